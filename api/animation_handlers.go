@@ -87,6 +87,15 @@ func (s *Server) handleStartAnimation(c *gin.Context) {
 		return
 	}
 
+	// 急停锁定期间不启动动画
+	if status, err := dev.GetStatus(); err == nil && status.EmergencyStop {
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 已急停，需要解除急停后才能启动动画", deviceId),
+		})
+		return
+	}
+
 	// 处理速度参数
 	speedMs := req.SpeedMs
 	if speedMs <= 0 {
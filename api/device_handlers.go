@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -47,6 +48,14 @@ func (s *Server) handleGetDevices(c *gin.Context) {
 	})
 }
 
+// registryErrorStatus 设备注册表写入失败时返回 500（修改已回滚），其它错误返回 status
+func registryErrorStatus(err error, status int) int {
+	if errors.Is(err, device.ErrRegistry) {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
 // handleCreateDevice 创建新设备
 func (s *Server) handleCreateDevice(c *gin.Context) {
 	var req DeviceCreateRequest
@@ -79,25 +88,16 @@ func (s *Server) handleCreateDevice(c *gin.Context) {
 		config["hand_type"] = req.HandType
 	}
 
-	// 创建设备实例
-	dev, err := device.CreateDevice(req.Model, config)
+	// 创建设备实例，注册到管理器并保存到设备注册表
+	dev, err := s.deviceManager.CreateDevice(req.Model, config)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ApiResponse{
+		c.JSON(registryErrorStatus(err, http.StatusBadRequest), ApiResponse{
 			Status: "error",
 			Error:  fmt.Sprintf("创建设备失败：%v", err),
 		})
 		return
 	}
 
-	// 注册设备到管理器
-	if err := s.deviceManager.RegisterDevice(dev); err != nil {
-		c.JSON(http.StatusInternalServerError, ApiResponse{
-			Status: "error",
-			Error:  fmt.Sprintf("注册设备失败：%v", err),
-		})
-		return
-	}
-
 	// 获取设备状态
 	status, err := dev.GetStatus()
 	if err != nil {
@@ -136,6 +136,11 @@ func (s *Server) handleGetDevice(c *gin.Context) {
 		return
 	}
 
+	table, unit, ok := s.bindPoseUnit(c, dev, c.Query("unit"))
+	if !ok {
+		return
+	}
+
 	status, err := dev.GetStatus()
 	if err != nil {
 		status = device.DeviceStatus{
@@ -145,6 +150,7 @@ func (s *Server) handleGetDevice(c *gin.Context) {
 			LastError:   err.Error(),
 		}
 	}
+	status.Pose = table.Convert(status.Pose, unit)
 
 	deviceInfo := DeviceInfo{
 		ID:       dev.GetID(),
@@ -210,9 +216,8 @@ func (s *Server) handleSetHandType(c *gin.Context) {
 		return
 	}
 
-	// 获取设备
-	dev, err := s.deviceManager.GetDevice(deviceId)
-	if err != nil {
+	// 检查设备是否存在
+	if _, err := s.deviceManager.GetDevice(deviceId); err != nil {
 		c.JSON(http.StatusNotFound, ApiResponse{
 			Status: "error",
 			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
@@ -231,8 +236,8 @@ func (s *Server) handleSetHandType(c *gin.Context) {
 		return
 	}
 
-	// 设置手型
-	if err := dev.SetHandType(handType); err != nil {
+	// 设置手型，并更新设备注册表
+	if err := s.deviceManager.SetHandType(deviceId, handType); err != nil {
 		c.JSON(http.StatusInternalServerError, ApiResponse{
 			Status: "error",
 			Error:  fmt.Sprintf("设置手型失败：%v", err),
@@ -249,3 +254,114 @@ func (s *Server) handleSetHandType(c *gin.Context) {
 		},
 	})
 }
+
+// handleConnectDevice 连接设备：检查接口是否可用并与设备握手，失败时设备进入自动重连
+func (s *Server) handleConnectDevice(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	connectErr := dev.Connect()
+	deviceInfo := newDeviceInfo(dev)
+
+	if connectErr != nil {
+		// 返回当前状态，调用方可以区分接口不可用 (faulted) 和设备未应答 (degraded)
+		c.JSON(http.StatusServiceUnavailable, ApiResponse{
+			Status: "error",
+			Error:  connectErr.Error(),
+			Data:   deviceInfo,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备 %s 已连接", deviceId),
+		Data:    deviceInfo,
+	})
+}
+
+// handleDisconnectDevice 断开设备
+func (s *Server) handleDisconnectDevice(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	// 停止正在运行的动画，断开后动画帧会发送失败
+	animEngine := dev.GetAnimationEngine()
+	if animEngine.IsRunning() {
+		if err := animEngine.Stop(); err != nil {
+			fmt.Printf("警告：停止设备 %s 动画时出错：%v\n", deviceId, err)
+		}
+	}
+
+	if err := dev.Disconnect(); err != nil {
+		c.JSON(http.StatusInternalServerError, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("断开设备失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备 %s 已断开", deviceId),
+		Data:    newDeviceInfo(dev),
+	})
+}
+
+// handleGetDeviceCapabilities 获取设备的能力描述，关节范围反映设备配置的关节限制
+func (s *Server) handleGetDeviceCapabilities(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: map[string]any{
+			"deviceId":     deviceId,
+			"capabilities": dev.GetCapabilities(),
+		},
+	})
+}
+
+// newDeviceInfo 构建设备信息，获取状态失败时使用默认状态
+func newDeviceInfo(dev device.Device) DeviceInfo {
+	status, err := dev.GetStatus()
+	if err != nil {
+		status = device.DeviceStatus{
+			IsConnected: false,
+			IsActive:    false,
+			ErrorCount:  1,
+			LastError:   err.Error(),
+		}
+	}
+
+	return DeviceInfo{
+		ID:       dev.GetID(),
+		Model:    dev.GetModel(),
+		HandType: dev.GetHandType().String(),
+		Status:   status,
+	}
+}
@@ -0,0 +1,377 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"hands/device"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleGetGroups 获取所有设备组
+func (s *Server) handleGetGroups(c *gin.Context) {
+	groups := s.deviceManager.GetAllGroups()
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: GroupListResponse{
+			Groups: groups,
+			Total:  len(groups),
+		},
+	})
+}
+
+// handleCreateGroup 创建设备组
+func (s *Server) handleCreateGroup(c *gin.Context) {
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的设备组数据：" + err.Error(),
+		})
+		return
+	}
+	if req.ID == "" {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "设备组 ID 不能为空",
+		})
+		return
+	}
+
+	// 检查设备组是否已存在
+	if _, err := s.deviceManager.GetGroup(req.ID); err == nil {
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 已存在", req.ID),
+		})
+		return
+	}
+
+	group := device.DeviceGroup{ID: req.ID, Name: req.Name, DeviceIDs: req.DeviceIDs}
+	if err := s.deviceManager.CreateGroup(group); err != nil {
+		c.JSON(registryErrorStatus(err, http.StatusBadRequest), ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("创建设备组失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备组 %s 创建成功", req.ID),
+		Data:    group,
+	})
+}
+
+// handleGetGroup 获取设备组详情
+func (s *Server) handleGetGroup(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	group, err := s.deviceManager.GetGroup(groupId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 不存在", groupId),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data:   group,
+	})
+}
+
+// handleUpdateGroup 更新设备组的名称和成员
+func (s *Server) handleUpdateGroup(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的设备组数据：" + err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.deviceManager.GetGroup(groupId); err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 不存在", groupId),
+		})
+		return
+	}
+
+	group := device.DeviceGroup{ID: groupId, Name: req.Name, DeviceIDs: req.DeviceIDs}
+	if err := s.deviceManager.UpdateGroup(group); err != nil {
+		c.JSON(registryErrorStatus(err, http.StatusBadRequest), ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("更新设备组失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备组 %s 已更新", groupId),
+		Data:    group,
+	})
+}
+
+// handleDeleteGroup 删除设备组，组内设备保持不变
+func (s *Server) handleDeleteGroup(c *gin.Context) {
+	groupId := c.Param("groupId")
+
+	if err := s.deviceManager.DeleteGroup(groupId); err != nil {
+		c.JSON(registryErrorStatus(err, http.StatusNotFound), ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("删除设备组失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备组 %s 已删除", groupId),
+	})
+}
+
+// handleGroupFingerPose 向设备组的所有成员发送手指姿态
+func (s *Server) handleGroupFingerPose(c *gin.Context) {
+	var req FingerPoseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的手指姿态数据：" + err.Error(),
+		})
+		return
+	}
+
+	check := func(dev device.Device) error {
+		if err := dev.GetCapabilities().ValidatePose(device.JointGroupFinger, req.Pose); err != nil {
+			return fmt.Errorf("设备 %s：%w", dev.GetID(), err)
+		}
+		if err := checkJointLimits(dev, device.JointGroupFinger, req.Pose); err != nil {
+			return err
+		}
+		return checkAcceptsCommands(dev)
+	}
+	s.executeGroupCommand(c, "fingers", check, func(dev device.Device) error {
+		if err := stopRunningAnimation(dev); err != nil {
+			return err
+		}
+		return commanderFor(c, dev, device.SourceGroup).SetFingerPose(req.Pose)
+	})
+}
+
+// handleGroupPalmPose 向设备组的所有成员发送掌部姿态
+func (s *Server) handleGroupPalmPose(c *gin.Context) {
+	var req PalmPoseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的掌部姿态数据：" + err.Error(),
+		})
+		return
+	}
+
+	check := func(dev device.Device) error {
+		if err := dev.GetCapabilities().ValidatePose(device.JointGroupPalm, req.Pose); err != nil {
+			return fmt.Errorf("设备 %s：%w", dev.GetID(), err)
+		}
+		if err := checkJointLimits(dev, device.JointGroupPalm, req.Pose); err != nil {
+			return err
+		}
+		return checkAcceptsCommands(dev)
+	}
+	s.executeGroupCommand(c, "palm", check, func(dev device.Device) error {
+		if err := stopRunningAnimation(dev); err != nil {
+			return err
+		}
+		return commanderFor(c, dev, device.SourceGroup).SetPalmPose(req.Pose)
+	})
+}
+
+// handleGroupPresetPose 在设备组的所有成员上执行预设姿势
+func (s *Server) handleGroupPresetPose(c *gin.Context) {
+	presetName := c.Param("presetName")
+
+	check := func(dev device.Device) error {
+		if !slices.Contains(dev.GetSupportedPresets(), presetName) {
+			return fmt.Errorf("设备 %s 不支持预设姿势 %s", dev.GetID(), presetName)
+		}
+		if preset, ok := dev.GetPresetDetails(presetName); ok {
+			if err := checkJointLimits(dev, device.JointGroupFinger, preset.FingerPose); err != nil {
+				return err
+			}
+			if len(preset.PalmPose) > 0 {
+				if err := checkJointLimits(dev, device.JointGroupPalm, preset.PalmPose); err != nil {
+					return err
+				}
+			}
+		}
+		return checkAcceptsCommands(dev)
+	}
+	s.executeGroupCommand(c, "preset:"+presetName, check, func(dev device.Device) error {
+		if err := stopRunningAnimation(dev); err != nil {
+			return err
+		}
+		return commanderFor(c, dev, device.SourceGroup).ExecutePreset(presetName)
+	})
+}
+
+// handleGroupResetPose 将设备组的所有成员重置到默认姿态
+func (s *Server) handleGroupResetPose(c *gin.Context) {
+	s.executeGroupCommand(c, "reset", checkAcceptsCommands, func(dev device.Device) error {
+		if err := stopRunningAnimation(dev); err != nil {
+			return err
+		}
+		return commanderFor(c, dev, device.SourceGroup).ResetPose()
+	})
+}
+
+// handleGroupStartAnimation 在设备组的所有成员上启动动画
+func (s *Server) handleGroupStartAnimation(c *gin.Context) {
+	var req AnimationStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的动画请求：" + err.Error(),
+		})
+		return
+	}
+
+	speedMs := req.SpeedMs
+	if speedMs <= 0 {
+		speedMs = 500 // 默认速度
+	}
+
+	check := func(dev device.Device) error {
+		if !slices.Contains(dev.GetAnimationEngine().GetRegisteredAnimations(), req.Name) {
+			return fmt.Errorf("设备 %s 不支持动画 %s", dev.GetID(), req.Name)
+		}
+		return checkAcceptsCommands(dev)
+	}
+	s.executeGroupCommand(c, "animation:"+req.Name, check, func(dev device.Device) error {
+		return dev.GetAnimationEngine().Start(req.Name, speedMs)
+	})
+}
+
+// handleGroupStopAnimation 停止设备组所有成员的动画
+func (s *Server) handleGroupStopAnimation(c *gin.Context) {
+	s.executeGroupCommand(c, "animation:stop", nil, stopRunningAnimation)
+}
+
+// executeGroupCommand 并发地在组成员上执行指令并返回每个成员的结果
+// 查询参数 allOrNothing=true 时先预检所有成员，任一成员无法执行则不向任何成员发送
+func (s *Server) executeGroupCommand(c *gin.Context, command string, check, op func(device.Device) error) {
+	groupId := c.Param("groupId")
+
+	allOrNothing := false
+	if v := c.Query("allOrNothing"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ApiResponse{
+				Status: "error",
+				Error:  fmt.Sprintf("无效的 allOrNothing 参数：%s", v),
+			})
+			return
+		}
+		allOrNothing = parsed
+	}
+
+	results, err := s.deviceManager.ExecuteGroup(groupId, allOrNothing, check, op)
+	if err != nil && !errors.Is(err, device.ErrGroupPrecheckFailed) {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 不存在", groupId),
+		})
+		return
+	}
+
+	response := GroupCommandResponse{
+		GroupID:      groupId,
+		Command:      command,
+		AllOrNothing: allOrNothing,
+		Results:      results,
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	switch {
+	case err != nil:
+		// 预检失败，没有向任何成员发送指令
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 有设备无法执行 %s，未向任何设备发送", groupId, command),
+			Data:   response,
+		})
+	case response.Failed == 0:
+		c.JSON(http.StatusOK, ApiResponse{
+			Status:  "success",
+			Message: fmt.Sprintf("设备组 %s 的 %d 个设备已执行 %s", groupId, response.Succeeded, command),
+			Data:    response,
+		})
+	case response.Succeeded > 0:
+		c.JSON(http.StatusMultiStatus, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 有 %d/%d 个设备执行 %s 失败", groupId, response.Failed, len(results), command),
+			Data:   response,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备组 %s 的所有设备执行 %s 失败", groupId, command),
+			Data:   response,
+		})
+	}
+}
+
+// checkAcceptsCommands 检查设备当前的连接状态和急停锁定是否允许发送指令
+func checkAcceptsCommands(dev device.Device) error {
+	status, err := dev.GetStatus()
+	if err != nil {
+		return fmt.Errorf("获取设备 %s 状态失败：%w", dev.GetID(), err)
+	}
+	if !status.State.AcceptsCommands() {
+		return fmt.Errorf("设备 %s 当前状态为 %s，无法执行指令", dev.GetID(), status.State)
+	}
+	if status.EmergencyStop {
+		return fmt.Errorf("%w：设备 %s 需要解除急停后才能执行指令", device.ErrEmergencyStop, dev.GetID())
+	}
+	return nil
+}
+
+// checkJointLimits 按设备的关节限制预检姿态，reject 模式下会被拒绝时返回错误；clamp 模式和没有关节限制的设备总是通过
+func checkJointLimits(dev device.Device, group device.JointGroup, pose []byte) error {
+	checker, ok := dev.(device.LimitCheckingDevice)
+	if !ok {
+		return nil
+	}
+	if err := checker.CheckJointLimits(group, pose); err != nil {
+		return fmt.Errorf("设备 %s：%w", dev.GetID(), err)
+	}
+	return nil
+}
+
+// stopRunningAnimation 停止设备正在运行的动画
+func stopRunningAnimation(dev device.Device) error {
+	animEngine := dev.GetAnimationEngine()
+	if !animEngine.IsRunning() {
+		return nil
+	}
+	if err := animEngine.Stop(); err != nil {
+		return fmt.Errorf("停止动画失败：%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"hands/device"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commanderFor 返回以请求路径和客户端地址为来源、通过 manual 通道执行指令的 Commander
+func commanderFor(c *gin.Context, dev device.Device, source string) device.Commander {
+	return device.CommanderFor(dev, device.CommandOrigin{
+		Source:   source,
+		Detail:   c.Request.Method + " " + c.Request.URL.Path,
+		Client:   c.ClientIP(),
+		Priority: device.PriorityManual,
+	})
+}
+
+// handleGetHistory 获取设备的指令历史，按从新到旧排列
+// 查询参数 source、action、result、since 和 limit 用于过滤，见 HistoryQuery
+func (s *Server) handleGetHistory(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	var query HistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的查询参数：" + err.Error(),
+		})
+		return
+	}
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	historyDevice, ok := dev.(device.HistoryDevice)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s (%s) 不记录指令历史", deviceId, dev.GetModel()),
+		})
+		return
+	}
+
+	entries := historyDevice.GetHistory(device.HistoryFilter{
+		Source: query.Source,
+		Action: query.Action,
+		Result: query.Result,
+		Since:  query.Since,
+		Limit:  query.Limit,
+	})
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: HistoryResponse{
+			DeviceID: deviceId,
+			Entries:  entries,
+			Total:    len(entries),
+		},
+	})
+}
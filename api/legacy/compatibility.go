@@ -3,6 +3,7 @@ package legacy
 import (
 	"fmt"
 	"log"
+	"maps"
 	"sync"
 
 	"hands/config"
@@ -51,23 +52,33 @@ func (m *InterfaceDeviceMapper) initializeDevices() error {
 	for _, ifName := range config.Config.AvailableInterfaces {
 		deviceId := ifName + "_default"
 
-		// 创建设备配置
-		deviceConfig := map[string]any{
-			"id":              deviceId,
+		// 传输方式总是以命令行参数为准
+		transportConfig := map[string]any{
+			"transport":       config.Config.Transport,
 			"can_service_url": config.Config.CanServiceURL,
-			"can_interface":   ifName,
-			"hand_type":       "right", // 默认右手
 		}
 
-		// 创建设备实例
-		dev, err := device.CreateDevice("L10", deviceConfig)
-		if err != nil {
-			return fmt.Errorf("创建接口 %s 的设备失败: %w", ifName, err)
-		}
-
-		// 注册设备到管理器
-		if err := m.deviceManager.RegisterDevice(dev); err != nil {
-			return fmt.Errorf("注册接口 %s 的设备失败: %w", ifName, err)
+		// 已从设备注册表恢复的设备保留其手型等配置，传输方式变化时按新的配置重新创建
+		dev, err := m.deviceManager.GetDevice(deviceId)
+		if err == nil {
+			dev, err = m.deviceManager.RefreshDeviceConfig(deviceId, transportConfig)
+			if err != nil {
+				return fmt.Errorf("更新接口 %s 的设备失败: %w", ifName, err)
+			}
+		} else {
+			// 创建设备配置
+			deviceConfig := map[string]any{
+				"id":            deviceId,
+				"can_interface": ifName,
+				"hand_type":     "right", // 默认右手
+			}
+			maps.Copy(deviceConfig, transportConfig)
+
+			// 创建设备实例，注册到管理器并保存到设备注册表
+			dev, err = m.deviceManager.CreateDevice("L10", deviceConfig)
+			if err != nil {
+				return fmt.Errorf("创建接口 %s 的设备失败: %w", ifName, err)
+			}
 		}
 
 		// 建立映射关系
@@ -75,9 +86,10 @@ func (m *InterfaceDeviceMapper) initializeDevices() error {
 		m.deviceToInterface[deviceId] = ifName
 
 		// 初始化手型配置
+		handType := dev.GetHandType()
 		m.handConfigs[ifName] = HandConfig{
-			HandType: "right",
-			HandId:   uint32(define.HAND_TYPE_RIGHT),
+			HandType: handType.Key(),
+			HandId:   uint32(handType),
 		}
 
 		log.Printf("✅ 接口 %s -> 设备 %s 映射创建成功", ifName, deviceId)
@@ -125,11 +137,6 @@ func (m *InterfaceDeviceMapper) SetHandConfig(ifName string, handType string, ha
 		return fmt.Errorf("接口 %s 没有对应的设备", ifName)
 	}
 
-	dev, err := m.deviceManager.GetDevice(deviceId)
-	if err != nil {
-		return fmt.Errorf("获取设备失败：%w", err)
-	}
-
 	// 转换手型
 	var deviceHandType define.HandType
 	switch handType {
@@ -141,8 +148,8 @@ func (m *InterfaceDeviceMapper) SetHandConfig(ifName string, handType string, ha
 		return fmt.Errorf("无效的手型: %s", handType)
 	}
 
-	// 设置设备手型
-	if err := dev.SetHandType(deviceHandType); err != nil {
+	// 设置设备手型，并更新设备注册表
+	if err := m.deviceManager.SetHandType(deviceId, deviceHandType); err != nil {
 		return fmt.Errorf("设置设备手型失败：%w", err)
 	}
 
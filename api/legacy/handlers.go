@@ -7,10 +7,21 @@ import (
 
 	"hands/config"
 	"hands/define"
+	"hands/device"
 
 	"github.com/gin-gonic/gin"
 )
 
+// legacyCommander 返回以旧版接口路径和客户端地址为来源执行指令的 Commander，记录在设备的指令历史中
+func legacyCommander(c *gin.Context, dev device.Device) device.Commander {
+	return device.CommanderFor(dev, device.CommandOrigin{
+		Source:   device.SourceLegacy,
+		Detail:   c.Request.Method + " " + c.Request.URL.Path,
+		Client:   c.ClientIP(),
+		Priority: device.PriorityManual,
+	})
+}
+
 // handleHealth 健康检查处理函数
 func (s *LegacyServer) handleHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, define.ApiResponse{
@@ -162,6 +173,15 @@ func (s *LegacyServer) handleFingers(c *gin.Context) {
 		return
 	}
 
+	// 按设备的能力描述校验姿态长度
+	if err := dev.GetCapabilities().ValidatePose(device.JointGroupFinger, req.Pose); err != nil {
+		c.JSON(http.StatusBadRequest, define.ApiResponse{
+			Status: "error",
+			Error:  "无效的手指姿态数据：" + err.Error(),
+		})
+		return
+	}
+
 	// 停止当前动画
 	if err := s.mapper.StopAllAnimations(req.Interface); err != nil {
 		c.JSON(http.StatusInternalServerError, define.ApiResponse{
@@ -172,7 +192,7 @@ func (s *LegacyServer) handleFingers(c *gin.Context) {
 	}
 
 	// 设置手指姿态
-	if err := dev.SetFingerPose(req.Pose); err != nil {
+	if err := legacyCommander(c, dev).SetFingerPose(req.Pose); err != nil {
 		c.JSON(http.StatusInternalServerError, define.ApiResponse{
 			Status: "error",
 			Error:  "发送手指姿态失败：" + err.Error(),
@@ -233,6 +253,15 @@ func (s *LegacyServer) handlePalm(c *gin.Context) {
 		return
 	}
 
+	// 按设备的能力描述校验姿态长度
+	if err := dev.GetCapabilities().ValidatePose(device.JointGroupPalm, req.Pose); err != nil {
+		c.JSON(http.StatusBadRequest, define.ApiResponse{
+			Status: "error",
+			Error:  "无效的掌部姿态数据：" + err.Error(),
+		})
+		return
+	}
+
 	// 停止当前动画
 	if err := s.mapper.StopAllAnimations(req.Interface); err != nil {
 		c.JSON(http.StatusInternalServerError, define.ApiResponse{
@@ -243,7 +272,7 @@ func (s *LegacyServer) handlePalm(c *gin.Context) {
 	}
 
 	// 设置掌部姿态
-	if err := dev.SetPalmPose(req.Pose); err != nil {
+	if err := legacyCommander(c, dev).SetPalmPose(req.Pose); err != nil {
 		c.JSON(http.StatusInternalServerError, define.ApiResponse{
 			Status: "error",
 			Error:  "发送掌部姿态失败：" + err.Error(),
@@ -309,7 +338,7 @@ func (s *LegacyServer) handlePreset(c *gin.Context) {
 	}
 
 	// 使用设备的预设姿势方法
-	if err := dev.ExecutePreset(pose); err != nil {
+	if err := legacyCommander(c, dev).ExecutePreset(pose); err != nil {
 		c.JSON(http.StatusBadRequest, define.ApiResponse{
 			Status: "error",
 			Error:  "无效的预设姿势",
@@ -387,6 +416,15 @@ func (s *LegacyServer) handleAnimation(c *gin.Context) {
 		return
 	}
 
+	// 急停锁定期间不启动动画
+	if status, err := dev.GetStatus(); err == nil && status.EmergencyStop {
+		c.JSON(http.StatusConflict, define.ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("%s 已急停，需要解除急停后才能启动动画", req.Interface),
+		})
+		return
+	}
+
 	// 处理速度参数
 	if req.Speed <= 0 {
 		req.Speed = 500 // 默认速度
@@ -566,6 +604,7 @@ func (s *LegacyServer) handleStatus(c *gin.Context) {
 			"availableInterfaces": config.Config.AvailableInterfaces,
 			"activeInterfaces":    activeInterfacesCount,
 			"handConfigs":         handConfigsData,
+			"emergencyStop":       s.mapper.deviceManager.EmergencyStopState().Latched,
 		},
 	})
 }
@@ -3,17 +3,17 @@ package legacy
 // FingerPoseRequest 手指姿态设置请求
 type FingerPoseRequest struct {
 	Interface string `json:"interface,omitempty"`
-	Pose      []byte `json:"pose" binding:"required,len=6"`
-	HandType  string `json:"handType,omitempty"` // 新增：手型类型
-	HandId    uint32 `json:"handId,omitempty"`   // 新增：CAN ID
+	Pose      []byte `json:"pose" binding:"required,min=1"` // 长度按设备的能力描述校验
+	HandType  string `json:"handType,omitempty"`            // 新增：手型类型
+	HandId    uint32 `json:"handId,omitempty"`              // 新增：CAN ID
 }
 
 // PalmPoseRequest 掌部姿态设置请求
 type PalmPoseRequest struct {
 	Interface string `json:"interface,omitempty"`
-	Pose      []byte `json:"pose" binding:"required,len=4"`
-	HandType  string `json:"handType,omitempty"` // 新增：手型类型
-	HandId    uint32 `json:"handId,omitempty"`   // 新增：CAN ID
+	Pose      []byte `json:"pose" binding:"required,min=1"` // 长度按设备的能力描述校验
+	HandType  string `json:"handType,omitempty"`            // 新增：手型类型
+	HandId    uint32 `json:"handId,omitempty"`              // 新增：CAN ID
 }
 
 // AnimationRequest 动画控制请求
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"hands/communication"
 	"hands/device"
 	"time"
 )
@@ -44,16 +45,75 @@ type HandTypeRequest struct {
 	HandType string `json:"handType" binding:"required,oneof=left right"`
 }
 
+// ===== 设备组相关模型 =====
+
+// GroupRequest 创建或更新设备组请求，更新时忽略 ID
+type GroupRequest struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	DeviceIDs []string `json:"deviceIds" binding:"required,min=1"`
+}
+
+// GroupListResponse 设备组列表响应
+type GroupListResponse struct {
+	Groups []device.DeviceGroup `json:"groups"`
+	Total  int                  `json:"total"`
+}
+
+// GroupCommandResponse 组指令响应，Results 与组成员顺序一致
+type GroupCommandResponse struct {
+	GroupID      string                     `json:"groupId"`
+	Command      string                     `json:"command"`
+	AllOrNothing bool                       `json:"allOrNothing"`
+	Succeeded    int                        `json:"succeeded"`
+	Failed       int                        `json:"failed"`
+	Results      []device.GroupMemberResult `json:"results"`
+}
+
 // ===== 姿态控制相关模型 =====
 
-// FingerPoseRequest 手指姿态设置请求
+// FingerPoseRequest 手指姿态设置请求，长度按设备的能力描述校验 (L10 为 6，L20 为 10)
 type FingerPoseRequest struct {
-	Pose []byte `json:"pose" binding:"required,len=6"`
+	Pose []byte `json:"pose" binding:"required,min=1"`
 }
 
-// PalmPoseRequest 手掌姿态设置请求
+// PalmPoseRequest 手掌姿态设置请求，长度按设备的能力描述校验 (L10 为 4，L20 为 6)
 type PalmPoseRequest struct {
-	Pose []byte `json:"pose" binding:"required,len=4"`
+	Pose []byte `json:"pose" binding:"required,min=1"`
+}
+
+// PoseValuesRequest 以指定单位设置姿态的请求，单位由路径参数 :unit 指定
+type PoseValuesRequest struct {
+	Values []float64 `json:"values" binding:"required"`
+}
+
+// PoseFiltersRequest 替换姿态滤波器请求，空数组表示不做任何变换
+type PoseFiltersRequest struct {
+	Filters []device.FilterConfig `json:"filters" binding:"required"`
+}
+
+// ===== 指令历史相关模型 =====
+
+// HistoryQuery 指令历史的查询参数，均可省略
+type HistoryQuery struct {
+	Source string    `form:"source"`                                        // 来源：api、group、legacy、animation 或 device
+	Action string    `form:"action"`                                        // 动作前缀，如 "preset" 或 "preset:fist"
+	Result string    `form:"result"`                                        // 结果：ok、failed、coalesced、preempted、rejected 或 closed
+	Since  time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"` // 只返回此时间之后的记录，RFC 3339 格式
+	Limit  int       `form:"limit" binding:"omitempty,min=1"`               // 最多返回的条数
+}
+
+// EventStreamQuery 事件流的查询参数，只过滤设备事件，健康事件总是推送
+type EventStreamQuery struct {
+	DeviceID string   `form:"deviceId"` // 只推送该设备的事件
+	Types    []string `form:"type"`     // 只推送这些类型的设备事件，可重复，如 type=state_changed&type=error
+}
+
+// HistoryResponse 指令历史响应，记录按从新到旧排列
+type HistoryResponse struct {
+	DeviceID string                `json:"deviceId"`
+	Entries  []device.HistoryEntry `json:"entries"`
+	Total    int                   `json:"total"`
 }
 
 // ===== 动画控制相关模型 =====
@@ -95,6 +155,24 @@ type SystemStatusResponse struct {
 	SupportedModels []string              `json:"supportedModels"`
 	Devices         map[string]DeviceInfo `json:"devices"`
 	Uptime          time.Duration         `json:"uptime"`
+
+	// Breakers 各通信客户端的熔断器状态，key 为通信客户端标识（如 "can-bridge:http://127.0.0.1:5260"）
+	Breakers map[string]communication.BreakerStatus `json:"breakers"`
+
+	// Health 各通信客户端最近一次健康检查的结果，key 与 Breakers 相同
+	Health map[string]communication.HealthSnapshot `json:"health"`
+
+	// Failovers 配置了多个 can-bridge 的通信客户端的故障转移状态，key 与 Breakers 相同
+	Failovers map[string]communication.FailoverStatus `json:"failovers"`
+
+	// EmergencyStop 急停状态，锁定期间所有设备拒绝姿态指令
+	EmergencyStop device.EmergencyStopStatus `json:"emergencyStop"`
+}
+
+// InterfaceStatsResponse 各 CAN 接口的发送统计响应
+type InterfaceStatsResponse struct {
+	// Communicators key 为通信客户端标识（与 SystemStatusResponse.Breakers 相同），value 为该客户端下按接口名索引的统计
+	Communicators map[string]map[string]communication.InterfaceStats `json:"communicators"`
 }
 
 // SupportedModelsResponse 支持的设备型号响应
@@ -103,6 +181,24 @@ type SupportedModelsResponse struct {
 	Total  int      `json:"total"`
 }
 
+// ===== 流量录制相关模型 =====
+
+// RecordingListResponse 录制列表响应
+type RecordingListResponse struct {
+	Dir        string                        `json:"dir"`
+	Recordings []communication.RecordingInfo `json:"recordings"`
+	Replay     communication.ReplayStatus    `json:"replay"`
+}
+
+// ReplayRequest 回放请求
+type ReplayRequest struct {
+	File         string            `json:"file" binding:"required"`
+	Speed        *float64          `json:"speed,omitempty"`        // 时间缩放系数，默认 1；<= 0 表示尽快发送
+	InterfaceMap map[string]string `json:"interfaceMap,omitempty"` // 接口重映射，原接口 -> 目标接口
+	CANIDMap     map[string]string `json:"canIdMap,omitempty"`     // CAN ID 重映射，原 ID -> 目标 ID，十进制或 0x 开头的十六进制
+	Communicator string            `json:"communicator,omitempty"` // 通信客户端标识，默认使用全局传输配置
+}
+
 // HealthResponse 健康检查响应
 type HealthResponse struct {
 	Status    string    `json:"status"`
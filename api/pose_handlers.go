@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"hands/device"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,17 +22,6 @@ func (s *Server) handleSetFingerPose(c *gin.Context) {
 		return
 	}
 
-	// 验证每个值是否在范围内
-	for _, v := range req.Pose {
-		if v > 255 {
-			c.JSON(http.StatusBadRequest, ApiResponse{
-				Status: "error",
-				Error:  "手指姿态值必须在 0-255 范围内",
-			})
-			return
-		}
-	}
-
 	// 获取设备
 	dev, err := s.deviceManager.GetDevice(deviceId)
 	if err != nil {
@@ -41,6 +32,15 @@ func (s *Server) handleSetFingerPose(c *gin.Context) {
 		return
 	}
 
+	// 按设备的能力描述校验姿态长度
+	if err := dev.GetCapabilities().ValidatePose(device.JointGroupFinger, req.Pose); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的手指姿态数据：" + err.Error(),
+		})
+		return
+	}
+
 	// 停止当前动画（如果正在运行）
 	animEngine := dev.GetAnimationEngine()
 	if animEngine.IsRunning() {
@@ -54,8 +54,8 @@ func (s *Server) handleSetFingerPose(c *gin.Context) {
 	}
 
 	// 设置手指姿态
-	if err := dev.SetFingerPose(req.Pose); err != nil {
-		c.JSON(http.StatusInternalServerError, ApiResponse{
+	if err := commanderFor(c, dev, device.SourceAPI).SetFingerPose(req.Pose); err != nil {
+		c.JSON(poseErrorStatus(err), ApiResponse{
 			Status: "error",
 			Error:  "发送手指姿态失败：" + err.Error(),
 		})
@@ -85,17 +85,6 @@ func (s *Server) handleSetPalmPose(c *gin.Context) {
 		return
 	}
 
-	// 验证每个值是否在范围内
-	for _, v := range req.Pose {
-		if v > 255 {
-			c.JSON(http.StatusBadRequest, ApiResponse{
-				Status: "error",
-				Error:  "掌部姿态值必须在 0-255 范围内",
-			})
-			return
-		}
-	}
-
 	// 获取设备
 	dev, err := s.deviceManager.GetDevice(deviceId)
 	if err != nil {
@@ -106,6 +95,15 @@ func (s *Server) handleSetPalmPose(c *gin.Context) {
 		return
 	}
 
+	// 按设备的能力描述校验姿态长度
+	if err := dev.GetCapabilities().ValidatePose(device.JointGroupPalm, req.Pose); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的掌部姿态数据：" + err.Error(),
+		})
+		return
+	}
+
 	// 停止当前动画（如果正在运行）
 	animEngine := dev.GetAnimationEngine()
 	if animEngine.IsRunning() {
@@ -119,8 +117,8 @@ func (s *Server) handleSetPalmPose(c *gin.Context) {
 	}
 
 	// 设置手掌姿态
-	if err := dev.SetPalmPose(req.Pose); err != nil {
-		c.JSON(http.StatusInternalServerError, ApiResponse{
+	if err := commanderFor(c, dev, device.SourceAPI).SetPalmPose(req.Pose); err != nil {
+		c.JSON(poseErrorStatus(err), ApiResponse{
 			Status: "error",
 			Error:  "发送掌部姿态失败：" + err.Error(),
 		})
@@ -140,7 +138,7 @@ func (s *Server) handleSetPalmPose(c *gin.Context) {
 // handleSetPresetPose 设置预设姿势
 func (s *Server) handleSetPresetPose(c *gin.Context) {
 	deviceId := c.Param("deviceId")
-	pose := c.Param("pose")
+	pose := c.Param("presetName")
 
 	// 获取设备
 	dev, err := s.deviceManager.GetDevice(deviceId)
@@ -165,7 +163,7 @@ func (s *Server) handleSetPresetPose(c *gin.Context) {
 	}
 
 	// 使用设备的预设姿势方法
-	if err := dev.ExecutePreset(pose); err != nil {
+	if err := commanderFor(c, dev, device.SourceAPI).ExecutePreset(pose); err != nil {
 		c.JSON(http.StatusBadRequest, ApiResponse{
 			Status: "error",
 			Error:  fmt.Sprintf("执行预设姿势失败: %v", err),
@@ -218,7 +216,7 @@ func (s *Server) handleResetPose(c *gin.Context) {
 	}
 
 	// 重置姿态
-	if err := dev.ResetPose(); err != nil {
+	if err := commanderFor(c, dev, device.SourceAPI).ResetPose(); err != nil {
 		c.JSON(http.StatusInternalServerError, ApiResponse{
 			Status: "error",
 			Error:  "重置姿态失败：" + err.Error(),
@@ -239,7 +237,7 @@ func (s *Server) handleResetPose(c *gin.Context) {
 func (s *Server) handleGetPresetPose(c *gin.Context) {
 	deviceID := c.Param("deviceId")
 
-	device, err := s.deviceManager.GetDevice(deviceID)
+	dev, err := s.deviceManager.GetDevice(deviceID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, ApiResponse{
 			Status: "error",
@@ -248,17 +246,25 @@ func (s *Server) handleGetPresetPose(c *gin.Context) {
 		return
 	}
 
+	table, unit, ok := s.bindPoseUnit(c, dev, c.Query("unit"))
+	if !ok {
+		return
+	}
+
 	// 使用设备的预设姿势方法
-	presets := device.GetSupportedPresets()
+	presets := dev.GetSupportedPresets()
 
-	// 构建详细的预设信息
-	presetDetails := make([]map[string]string, 0, len(presets))
+	// 构建详细的预设信息，姿态按请求的单位表示
+	presetDetails := make([]map[string]any, 0, len(presets))
 	for _, presetName := range presets {
-		description := device.GetPresetDescription(presetName)
-		presetDetails = append(presetDetails, map[string]string{
+		detail := map[string]any{
 			"name":        presetName,
-			"description": description,
-		})
+			"description": dev.GetPresetDescription(presetName),
+		}
+		if preset, ok := dev.GetPresetDetails(presetName); ok {
+			detail["pose"] = table.Pose(unit, preset.FingerPose, preset.PalmPose)
+		}
+		presetDetails = append(presetDetails, detail)
 	}
 
 	c.JSON(http.StatusOK, ApiResponse{
@@ -271,3 +277,258 @@ func (s *Server) handleGetPresetPose(c *gin.Context) {
 		},
 	})
 }
+
+// poseErrorStatus 姿态长度与型号不符时返回 400，被关节限制拒绝时返回 422，急停锁定时返回 409，其它发送错误返回 500
+func poseErrorStatus(err error) int {
+	if errors.Is(err, device.ErrInvalidPose) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, device.ErrJointLimit) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, device.ErrEmergencyStop) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// handleGetPoseFilters 获取设备当前的姿态滤波器
+func (s *Server) handleGetPoseFilters(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	filterable, ok := dev.(device.FilterableDevice)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s (%s) 不支持姿态滤波器", deviceId, dev.GetModel()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: map[string]any{
+			"deviceId": deviceId,
+			"filters":  filterable.GetPoseFilters(),
+		},
+	})
+}
+
+// handleSetPoseFilters 替换设备的姿态滤波器，立即对之后的姿态指令生效
+func (s *Server) handleSetPoseFilters(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	var req PoseFiltersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的姿态滤波器配置：" + err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.deviceManager.GetDevice(deviceId); err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	// 替换滤波器，并更新设备注册表
+	if err := s.deviceManager.SetPoseFilters(deviceId, req.Filters); err != nil {
+		c.JSON(registryErrorStatus(err, http.StatusBadRequest), ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设置姿态滤波器失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("设备 %s 姿态滤波器已更新", deviceId),
+		Data: map[string]any{
+			"deviceId": deviceId,
+			"filters":  req.Filters,
+		},
+	})
+}
+
+// bindPoseUnit 解析单位并获取设备型号的关节标定表，失败时写入错误响应并返回 false
+// raw 单位不需要标定表
+func (s *Server) bindPoseUnit(c *gin.Context, dev device.Device, unitParam string) (device.JointCalibrationTable, device.PoseUnit, bool) {
+	unit, err := device.ParsePoseUnit(unitParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return device.JointCalibrationTable{}, "", false
+	}
+
+	table, ok := device.GetJointCalibration(dev.GetModel())
+	if !ok && unit != device.UnitRaw {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备型号 %s 没有关节标定表，只支持 raw 单位", dev.GetModel()),
+		})
+		return device.JointCalibrationTable{}, "", false
+	}
+	return table, unit, true
+}
+
+// handleGetPose 获取设备最近一次发送的姿态
+func (s *Server) handleGetPose(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	table, unit, ok := s.bindPoseUnit(c, dev, c.Query("unit"))
+	if !ok {
+		return
+	}
+
+	status, err := dev.GetStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("获取设备状态失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: map[string]any{
+			"deviceId": deviceId,
+			"pose":     table.Convert(status.Pose, unit),
+		},
+	})
+}
+
+// handleGetJointCalibration 获取设备型号的关节标定表
+func (s *Server) handleGetJointCalibration(c *gin.Context) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	table, ok := device.GetJointCalibration(dev.GetModel())
+	if !ok {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备型号 %s 没有关节标定表", dev.GetModel()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data: map[string]any{
+			"deviceId":    deviceId,
+			"model":       dev.GetModel(),
+			"calibration": table,
+		},
+	})
+}
+
+// handleSetFingerPoseUnit 以指定单位设置手指姿态
+func (s *Server) handleSetFingerPoseUnit(c *gin.Context) {
+	s.setPoseInUnit(c, device.JointGroupFinger)
+}
+
+// handleSetPalmPoseUnit 以指定单位设置手掌姿态
+func (s *Server) handleSetPalmPoseUnit(c *gin.Context) {
+	s.setPoseInUnit(c, device.JointGroupPalm)
+}
+
+// setPoseInUnit 将指定单位的姿态换算为原始值后发送，响应中同时返回两种表示
+func (s *Server) setPoseInUnit(c *gin.Context, group device.JointGroup) {
+	deviceId := c.Param("deviceId")
+
+	var req PoseValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的姿态数据：" + err.Error(),
+		})
+		return
+	}
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return
+	}
+
+	table, unit, ok := s.bindPoseUnit(c, dev, c.Param("unit"))
+	if !ok {
+		return
+	}
+	raw, err := table.ToRaw(group, unit, req.Values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的姿态数据：" + err.Error(),
+		})
+		return
+	}
+
+	// 停止当前动画（如果正在运行）
+	if err := stopRunningAnimation(dev); err != nil {
+		c.JSON(http.StatusInternalServerError, ApiResponse{
+			Status: "error",
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	if group == device.JointGroupFinger {
+		err = commanderFor(c, dev, device.SourceAPI).SetFingerPose(raw)
+	} else {
+		err = commanderFor(c, dev, device.SourceAPI).SetPalmPose(raw)
+	}
+	if err != nil {
+		c.JSON(poseErrorStatus(err), ApiResponse{
+			Status: "error",
+			Error:  "发送姿态失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("%s 姿态指令发送成功", group),
+		Data: map[string]any{
+			"deviceId": deviceId,
+			"unit":     unit,
+			"values":   req.Values,
+			"raw":      table.FromRaw(group, device.UnitRaw, raw),
+		},
+	})
+}
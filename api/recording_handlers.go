@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"hands/communication"
+	"hands/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleGetRecordings 获取录制列表和回放状态
+func (s *Server) handleGetRecordings(c *gin.Context) {
+	recorder := communication.DefaultRecorder()
+
+	response := RecordingListResponse{
+		Dir:        recorder.Dir(),
+		Recordings: recorder.Recordings(),
+		Replay:     communication.DefaultReplayer().Status(),
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// handleStartRecording 开始录制指定接口上发送的帧
+func (s *Server) handleStartRecording(c *gin.Context) {
+	ifName := c.Param("interface")
+
+	if !config.IsValidInterface(ifName) {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("无效的接口 %s，可用接口: %v", ifName, config.Config.AvailableInterfaces),
+		})
+		return
+	}
+
+	info, err := communication.DefaultRecorder().Start(ifName)
+	if err != nil {
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("开始录制失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("接口 %s 开始录制", ifName),
+		Data:    info,
+	})
+}
+
+// handleStopRecording 停止录制指定接口
+func (s *Server) handleStopRecording(c *gin.Context) {
+	ifName := c.Param("interface")
+
+	info, err := communication.DefaultRecorder().Stop(ifName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("停止录制失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("接口 %s 录制已停止，共 %d 帧", ifName, info.Frames),
+		Data:    info,
+	})
+}
+
+// handleStartReplay 回放一个录制文件
+func (s *Server) handleStartReplay(c *gin.Context) {
+	var req ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的回放请求：" + err.Error(),
+		})
+		return
+	}
+
+	idMap, err := communication.ParseCANIDMap(req.CANIDMap)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的 CAN ID 映射：" + err.Error(),
+		})
+		return
+	}
+
+	// 回放的帧不经过设备的指令队列，急停锁定期间不开始回放
+	if s.deviceManager.EmergencyStopState().Latched {
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  "系统已急停，需要解除急停后才能开始回放",
+		})
+		return
+	}
+
+	// 选择回放使用的通信客户端
+	var comm communication.Communicator
+	if req.Communicator != "" {
+		var ok bool
+		comm, ok = communication.GetCommunicators()[req.Communicator]
+		if !ok {
+			c.JSON(http.StatusBadRequest, ApiResponse{
+				Status: "error",
+				Error:  fmt.Sprintf("通信客户端 %s 不存在", req.Communicator),
+			})
+			return
+		}
+	} else {
+		comm, err = communication.NewCommunicatorFromConfig(map[string]any{
+			"transport":       config.Config.Transport,
+			"can_service_url": config.Config.CanServiceURL,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ApiResponse{
+				Status: "error",
+				Error:  fmt.Sprintf("创建通信客户端失败：%v", err),
+			})
+			return
+		}
+	}
+
+	speed := 1.0
+	if req.Speed != nil {
+		speed = *req.Speed
+	}
+
+	status, err := communication.DefaultReplayer().Start(comm, req.File, communication.ReplayOptions{
+		Speed:        speed,
+		InterfaceMap: req.InterfaceMap,
+		IDMap:        idMap,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("开始回放失败：%v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("开始回放 %s", req.File),
+		Data:    status,
+	})
+}
+
+// handleStopReplay 停止当前回放
+func (s *Server) handleStopReplay(c *gin.Context) {
+	status := communication.DefaultReplayer().Stop()
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: "回放已停止",
+		Data:    status,
+	})
+}
@@ -50,9 +50,18 @@ func (s *Server) SetupRoutes(r *gin.Engine) {
 					poses.POST("/palm", s.handleSetPalmPose)      // 设置手掌姿态
 					poses.POST("/reset", s.handleResetPose)       // 重置姿态
 
+					// 以物理单位 (raw、percent、degrees) 表示的姿态
+					poses.GET("", s.handleGetPose)                          // 获取当前姿态，?unit= 指定单位
+					poses.GET("/calibration", s.handleGetJointCalibration)  // 获取型号的关节标定表
+					poses.POST("/fingers/:unit", s.handleSetFingerPoseUnit) // 以指定单位设置手指姿态
+					poses.POST("/palm/:unit", s.handleSetPalmPoseUnit)      // 以指定单位设置手掌姿态
+
 					// 新的预设姿势 API
 					poses.GET("/presets", s.handleGetPresetPose)              // 获取支持的预设姿势列表
 					poses.POST("/presets/:presetName", s.handleSetPresetPose) // 执行预设姿势
+
+					poses.GET("/filters", s.handleGetPoseFilters) // 获取姿态滤波器
+					poses.PUT("/filters", s.handleSetPoseFilters) // 替换姿态滤波器
 				}
 
 				// 动画控制路由
@@ -71,16 +80,59 @@ func (s *Server) SetupRoutes(r *gin.Engine) {
 				}
 
 				// 设备状态路由
-				deviceRoutes.GET("/status", s.handleGetDeviceStatus) // 获取设备状态
+				deviceRoutes.GET("/status", s.handleGetDeviceStatus)             // 获取设备状态
+				deviceRoutes.GET("/capabilities", s.handleGetDeviceCapabilities) // 获取设备的能力描述
+				deviceRoutes.GET("/history", s.handleGetHistory)                 // 获取指令历史，支持 source、action、result、since、limit 过滤
+
+				// 控制会话路由，设备配置了 watchdog 时心跳超时会驱动到安全姿态
+				deviceRoutes.POST("/session", s.handleOpenSession)                           // 开始控制会话
+				deviceRoutes.POST("/session/:sessionId/heartbeat", s.handleSessionHeartbeat) // 控制会话心跳
+				deviceRoutes.DELETE("/session/:sessionId", s.handleCloseSession)             // 结束控制会话
+
+				// 连接管理路由
+				deviceRoutes.POST("/connect", s.handleConnectDevice)       // 检查接口并与设备握手
+				deviceRoutes.POST("/disconnect", s.handleDisconnectDevice) // 断开设备，停止自动重连
+			}
+		}
+
+		// 设备组路由，组指令并发发送到所有成员，?allOrNothing=true 时任一成员无法执行则都不执行
+		groups := v2.Group("/groups")
+		{
+			groups.GET("", s.handleGetGroups)               // 获取所有设备组
+			groups.POST("", s.handleCreateGroup)            // 创建设备组
+			groups.GET("/:groupId", s.handleGetGroup)       // 获取设备组详情
+			groups.PUT("/:groupId", s.handleUpdateGroup)    // 更新设备组名称和成员
+			groups.DELETE("/:groupId", s.handleDeleteGroup) // 删除设备组
+
+			groupRoutes := groups.Group("/:groupId")
+			{
+				groupRoutes.POST("/poses/fingers", s.handleGroupFingerPose)             // 设置所有成员的手指姿态
+				groupRoutes.POST("/poses/palm", s.handleGroupPalmPose)                  // 设置所有成员的手掌姿态
+				groupRoutes.POST("/poses/reset", s.handleGroupResetPose)                // 重置所有成员的姿态
+				groupRoutes.POST("/poses/presets/:presetName", s.handleGroupPresetPose) // 所有成员执行预设姿势
+				groupRoutes.POST("/animations/start", s.handleGroupStartAnimation)      // 所有成员启动动画
+				groupRoutes.POST("/animations/stop", s.handleGroupStopAnimation)        // 停止所有成员的动画
 			}
 		}
 
 		// 系统管理路由
 		system := v2.Group("/system")
 		{
-			system.GET("/models", s.handleGetSupportedModels) // 获取支持的设备型号
-			system.GET("/status", s.handleGetSystemStatus)    // 获取系统状态
-			system.GET("/health", s.handleHealthCheck)        // 健康检查
+			system.GET("/models", s.handleGetSupportedModels)           // 获取支持的设备型号
+			system.GET("/models/:model", s.handleGetModelCapabilities)  // 获取型号的能力描述
+			system.GET("/status", s.handleGetSystemStatus)              // 获取系统状态
+			system.GET("/health", s.handleHealthCheck)                  // 健康检查
+			system.GET("/events", s.handleStreamEvents)                 // 健康状态变化和设备事件流 (SSE)
+			system.GET("/interfaces", s.handleGetInterfaceStats)        // 各 CAN 接口的发送速率和总线负载
+			system.POST("/estop", s.handleEmergencyStop)                // 急停所有设备并锁定
+			system.POST("/estop/release", s.handleReleaseEmergencyStop) // 解除急停锁定
+
+			// CAN 流量录制与回放路由
+			system.GET("/recordings", s.handleGetRecordings)                    // 获取录制列表和回放状态
+			system.POST("/recordings/:interface/start", s.handleStartRecording) // 开始录制接口
+			system.POST("/recordings/:interface/stop", s.handleStopRecording)   // 停止录制接口
+			system.POST("/replay", s.handleStartReplay)                         // 回放录制文件
+			system.POST("/replay/stop", s.handleStopReplay)                     // 停止回放
 		}
 	}
 }
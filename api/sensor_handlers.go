@@ -51,6 +51,11 @@ func (s *Server) handleGetDeviceStatus(c *gin.Context) {
 		return
 	}
 
+	table, unit, ok := s.bindPoseUnit(c, dev, c.Query("unit"))
+	if !ok {
+		return
+	}
+
 	// 获取设备状态
 	status, err := dev.GetStatus()
 	if err != nil {
@@ -60,6 +65,7 @@ func (s *Server) handleGetDeviceStatus(c *gin.Context) {
 		})
 		return
 	}
+	status.Pose = table.Convert(status.Pose, unit)
 
 	// 获取动画引擎状态
 	animEngine := dev.GetAnimationEngine()
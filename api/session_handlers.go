@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"hands/device"
+
+	"github.com/gin-gonic/gin"
+)
+
+// watchdogDevice 获取支持控制会话的设备，失败时已写入响应
+func (s *Server) watchdogDevice(c *gin.Context) (device.WatchdogDevice, bool) {
+	deviceId := c.Param("deviceId")
+
+	dev, err := s.deviceManager.GetDevice(deviceId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s 不存在", deviceId),
+		})
+		return nil, false
+	}
+
+	watchdogDevice, ok := dev.(device.WatchdogDevice)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("设备 %s (%s) 不支持控制会话", deviceId, dev.GetModel()),
+		})
+		return nil, false
+	}
+	return watchdogDevice, true
+}
+
+// sessionErrorStatus 没有配置 watchdog 时返回 400，已有会话时返回 409，会话不存在或已超时返回 404
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, device.ErrWatchdogDisabled):
+		return http.StatusBadRequest
+	case errors.Is(err, device.ErrSessionActive):
+		return http.StatusConflict
+	case errors.Is(err, device.ErrSessionNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// handleOpenSession 开始控制会话，客户端需要在 timeoutMs 内发送心跳，否则设备停止动画并驱动到安全姿态
+func (s *Server) handleOpenSession(c *gin.Context) {
+	dev, ok := s.watchdogDevice(c)
+	if !ok {
+		return
+	}
+
+	session, err := dev.OpenSession(c.ClientIP())
+	if err != nil {
+		c.JSON(sessionErrorStatus(err), ApiResponse{
+			Status: "error",
+			Error:  "开始控制会话失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: "控制会话已开始",
+		Data:    session,
+	})
+}
+
+// handleSessionHeartbeat 刷新控制会话的超时时间
+func (s *Server) handleSessionHeartbeat(c *gin.Context) {
+	dev, ok := s.watchdogDevice(c)
+	if !ok {
+		return
+	}
+
+	session, err := dev.Heartbeat(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(sessionErrorStatus(err), ApiResponse{
+			Status: "error",
+			Error:  "心跳失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data:   session,
+	})
+}
+
+// handleCloseSession 正常结束控制会话，设备保持当前姿态
+func (s *Server) handleCloseSession(c *gin.Context) {
+	dev, ok := s.watchdogDevice(c)
+	if !ok {
+		return
+	}
+
+	if err := dev.CloseSession(c.Param("sessionId")); err != nil {
+		c.JSON(sessionErrorStatus(err), ApiResponse{
+			Status: "error",
+			Error:  "结束控制会话失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: "控制会话已结束",
+	})
+}
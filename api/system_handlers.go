@@ -1,9 +1,12 @@
 package api
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"hands/communication"
 	"hands/device"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +28,25 @@ func (s *Server) handleGetSupportedModels(c *gin.Context) {
 	})
 }
 
+// handleGetModelCapabilities 获取型号的能力描述：关节名称、数量和范围，支持的指令、单位、预设和动画
+func (s *Server) handleGetModelCapabilities(c *gin.Context) {
+	model := c.Param("model")
+
+	capabilities, ok := device.GetModelCapabilities(model)
+	if !ok {
+		c.JSON(http.StatusNotFound, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("未知的设备型号: %s", model),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data:   capabilities,
+	})
+}
+
 // handleGetSystemStatus 获取系统状态
 func (s *Server) handleGetSystemStatus(c *gin.Context) {
 	// 获取所有设备
@@ -66,12 +88,92 @@ func (s *Server) handleGetSystemStatus(c *gin.Context) {
 	// 计算系统运行时间
 	uptime := time.Since(s.startTime)
 
+	// 收集通信客户端的熔断器状态
+	breakers := make(map[string]communication.BreakerStatus)
+	failovers := make(map[string]communication.FailoverStatus)
+	for name, comm := range communication.GetCommunicators() {
+		if reporter, ok := communication.As[communication.BreakerReporter](comm); ok {
+			breakers[name] = reporter.BreakerStatus()
+		}
+		if reporter, ok := communication.As[communication.FailoverReporter](comm); ok {
+			failovers[name] = reporter.FailoverStatus()
+		}
+	}
+
 	response := SystemStatusResponse{
 		TotalDevices:    totalDevices,
 		ActiveDevices:   activeDevices,
 		SupportedModels: supportedModels,
 		Devices:         deviceInfos,
 		Uptime:          uptime,
+		Breakers:        breakers,
+		Health:          communication.GetHealthSnapshots(),
+		Failovers:       failovers,
+		EmergencyStop:   s.deviceManager.EmergencyStopState(),
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status: "success",
+		Data:   response,
+	})
+}
+
+// handleEmergencyStop 急停所有设备：停止动画、驱动到安全姿态并锁定，直到调用 /estop/release
+// 锁定总是生效；有设备未能到达安全姿态时返回 207 和各设备的结果
+func (s *Server) handleEmergencyStop(c *gin.Context) {
+	status := s.deviceManager.EmergencyStop(device.CommandOrigin{
+		Source:   device.SourceAPI,
+		Detail:   c.Request.Method + " " + c.Request.URL.Path,
+		Client:   c.ClientIP(),
+		Priority: device.PriorityEmergency,
+	})
+
+	failed := 0
+	for _, result := range status.Results {
+		if !result.Success {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		c.JSON(http.StatusMultiStatus, ApiResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("已急停锁定 %d 个设备，其中 %d 个未能驱动到安全姿态", len(status.Results), failed),
+			Data:   status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("已急停锁定 %d 个设备", len(status.Results)),
+		Data:    status,
+	})
+}
+
+// handleReleaseEmergencyStop 解除急停锁定，设备停留在安全姿态
+func (s *Server) handleReleaseEmergencyStop(c *gin.Context) {
+	if !s.deviceManager.EmergencyStopState().Latched {
+		c.JSON(http.StatusConflict, ApiResponse{
+			Status: "error",
+			Error:  "当前没有急停",
+		})
+		return
+	}
+
+	status := s.deviceManager.ReleaseEmergencyStop()
+
+	c.JSON(http.StatusOK, ApiResponse{
+		Status:  "success",
+		Message: fmt.Sprintf("已解除 %d 个设备的急停", len(status.Results)),
+		Data:    status,
+	})
+}
+
+// handleGetInterfaceStats 获取各 CAN 接口的发送速率、限速和估算的总线负载
+func (s *Server) handleGetInterfaceStats(c *gin.Context) {
+	response := InterfaceStatsResponse{
+		Communicators: communication.GetInterfaceStats(),
 	}
 
 	c.JSON(http.StatusOK, ApiResponse{
@@ -112,3 +214,44 @@ func (s *Server) handleHealthCheck(c *gin.Context) {
 		Data:   response,
 	})
 }
+
+// handleStreamEvents 以 Server-Sent Events 推送通信服务和 CAN 接口的健康状态变化 (health)，
+// 以及设备事件 (device)；查询参数 deviceId 和 type 过滤设备事件，见 EventStreamQuery
+func (s *Server) handleStreamEvents(c *gin.Context) {
+	var query EventStreamQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, ApiResponse{
+			Status: "error",
+			Error:  "无效的查询参数：" + err.Error(),
+		})
+		return
+	}
+
+	sub := communication.SubscribeHealthEvents()
+	defer sub.Close()
+	deviceSub := device.SubscribeEvents(device.EventFilter{DeviceID: query.DeviceID, Types: query.Types}, 0)
+	defer deviceSub.Close()
+
+	// 先推送当前状态，客户端不必等待下一次变化
+	c.SSEvent("snapshot", communication.GetHealthSnapshots())
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent("health", event)
+			return true
+		case event, ok := <-deviceSub.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent("device", event)
+			return true
+		}
+	})
+}
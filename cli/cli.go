@@ -5,9 +5,12 @@ import (
 	"flag"
 	"hands/define"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // 解析配置
@@ -16,16 +19,32 @@ func ParseConfig() *define.Config {
 
 	// 命令行参数
 	var canInterfacesFlag string
-	flag.StringVar(&cfg.CanServiceURL, "can-url", "http://127.0.0.1:5260", "CAN 服务的 URL")
+	flag.StringVar(&cfg.CanServiceURL, "can-url", "http://127.0.0.1:5260", "CAN 服务的 URL，多个 URL 用逗号分隔时按顺序故障转移")
+	flag.StringVar(&cfg.Transport, "transport", "can-bridge", "默认设备的传输方式 (can-bridge、socketcan 或 sim)")
 	flag.StringVar(&cfg.WebPort, "port", "9099", "Web 服务的端口")
 	flag.StringVar(&cfg.DefaultInterface, "interface", "", "默认 CAN 接口")
 	flag.StringVar(&canInterfacesFlag, "can-interfaces", "", "支持的 CAN 接口列表，用逗号分隔 (例如：can0,can1,vcan0)")
+	flag.IntVar(&cfg.SendRetries, "can-retries", 3, "姿态帧发送到 can-bridge 失败后的最大重试次数")
+	flag.DurationVar(&cfg.RetryBaseDelay, "can-retry-delay", 50*time.Millisecond, "重试退避的基础间隔")
+	flag.IntVar(&cfg.BreakerThreshold, "can-breaker-threshold", 5, "连续失败多少次后熔断，0 表示不熔断")
+	flag.DurationVar(&cfg.BreakerOpenTimeout, "can-breaker-timeout", 5*time.Second, "熔断后多久尝试恢复")
+	flag.StringVar(&cfg.RecordDir, "record-dir", "recordings", "CAN 流量录制文件的存放目录")
+	flag.StringVar(&cfg.DeviceStore, "device-store", "devices.json", "设备注册表文件路径，为空时不持久化设备")
+	flag.StringVar(&cfg.ModelDir, "model-dir", "models", "型号描述文件 (*.json) 所在目录，目录不存在时只使用内置型号")
+	flag.DurationVar(&cfg.HealthInterval, "health-interval", 2*time.Second, "通信服务和接口健康检查的轮询间隔")
+	flag.Float64Var(&cfg.RateLimit, "can-rate-limit", 1000, "每个 CAN 接口每秒最多发送的帧数，0 表示不限速")
+	flag.IntVar(&cfg.RateBurst, "can-rate-burst", 100, "发送限速允许的突发帧数")
+	flag.IntVar(&cfg.Bitrate, "can-bitrate", 1_000_000, "CAN 总线波特率，用于估算总线负载")
+	flag.IntVar(&cfg.DataBitrate, "can-data-bitrate", 5_000_000, "CAN FD 数据段波特率，用于估算总线负载")
 	flag.Parse()
 
 	// 环境变量覆盖命令行参数
 	if envURL := os.Getenv("CAN_SERVICE_URL"); envURL != "" {
 		cfg.CanServiceURL = envURL
 	}
+	if envTransport := os.Getenv("CAN_TRANSPORT"); envTransport != "" {
+		cfg.Transport = envTransport
+	}
 	if envPort := os.Getenv("WEB_PORT"); envPort != "" {
 		cfg.WebPort = envPort
 	}
@@ -35,6 +54,79 @@ func ParseConfig() *define.Config {
 	if envInterfaces := os.Getenv("CAN_INTERFACES"); envInterfaces != "" {
 		canInterfacesFlag = envInterfaces
 	}
+	if envRecordDir := os.Getenv("CAN_RECORD_DIR"); envRecordDir != "" {
+		cfg.RecordDir = envRecordDir
+	}
+	if envDeviceStore, ok := os.LookupEnv("DEVICE_STORE"); ok {
+		cfg.DeviceStore = envDeviceStore
+	}
+	if envModelDir := os.Getenv("MODEL_DIR"); envModelDir != "" {
+		cfg.ModelDir = envModelDir
+	}
+	if envRetries := os.Getenv("CAN_RETRIES"); envRetries != "" {
+		if v, err := strconv.Atoi(envRetries); err == nil {
+			cfg.SendRetries = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_RETRIES: %s", envRetries)
+		}
+	}
+	if envDelay := os.Getenv("CAN_RETRY_DELAY"); envDelay != "" {
+		if v, err := time.ParseDuration(envDelay); err == nil {
+			cfg.RetryBaseDelay = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_RETRY_DELAY: %s", envDelay)
+		}
+	}
+	if envThreshold := os.Getenv("CAN_BREAKER_THRESHOLD"); envThreshold != "" {
+		if v, err := strconv.Atoi(envThreshold); err == nil {
+			cfg.BreakerThreshold = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_BREAKER_THRESHOLD: %s", envThreshold)
+		}
+	}
+	if envTimeout := os.Getenv("CAN_BREAKER_TIMEOUT"); envTimeout != "" {
+		if v, err := time.ParseDuration(envTimeout); err == nil {
+			cfg.BreakerOpenTimeout = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_BREAKER_TIMEOUT: %s", envTimeout)
+		}
+	}
+	if envInterval := os.Getenv("CAN_HEALTH_INTERVAL"); envInterval != "" {
+		if v, err := time.ParseDuration(envInterval); err == nil {
+			cfg.HealthInterval = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_HEALTH_INTERVAL: %s", envInterval)
+		}
+	}
+
+	if envRate := os.Getenv("CAN_RATE_LIMIT"); envRate != "" {
+		if v, err := strconv.ParseFloat(envRate, 64); err == nil {
+			cfg.RateLimit = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_RATE_LIMIT: %s", envRate)
+		}
+	}
+	if envBurst := os.Getenv("CAN_RATE_BURST"); envBurst != "" {
+		if v, err := strconv.Atoi(envBurst); err == nil {
+			cfg.RateBurst = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_RATE_BURST: %s", envBurst)
+		}
+	}
+	if envBitrate := os.Getenv("CAN_BITRATE"); envBitrate != "" {
+		if v, err := strconv.Atoi(envBitrate); err == nil {
+			cfg.Bitrate = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_BITRATE: %s", envBitrate)
+		}
+	}
+	if envDataBitrate := os.Getenv("CAN_DATA_BITRATE"); envDataBitrate != "" {
+		if v, err := strconv.Atoi(envDataBitrate); err == nil {
+			cfg.DataBitrate = v
+		} else {
+			log.Printf("⚠️ 无效的 CAN_DATA_BITRATE: %s", envDataBitrate)
+		}
+	}
 
 	// 解析可用接口
 	if canInterfacesFlag != "" {
@@ -45,10 +137,18 @@ func ParseConfig() *define.Config {
 		}
 	}
 
-	// 如果没有指定可用接口，从 CAN 服务获取
+	// 如果没有指定可用接口，从 CAN 服务或本机网络接口获取
 	if len(cfg.AvailableInterfaces) == 0 {
-		log.Println("🔍 未指定可用接口，将从 CAN 服务获取...")
-		cfg.AvailableInterfaces = getAvailableInterfacesFromCanService(cfg.CanServiceURL)
+		if cfg.Transport == "socketcan" {
+			log.Println("🔍 未指定可用接口，将从本机网络接口获取...")
+			cfg.AvailableInterfaces = getAvailableInterfacesFromSystem()
+		} else if cfg.Transport == "sim" || strings.HasPrefix(cfg.CanServiceURL, "sim://") {
+			log.Println("🧪 使用模拟总线，默认接口为 can0, can1")
+			cfg.AvailableInterfaces = []string{"can0", "can1"}
+		} else {
+			log.Println("🔍 未指定可用接口，将从 CAN 服务获取...")
+			cfg.AvailableInterfaces = getAvailableInterfacesFromCanService(cfg.CanServiceURL)
+		}
 	}
 
 	// 设置默认接口
@@ -59,11 +159,19 @@ func ParseConfig() *define.Config {
 	return cfg
 }
 
-// 从 CAN 服务获取可用接口
-func getAvailableInterfacesFromCanService(canServiceURL string) []string {
-	resp, err := http.Get(canServiceURL + "/api/interfaces")
+// 从 CAN 服务获取可用接口，配置了多个 CAN 服务时按顺序使用第一个可以访问的
+func getAvailableInterfacesFromCanService(canServiceURLs string) []string {
+	var resp *http.Response
+	var err error
+	for canServiceURL := range strings.SplitSeq(canServiceURLs, ",") {
+		resp, err = http.Get(strings.TrimSpace(canServiceURL) + "/api/interfaces")
+		if err == nil {
+			break
+		}
+		log.Printf("⚠️ 无法从 CAN 服务 %s 获取接口列表: %v", canServiceURL, err)
+	}
 	if err != nil {
-		log.Printf("⚠️ 无法从 CAN 服务获取接口列表: %v，使用默认配置", err)
+		log.Printf("⚠️ 无法从 CAN 服务获取接口列表，使用默认配置")
 		return []string{"can0", "can1"} // 默认接口
 	}
 	defer resp.Body.Close()
@@ -92,3 +200,27 @@ func getAvailableInterfacesFromCanService(canServiceURL string) []string {
 	log.Println("⚠️ 无法从 CAN 服务获取有效接口，使用默认配置")
 	return []string{"can0", "can1"}
 }
+
+// 从本机网络接口中查找 CAN 接口 (canX / vcanX)
+func getAvailableInterfacesFromSystem() []string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		log.Printf("⚠️ 无法获取本机网络接口: %v，使用默认配置", err)
+		return []string{"can0", "can1"}
+	}
+
+	interfaces := make([]string, 0)
+	for _, iface := range ifaces {
+		if strings.HasPrefix(iface.Name, "can") || strings.HasPrefix(iface.Name, "vcan") {
+			interfaces = append(interfaces, iface.Name)
+		}
+	}
+
+	if len(interfaces) == 0 {
+		log.Println("⚠️ 本机没有找到 CAN 接口，使用默认配置")
+		return []string{"can0", "can1"}
+	}
+
+	log.Printf("✅ 从本机获取到 CAN 接口: %v", interfaces)
+	return interfaces
+}
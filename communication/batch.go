@@ -0,0 +1,27 @@
+package communication
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// sendSequentially 按顺序逐帧发送，帧前按 Delay 等待，供本地直接发送帧的实现复用
+func sendSequentially(ctx context.Context, frames []BatchFrame, send func(ctx context.Context, msg RawMessage) error) error {
+	for i, frame := range frames {
+		if frame.Delay > 0 {
+			timer := time.NewTimer(frame.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("批量发送在第 %d 帧前被取消：%w", i+1, ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		if err := send(ctx, frame.Message); err != nil {
+			return fmt.Errorf("批量发送第 %d/%d 帧失败：%w", i+1, len(frames), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package communication
+
+import (
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态时快速失败返回的错误
+var ErrCircuitOpen = errors.New("can-bridge 熔断器已打开，暂停发送")
+
+// RetryPolicy 定义幂等帧发送失败后的重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0 表示不重试
+	BaseDelay  time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay   time.Duration // 单次等待时间上限
+}
+
+// DefaultRetryPolicy 默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  50 * time.Millisecond,
+		MaxDelay:   1 * time.Second,
+	}
+}
+
+// Backoff 返回第 attempt 次重试（从 1 开始）前的等待时间
+// 使用带抖动的指数退避：在 [d/2, d] 范围内随机取值，避免多个客户端同时重试
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行
+	BreakerOpen     BreakerState = "open"      // 快速失败
+	BreakerHalfOpen BreakerState = "half-open" // 放行一个探测请求
+)
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	FailureThreshold int           // 连续失败多少次后打开熔断器
+	OpenTimeout      time.Duration // 打开后经过多久进入半开状态尝试恢复
+}
+
+// DefaultBreakerConfig 默认熔断器配置
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      5 * time.Second,
+	}
+}
+
+// BreakerStatus 熔断器状态快照
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	Trips               int          `json:"trips"` // 累计打开次数
+	OpenedAt            time.Time    `json:"openedAt,omitzero"`
+	LastError           string       `json:"lastError,omitempty"`
+}
+
+// BreakerReporter 由带熔断器的 Communicator 实现，用于对外展示熔断器状态
+type BreakerReporter interface {
+	BreakerStatus() BreakerStatus
+}
+
+// CircuitBreaker 一个简单的三态熔断器
+type CircuitBreaker struct {
+	name          string // 用于日志
+	config        BreakerConfig
+	state         BreakerState
+	failures      int
+	trips         int
+	openedAt      time.Time
+	lastError     string
+	probeInFlight bool // 半开状态下是否已有探测请求
+	mutex         sync.Mutex
+}
+
+// NewCircuitBreaker 创建熔断器，FailureThreshold <= 0 表示禁用熔断
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:   name,
+		config: config,
+		state:  BreakerClosed,
+	}
+}
+
+// Allow 判断是否放行一次请求，不放行时返回 ErrCircuitOpen
+func (b *CircuitBreaker) Allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.config.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.probeInFlight = true
+		log.Printf("🟡 %s 熔断器进入半开状态，尝试恢复", b.name)
+		return nil
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			return ErrCircuitOpen
+		}
+		b.probeInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success 记录一次成功
+func (b *CircuitBreaker) Success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state != BreakerClosed {
+		log.Printf("🟢 %s 熔断器已关闭，恢复正常发送", b.name)
+	}
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probeInFlight = false
+}
+
+// Failure 记录一次失败，达到阈值或半开探测失败时打开熔断器
+func (b *CircuitBreaker) Failure(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.config.FailureThreshold <= 0 {
+		return
+	}
+
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.config.FailureThreshold) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.trips++
+		b.probeInFlight = false
+		log.Printf("🔴 %s 熔断器已打开 (连续失败 %d 次)，%v 后尝试恢复", b.name, b.failures, b.config.OpenTimeout)
+	}
+}
+
+// Abandon 请求未得出结果（例如被调用方取消）时释放半开状态的探测名额
+func (b *CircuitBreaker) Abandon() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probeInFlight = false
+}
+
+// Status 返回熔断器状态快照
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	status := BreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		LastError:           b.lastError,
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt
+	}
+	return status
+}
@@ -0,0 +1,144 @@
+package communication
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// canStandardIDMax 标准帧 (11 位) ID 的最大值，超过则按扩展帧处理
+const canStandardIDMax = 0x7FF
+
+// canExtendedIDMax 扩展帧 (29 位) ID 的最大值
+const canExtendedIDMax = 0x1FFFFFFF
+
+// candumpFlagBRS candump FD 帧标志位中的位速率切换标志
+const candumpFlagBRS = 0x1
+
+// LoggedFrame candump 日志中的一帧
+type LoggedFrame struct {
+	Timestamp time.Time
+	Message   RawMessage
+}
+
+// FormatCandumpLine 按 Linux `candump -l` 的格式输出一行，例如：
+//
+//	(1436509052.249713) vcan0 044#2A366C2BBA
+//
+// 标准帧 ID 为 3 位十六进制，扩展帧 ID 为 8 位十六进制，解析时按位数还原帧格式；
+// FD 帧使用 "ID##<标志><数据>" 格式，标志为一位十六进制数，BRS 对应 0x1
+func FormatCandumpLine(ts time.Time, msg RawMessage) string {
+	return fmt.Sprintf("(%d.%06d) %s", ts.Unix(), ts.Nanosecond()/1000, FormatCandumpFrame(msg))
+}
+
+// FormatCandumpFrame 按 candump 的格式输出不带时间戳的一帧，例如 "vcan0 044#2A366C2BBA"
+func FormatCandumpFrame(msg RawMessage) string {
+	var id string
+	if msg.IsExtended() {
+		id = fmt.Sprintf("%08X", msg.ID)
+	} else {
+		id = fmt.Sprintf("%03X", msg.ID)
+	}
+
+	separator := "#"
+	if msg.FD {
+		var flags byte
+		if msg.BRS {
+			flags |= candumpFlagBRS
+		}
+		separator = fmt.Sprintf("##%X", flags)
+	}
+
+	return fmt.Sprintf("%s %s%s%s", msg.Interface, id, separator, strings.ToUpper(hex.EncodeToString(msg.Data)))
+}
+
+// ParseCandumpLine 解析一行 `candump -l` 格式的日志
+func ParseCandumpLine(line string) (LoggedFrame, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return LoggedFrame{}, fmt.Errorf("字段数量错误: %q", line)
+	}
+
+	// 时间戳 (秒.微秒)
+	tsField := strings.TrimSuffix(strings.TrimPrefix(fields[0], "("), ")")
+	secStr, usecStr, ok := strings.Cut(tsField, ".")
+	if !ok {
+		return LoggedFrame{}, fmt.Errorf("无效的时间戳: %s", fields[0])
+	}
+	sec, err := strconv.ParseInt(secStr, 10, 64)
+	if err != nil {
+		return LoggedFrame{}, fmt.Errorf("无效的时间戳: %s", fields[0])
+	}
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil {
+		return LoggedFrame{}, fmt.Errorf("无效的时间戳: %s", fields[0])
+	}
+
+	// 帧内容 ID#DATA
+	idStr, dataStr, ok := strings.Cut(fields[2], "#")
+	if !ok {
+		return LoggedFrame{}, fmt.Errorf("无效的帧内容: %s", fields[2])
+	}
+	id, err := strconv.ParseUint(idStr, 16, 32)
+	if err != nil {
+		return LoggedFrame{}, fmt.Errorf("无效的 CAN ID: %s", idStr)
+	}
+
+	// 8 位 ID 为扩展帧，即使 ID 不超过 0x7FF
+	msg := RawMessage{Interface: fields[1], ID: uint32(id), Extended: len(idStr) == 8}
+
+	// FD 帧：ID##<标志><数据>
+	if rest, ok := strings.CutPrefix(dataStr, "#"); ok {
+		if rest == "" {
+			return LoggedFrame{}, fmt.Errorf("FD 帧缺少标志位: %s", fields[2])
+		}
+		flags, err := strconv.ParseUint(rest[:1], 16, 8)
+		if err != nil {
+			return LoggedFrame{}, fmt.Errorf("无效的 FD 帧标志位: %s", rest[:1])
+		}
+		msg.FD = true
+		msg.BRS = flags&candumpFlagBRS != 0
+		dataStr = rest[1:]
+	}
+
+	data, err := hex.DecodeString(dataStr)
+	if err != nil {
+		return LoggedFrame{}, fmt.Errorf("无效的帧数据: %s", dataStr)
+	}
+	msg.Data = data
+	if err := msg.Validate(); err != nil {
+		return LoggedFrame{}, err
+	}
+
+	return LoggedFrame{
+		Timestamp: time.Unix(sec, usec*1000),
+		Message:   msg,
+	}, nil
+}
+
+// ReadCandumpLog 读取整个 candump 日志，空行会被跳过
+func ReadCandumpLog(r io.Reader) ([]LoggedFrame, error) {
+	frames := make([]LoggedFrame, 0)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		frame, err := ParseCandumpLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行：%w", lineNo, err)
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取日志失败：%w", err)
+	}
+	return frames, nil
+}
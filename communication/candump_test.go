@@ -0,0 +1,40 @@
+package communication
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCandumpRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		msg  RawMessage
+	}{
+		{name: "标准帧", line: "(1436509052.249713) vcan0 044#2A366C2BBA", msg: RawMessage{Interface: "vcan0", ID: 0x44, Data: []byte{0x2A, 0x36, 0x6C, 0x2B, 0xBA}}},
+		{name: "扩展帧", line: "(1436509052.249713) vcan0 12345678#01", msg: RawMessage{Interface: "vcan0", ID: 0x12345678, Data: []byte{0x01}, Extended: true}},
+		{name: "ID 较小的扩展帧", line: "(1436509052.249713) vcan0 00000027#01", msg: RawMessage{Interface: "vcan0", ID: 0x27, Data: []byte{0x01}, Extended: true}},
+		{name: "FD 帧带 BRS", line: "(1436509052.249713) vcan0 028##10102", msg: RawMessage{Interface: "vcan0", ID: 0x28, Data: []byte{0x01, 0x02}, FD: true, BRS: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := ParseCandumpLine(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := frame.Message
+			if got.Interface != tt.msg.Interface || got.ID != tt.msg.ID || got.Extended != tt.msg.Extended ||
+				got.FD != tt.msg.FD || got.BRS != tt.msg.BRS || !bytes.Equal(got.Data, tt.msg.Data) {
+				t.Fatalf("解析得到 %+v，期望 %+v", got, tt.msg)
+			}
+			if line := FormatCandumpLine(frame.Timestamp, got); line != tt.line {
+				t.Fatalf("格式化得到 %q，期望 %q", line, tt.line)
+			}
+		})
+	}
+
+	if _, err := ParseCandumpLine("(1436509052.249713) vcan0 027#0102030405060708FF"); err == nil {
+		t.Fatal("经典帧超过 8 字节时应返回错误")
+	}
+}
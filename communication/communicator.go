@@ -4,20 +4,46 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hands/config"
 	"hands/define"
 	"io"
+	"log"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	streamRetryMinDelay = 1 * time.Second  // 接收流断开后的最小重连间隔
+	streamRetryMaxDelay = 30 * time.Second // 接收流断开后的最大重连间隔
+)
+
 // TODO: ID 的作用是什么
 // RawMessage 代表发送给 can-bridge 服务或从其接收的原始消息结构
 type RawMessage struct {
-	Interface string `json:"interface"` // 目标 CAN 接口名，例如 "can0", "vcan1"
-	ID        uint32 `json:"id"`        // CAN 帧的 ID
-	Data      []byte `json:"data"`      // CAN 帧的数据负载
+	Interface string `json:"interface"`     // 目标 CAN 接口名，例如 "can0", "vcan1"
+	ID        uint32 `json:"id"`            // CAN 帧的 ID
+	Data      []byte `json:"data"`          // CAN 帧的数据负载，经典帧最多 8 字节，FD 帧最多 64 字节
+	FD        bool   `json:"fd,omitempty"`  // 是否为 CAN FD 帧
+	BRS       bool   `json:"brs,omitempty"` // CAN FD 位速率切换，数据段使用更高的波特率，仅 FD 帧有效
+
+	// Extended 表示按扩展帧 (29 位 ID) 发送，ID 超过 0x7FF 时总是扩展帧；用于保留 ID 较小的扩展帧的帧格式
+	Extended bool `json:"extended,omitempty"`
+
+	// Idempotent 表示重复发送该帧不会改变结果（例如绝对位置的姿态帧），发送失败时允许重试
+	Idempotent bool `json:"-"`
+
+	// Coalesce 表示该帧等待发送时可以被同接口、同 CAN ID、同指令前缀的新帧取代（例如姿态帧只需发送最新的位置）
+	Coalesce bool `json:"-"`
+}
+
+// BatchFrame 批量发送中的一帧
+type BatchFrame struct {
+	Message RawMessage    // 要发送的帧
+	Delay   time.Duration // 发送本帧之前等待的时间，用于保持帧间隔
 }
 
 // Communicator 定义了与 can-bridge Web 服务进行通信的接口
@@ -25,6 +51,9 @@ type Communicator interface {
 	// SendMessage 将 RawMessage 通过 HTTP POST 请求发送到 can-bridge 服务
 	SendMessage(ctx context.Context, msg RawMessage) error
 
+	// SendBatch 按顺序发送一组帧，整组帧在一次请求中交给传输层，帧间按 Delay 间隔发出
+	SendBatch(ctx context.Context, frames []BatchFrame) error
+
 	// GetAllInterfaceStatuses 获取所有已知 CAN 接口的状态
 	GetAllInterfaceStatuses() (statuses map[string]bool, err error)
 
@@ -33,28 +62,194 @@ type Communicator interface {
 
 	// IsConnected 检查与 can-bridge 服务的连接状态
 	IsConnected() bool
+
+	// Subscribe 订阅接收到的 CAN 帧，filter 用于按接口和 CAN ID 过滤
+	Subscribe(filter FrameFilter) (Subscription, error)
+}
+
+// ReceiveCapable 由可能无法接收帧的 Communicator 实现，例如没有 /api/can/stream 的旧版 can-bridge
+type ReceiveCapable interface {
+	// CanReceive 返回 false 表示已确认无法接收帧，订阅不会收到任何帧
+	CanReceive() bool
+}
+
+// CanReceive 判断通信客户端（沿装饰器链查找）是否能接收帧，没有实现 ReceiveCapable 的客户端视为可以接收
+func CanReceive(comm Communicator) bool {
+	r, ok := As[ReceiveCapable](comm)
+	return !ok || r.CanReceive()
+}
+
+// CanBridgeOptions can-bridge 客户端的可选配置
+type CanBridgeOptions struct {
+	Retry   RetryPolicy   // 幂等帧的重试策略
+	Breaker BreakerConfig // 熔断器配置
 }
 
 // CanBridgeClient 实现与 can-bridge 服务的 HTTP 通信
 type CanBridgeClient struct {
-	serviceURL string
-	client     *http.Client
+	serviceURL   string       // 通过 getServiceURL/SetServiceURL 访问
+	urlMutex     sync.RWMutex // 保护 serviceURL
+	client       *http.Client
+	streamClient *http.Client // 接收流为长连接，不能使用带整体超时的 client
+	retry        RetryPolicy
+	breaker      *CircuitBreaker
+	hub          *subscriberHub
+	streamCancel context.CancelFunc // 当前接收流的取消函数，为空表示未在接收
+	streamMutex  sync.Mutex
+
+	streamUnsupported atomic.Bool // bridge 没有提供 /api/can/stream，无法接收帧
+}
+
+// bridgeStatusError can-bridge 返回了非 200 状态码
+type bridgeStatusError struct {
+	code int
+	body string
+}
+
+func (e *bridgeStatusError) Error() string {
+	return fmt.Sprintf("can-bridge服务返回错误: %d, %s", e.code, e.body)
 }
 
 func NewCanBridgeClient(serviceURL string) Communicator {
-	return &CanBridgeClient{
-		serviceURL: serviceURL,
-		client:     &http.Client{Timeout: 5 * time.Second},
+	return NewCanBridgeClientWithOptions(serviceURL, CanBridgeOptions{
+		Retry:   DefaultRetryPolicy(),
+		Breaker: DefaultBreakerConfig(),
+	})
+}
+
+func NewCanBridgeClientWithOptions(serviceURL string, opts CanBridgeOptions) Communicator {
+	c := &CanBridgeClient{
+		serviceURL:   serviceURL,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		streamClient: &http.Client{},
+		retry:        opts.Retry,
+		breaker:      NewCircuitBreaker("can-bridge "+serviceURL, opts.Breaker),
+		hub:          newSubscriberHub(),
 	}
+	c.hub.onIdle = c.stopStream
+	return c
 }
 
+// SendMessage 发送一帧，熔断器打开时快速失败；幂等帧在可重试的错误下按退避策略重试
 func (c *CanBridgeClient) SendMessage(ctx context.Context, msg RawMessage) error {
-	jsonData, err := json.Marshal(msg)
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	return c.sendWithRetry(ctx, msg.Idempotent, func(ctx context.Context) error {
+		return c.postJSON(ctx, "/api/can", msg)
+	})
+}
+
+// batchFrameRequest 批量发送请求中的一帧，在 RawMessage 基础上附加帧前延时
+type batchFrameRequest struct {
+	RawMessage
+	DelayMs int64 `json:"delayMs,omitempty"`
+}
+
+// batchRequest POST /api/can/batch 的请求体，can-bridge 按顺序发送并在帧间等待 delayMs
+type batchRequest struct {
+	Frames []batchFrameRequest `json:"frames"`
+}
+
+// SendBatch 通过一次 HTTP POST 发送一组帧，全部为幂等帧时才允许整体重试
+func (c *CanBridgeClient) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	req := batchRequest{Frames: make([]batchFrameRequest, 0, len(frames))}
+	idempotent := true
+	for i, frame := range frames {
+		if err := frame.Message.Validate(); err != nil {
+			return fmt.Errorf("批量发送第 %d/%d 帧无效：%w", i+1, len(frames), err)
+		}
+		req.Frames = append(req.Frames, batchFrameRequest{
+			RawMessage: frame.Message,
+			DelayMs:    frame.Delay.Milliseconds(),
+		})
+		idempotent = idempotent && frame.Message.Idempotent
+	}
+
+	return c.sendWithRetry(ctx, idempotent, func(ctx context.Context) error {
+		return c.postJSON(ctx, "/api/can/batch", req)
+	})
+}
+
+// sendWithRetry 执行一次发送，熔断器打开时快速失败；idempotent 为 true 时在可重试的错误下按退避策略重试
+func (c *CanBridgeClient) sendWithRetry(ctx context.Context, idempotent bool, send func(ctx context.Context) error) error {
+	attempts := 1
+	if idempotent {
+		attempts += c.retry.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("重试被取消：%w (上次错误：%v)", ctx.Err(), lastErr)
+			case <-time.After(c.retry.Backoff(attempt)):
+			}
+		}
+
+		if err := c.breaker.Allow(); err != nil {
+			if lastErr != nil {
+				return fmt.Errorf("%w (上次错误：%v)", err, lastErr)
+			}
+			return err
+		}
+
+		err := send(ctx)
+		switch {
+		case err == nil:
+			c.breaker.Success()
+			return nil
+		case errors.Is(err, context.Canceled):
+			// 调用方主动取消，不能说明服务状态
+			c.breaker.Abandon()
+			return err
+		case !isRetryableBridgeError(err):
+			// 服务正常响应但拒绝了请求，重试没有意义
+			c.breaker.Success()
+			return err
+		}
+
+		c.breaker.Failure(err)
+		lastErr = err
+		if attempt+1 < attempts {
+			log.Printf("⚠️ 发送到 can-bridge 失败 (第 %d/%d 次): %v", attempt+1, attempts, err)
+		}
+	}
+
+	if attempts > 1 {
+		return fmt.Errorf("重试 %d 次后仍失败：%w", attempts-1, lastErr)
+	}
+	return lastErr
+}
+
+// isRetryableBridgeError 判断错误是否是临时性的：网络错误、超时以及 429/5xx 响应
+func isRetryableBridgeError(err error) bool {
+	var statusErr *bridgeStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// SupportsFD can-bridge 透传 fd/brs 标志，是否真正支持 FD 取决于 bridge 侧接口的配置
+func (c *CanBridgeClient) SupportsFD() bool { return true }
+
+// BreakerStatus 返回熔断器状态
+func (c *CanBridgeClient) BreakerStatus() BreakerStatus { return c.breaker.Status() }
+
+// postJSON 将 body 序列化为 JSON 并 POST 到 can-bridge 的指定路径
+func (c *CanBridgeClient) postJSON(ctx context.Context, path string, body any) error {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("序列化消息失败：%w", err)
 	}
 
-	url := fmt.Sprintf("%s/api/can", c.serviceURL)
+	url := c.getServiceURL() + path
 
 	// 创建带有 context 的请求
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
@@ -71,7 +266,7 @@ func (c *CanBridgeClient) SendMessage(ctx context.Context, msg RawMessage) error
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("can-bridge服务返回错误: %d, %s", resp.StatusCode, string(body))
+		return &bridgeStatusError{code: resp.StatusCode, body: string(body)}
 	}
 
 	return nil
@@ -81,7 +276,7 @@ func (c *CanBridgeClient) GetAllInterfaceStatuses() (map[string]bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 
-	url := fmt.Sprintf("%s/api/status", c.serviceURL)
+	url := fmt.Sprintf("%s/api/status", c.getServiceURL())
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取所有接口状态失败：%w", err)
@@ -122,9 +317,151 @@ func (c *CanBridgeClient) GetAllInterfaceStatuses() (map[string]bool, error) {
 	return result, nil
 }
 
-func (c *CanBridgeClient) SetServiceURL(url string) { c.serviceURL = url }
+// getServiceURL 返回当前的 can-bridge 地址
+func (c *CanBridgeClient) getServiceURL() string {
+	c.urlMutex.RLock()
+	defer c.urlMutex.RUnlock()
+	return c.serviceURL
+}
+
+// SetServiceURL 切换 can-bridge 地址，之后的请求发往新地址，正在运行的接收流重新连接到新地址
+func (c *CanBridgeClient) SetServiceURL(url string) {
+	c.urlMutex.Lock()
+	changed := c.serviceURL != url
+	c.serviceURL = url
+	c.urlMutex.Unlock()
+
+	if changed {
+		c.streamUnsupported.Store(false)
+		c.restartStream()
+	}
+}
 
 func (c *CanBridgeClient) IsConnected() bool {
 	_, err := c.GetAllInterfaceStatuses()
 	return err == nil
 }
+
+// Subscribe 订阅 can-bridge 转发的 CAN 帧
+// 第一个订阅者出现时打开 /api/can/stream 长连接（每行一个 RawMessage JSON），
+// 最后一个订阅者退出时关闭该连接
+// 注册订阅与启动接收流在 streamMutex 下进行，与 stopStream 互斥，
+// 避免最后一个订阅者退出时关闭刚被新订阅者沿用的接收流
+func (c *CanBridgeClient) Subscribe(filter FrameFilter) (Subscription, error) {
+	c.streamMutex.Lock()
+	defer c.streamMutex.Unlock()
+
+	sub := c.hub.add(filter)
+	c.startStreamLocked()
+	return sub, nil
+}
+
+// CanReceive 接收流返回 404/405/501 后为 false，直到再次成功连接
+func (c *CanBridgeClient) CanReceive() bool { return !c.streamUnsupported.Load() }
+
+// startStreamLocked 如果接收流尚未运行则启动它，调用方需持有 c.streamMutex
+func (c *CanBridgeClient) startStreamLocked() {
+	if c.streamCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.streamCancel = cancel
+	go c.runStream(ctx)
+}
+
+// restartStream 接收流正在运行时以当前地址重新连接
+func (c *CanBridgeClient) restartStream() {
+	c.streamMutex.Lock()
+	defer c.streamMutex.Unlock()
+
+	if c.streamCancel == nil {
+		return
+	}
+	c.streamCancel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.streamCancel = cancel
+	go c.runStream(ctx)
+}
+
+// stopStream 最后一个订阅者退出时停止接收流；期间又有新的订阅者时保持运行
+func (c *CanBridgeClient) stopStream() {
+	c.streamMutex.Lock()
+	defer c.streamMutex.Unlock()
+
+	if c.streamCancel != nil && c.hub.count() == 0 {
+		c.streamCancel()
+		c.streamCancel = nil
+	}
+}
+
+// runStream 保持与 can-bridge 的接收流连接，断开后按指数退避重连
+func (c *CanBridgeClient) runStream(ctx context.Context) {
+	delay := streamRetryMinDelay
+	for {
+		connected, err := c.readStream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = streamRetryMinDelay
+		}
+
+		// 不支持接收流时只在第一次记录日志
+		if err != nil && c.CanReceive() {
+			log.Printf("⚠️ can-bridge 接收流中断: %v，%v 后重连", err, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, streamRetryMaxDelay)
+	}
+}
+
+// readStream 建立一次接收流连接并持续分发收到的帧，直到连接断开
+// 返回值 connected 表示本次是否成功建立过连接
+func (c *CanBridgeClient) readStream(ctx context.Context) (connected bool, err error) {
+	url := fmt.Sprintf("%s/api/can/stream", c.getServiceURL())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("创建 HTTP 请求失败：%w", err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("连接接收流失败：%w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		// 旧版 bridge 没有接收流，仍然重试，以便 bridge 升级后恢复接收
+		if !c.streamUnsupported.Swap(true) {
+			log.Printf("ℹ️ can-bridge %s 不支持接收流，无法接收 CAN 帧", c.getServiceURL())
+		}
+		return false, fmt.Errorf("can-bridge 服务不支持接收流：%d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("can-bridge 服务返回错误：%d", resp.StatusCode)
+	}
+
+	c.streamUnsupported.Store(false)
+	log.Printf("📥 已连接 can-bridge 接收流: %s", url)
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg RawMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return true, fmt.Errorf("接收流已关闭")
+			}
+			return true, fmt.Errorf("解析接收帧失败：%w", err)
+		}
+		c.hub.dispatch(msg)
+	}
+}
@@ -0,0 +1,218 @@
+package communication
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitCanReceive 等待 CanReceive 变为 want
+func waitCanReceive(t *testing.T, comm Communicator, want bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for CanReceive(comm) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("CanReceive 没有变为 %v", want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCanBridgeWithoutStreamCannotReceive(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	comm := NewCanBridgeClient(server.URL)
+	if !CanReceive(comm) {
+		t.Fatal("还没有连接接收流时应视为可以接收")
+	}
+
+	sub, err := comm.Subscribe(FrameFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	waitCanReceive(t, comm, false)
+}
+
+func TestCanBridgeWithStreamCanReceive(t *testing.T) {
+	connected := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/can/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"interface":"can0","id":39,"data":"AQI="}` + "\n"))
+		w.(http.Flusher).Flush()
+		close(connected)
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	comm := NewCanBridgeClient(server.URL)
+	sub, err := comm.Subscribe(FrameFilter{Interface: "can0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	select {
+	case msg := <-sub.Frames():
+		if msg.ID != 0x27 || len(msg.Data) != 2 {
+			t.Fatalf("收到 %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("没有收到接收流转发的帧")
+	}
+	<-connected
+	if !CanReceive(comm) {
+		t.Fatal("接收流已连接时应可以接收")
+	}
+}
+
+// frameServer 提供 /api/can 和 /api/can/stream 的 can-bridge，接收流连接后推送一帧 id
+func frameServer(t *testing.T, id uint32, posts *atomic.Int64) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/can", func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	})
+	mux.HandleFunc("GET /api/can/stream", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"interface":"can0","id":%d,"data":"AQ=="}`+"\n", id)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCanBridgeSetServiceURL(t *testing.T) {
+	var postsA, postsB atomic.Int64
+	serverA := frameServer(t, 0x27, &postsA)
+	serverB := frameServer(t, 0x28, &postsB)
+
+	comm := NewCanBridgeClient(serverA.URL)
+	sub, err := comm.Subscribe(FrameFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	receive := func(want uint32) {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for {
+			select {
+			case msg := <-sub.Frames():
+				if msg.ID == want {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("没有收到 %03X", want)
+			}
+		}
+	}
+	receive(0x27)
+
+	// 切换地址与发送并发进行，由 -race 检查
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 10 {
+				comm.SetServiceURL(serverB.URL)
+				return
+			}
+			comm.SendMessage(context.Background(), RawMessage{Interface: "can0", ID: 0x27, Data: []byte{1}})
+		}()
+	}
+	wg.Wait()
+
+	// 接收流重新连接到新地址，之后的发送都发往新地址
+	receive(0x28)
+	before := postsA.Load()
+	if err := comm.SendMessage(context.Background(), RawMessage{Interface: "can0", ID: 0x27, Data: []byte{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if postsA.Load() != before || postsB.Load() == 0 {
+		t.Fatalf("切换后 A 收到 %d 帧、B 收到 %d 帧", postsA.Load()-before, postsB.Load())
+	}
+}
+
+// blockingStreamServer 接收流保持连接直到请求被取消，不推送任何帧
+func blockingStreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCanBridgeSubscribeWhileLastSubscriberLeaves(t *testing.T) {
+	comm := NewCanBridgeClient(blockingStreamServer(t).URL).(*CanBridgeClient)
+
+	for range 200 {
+		old, err := comm.Subscribe(FrameFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 最后一个订阅者退出的同时出现新的订阅者
+		var next Subscription
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			old.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			next, _ = comm.Subscribe(FrameFilter{})
+		}()
+		wg.Wait()
+
+		comm.streamMutex.Lock()
+		running := comm.streamCancel != nil
+		comm.streamMutex.Unlock()
+		if !running {
+			t.Fatal("仍有订阅者时接收流被关闭")
+		}
+
+		next.Close()
+		comm.streamMutex.Lock()
+		running = comm.streamCancel != nil
+		comm.streamMutex.Unlock()
+		if running {
+			t.Fatal("最后一个订阅者退出后接收流仍在运行")
+		}
+	}
+}
+
+// TestCanBridgeLateIdleKeepsStream 按出问题的顺序执行：最后一个订阅者已从 hub 移除、尚未调用 onIdle 时新订阅者加入
+func TestCanBridgeLateIdleKeepsStream(t *testing.T) {
+	comm := NewCanBridgeClient(blockingStreamServer(t).URL).(*CanBridgeClient)
+
+	old, _ := comm.Subscribe(FrameFilter{})
+	comm.hub.mutex.Lock()
+	delete(comm.hub.subscribers, old.(*frameSubscription))
+	comm.hub.mutex.Unlock()
+
+	next, _ := comm.Subscribe(FrameFilter{})
+	defer next.Close()
+	comm.hub.onIdle()
+
+	comm.streamMutex.Lock()
+	defer comm.streamMutex.Unlock()
+	if comm.streamCancel == nil {
+		t.Fatal("仍有订阅者时接收流被关闭")
+	}
+}
@@ -0,0 +1,194 @@
+package communication
+
+import (
+	"fmt"
+	"hands/config"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// 支持的传输方式
+const (
+	TransportCanBridge = "can-bridge" // 通过 can-bridge HTTP 服务收发
+	TransportSocketCAN = "socketcan"  // 直接使用 Linux SocketCAN
+	TransportSim       = "sim"        // 进程内模拟总线
+	TransportSlcan     = "slcan"      // 通过串口上的 Lawicel slcan 协议驱动 USB-CAN 适配器
+)
+
+var (
+	// communicators 已创建的通信客户端，相同传输方式和地址的设备共享同一个客户端，
+	// 这样 SocketCAN 套接字不会重复打开，同一个 can-bridge 的熔断状态也只有一份
+	communicators      = make(map[string]Communicator)
+	communicatorsMutex sync.Mutex
+)
+
+// NewCommunicatorFromConfig 根据设备配置创建通信客户端
+// 参数 config 是设备配置，使用以下字段：
+//   - transport: 传输方式，可选值为 "can-bridge"、"socketcan"、"sim" 或 "slcan"，默认值为 "can-bridge"
+//   - can_service_url: can-bridge 服务 URL，仅 can-bridge 传输需要；以 "sim://" 开头时使用模拟总线；
+//     多个 URL 用逗号分隔时按顺序组成故障转移组，第一个为首选 bridge
+//   - can_service_urls: 按优先级排列的 can-bridge URL 列表，优先于 can_service_url
+//   - serial_port: slcan 适配器的串口设备，如 "/dev/ttyACM0"，仅 slcan 传输需要
+//   - serial_baud: 串口波特率，默认值为 115200
+//   - can_bitrate: slcan 适配器的 CAN 总线波特率，默认值为 1000000
+//   - can_interface: slcan 适配器对应的 CAN 接口名，默认值为 "can0"
+func NewCommunicatorFromConfig(config map[string]any) (Communicator, error) {
+	transport, _ := config["transport"].(string)
+	if transport == "" {
+		transport = TransportCanBridge
+	}
+
+	serviceURLs := serviceURLsFromConfig(config)
+	if transport == TransportCanBridge && len(serviceURLs) == 1 && IsSimServiceURL(serviceURLs[0]) {
+		transport = TransportSim
+	}
+
+	switch transport {
+	case TransportCanBridge:
+		if len(serviceURLs) == 0 {
+			return nil, fmt.Errorf("缺少 can 服务 URL 配置")
+		}
+		if len(serviceURLs) == 1 {
+			serviceURL := serviceURLs[0]
+			return getOrCreateCommunicator(TransportCanBridge+":"+serviceURL, func() (Communicator, error) {
+				return NewCanBridgeClientWithOptions(serviceURL, canBridgeOptionsFromConfig()), nil
+			})
+		}
+		return getOrCreateCommunicator(TransportCanBridge+":"+strings.Join(serviceURLs, ","), func() (Communicator, error) {
+			failover := NewBridgeFailover(serviceURLs, canBridgeOptionsFromConfig(), healthIntervalFromConfig())
+			failover.Start()
+			return failover, nil
+		})
+	case TransportSocketCAN:
+		return getOrCreateCommunicator(TransportSocketCAN, NewSocketCANClient)
+	case TransportSim:
+		return getOrCreateCommunicator(TransportSim, func() (Communicator, error) {
+			return NewSimulatedClient(DefaultVirtualBus()), nil
+		})
+	case TransportSlcan:
+		serialPort, _ := config["serial_port"].(string)
+		if serialPort == "" {
+			return nil, fmt.Errorf("缺少 slcan 串口配置")
+		}
+		ifName, _ := config["can_interface"].(string)
+		if ifName == "" {
+			ifName = "can0"
+		}
+		return getOrCreateCommunicator(TransportSlcan+":"+serialPort, func() (Communicator, error) {
+			return NewSlcanClient(serialPort, ifName, configInt(config, "can_bitrate"), configInt(config, "serial_baud"))
+		})
+	default:
+		return nil, fmt.Errorf("未知的传输方式: %s", transport)
+	}
+}
+
+// GetCommunicators 返回所有已创建的通信客户端，key 形如 "can-bridge:http://127.0.0.1:5260"
+func GetCommunicators() map[string]Communicator {
+	communicatorsMutex.Lock()
+	defer communicatorsMutex.Unlock()
+	return maps.Clone(communicators)
+}
+
+func getOrCreateCommunicator(key string, create func() (Communicator, error)) (Communicator, error) {
+	communicatorsMutex.Lock()
+	defer communicatorsMutex.Unlock()
+
+	if comm, ok := communicators[key]; ok {
+		return comm, nil
+	}
+
+	comm, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	// 所有发送都经过录制器，便于按接口开启录制
+	comm = NewRecordingCommunicator(comm, DefaultRecorder())
+
+	// 按接口限速，限速器在录制器外层，被取代而未发送的帧不会被录制
+	comm = NewRateLimitedCommunicator(comm, rateLimitConfigFromConfig())
+
+	// 后台轮询接口状态，IsConnected 和 GetAllInterfaceStatuses 返回缓存结果
+	monitor := NewHealthMonitor(key, comm, healthIntervalFromConfig())
+	monitor.Start()
+
+	communicators[key] = monitor
+	return monitor, nil
+}
+
+// Unwrapper 由 Communicator 装饰器实现，返回被包装的 Communicator
+type Unwrapper interface {
+	Unwrap() Communicator
+}
+
+// As 沿装饰器链查找实现了 T 的 Communicator，例如 As[BreakerReporter](comm)
+func As[T any](comm Communicator) (T, bool) {
+	for comm != nil {
+		if target, ok := comm.(T); ok {
+			return target, true
+		}
+		unwrapper, ok := comm.(Unwrapper)
+		if !ok {
+			break
+		}
+		comm = unwrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// serviceURLsFromConfig 读取设备配置中按优先级排列的 can-bridge URL
+func serviceURLsFromConfig(config map[string]any) []string {
+	switch v := config["can_service_urls"].(type) {
+	case []string:
+		return slices.DeleteFunc(slices.Clone(v), func(url string) bool { return url == "" })
+	case []any: // JSON 数组
+		urls := make([]string, 0, len(v))
+		for _, item := range v {
+			if url, ok := item.(string); ok && url != "" {
+				urls = append(urls, url)
+			}
+		}
+		return urls
+	}
+	serviceURL, _ := config["can_service_url"].(string)
+	return ParseServiceURLs(serviceURL)
+}
+
+// configInt 读取设备配置中的整数，兼容 JSON 解码得到的 float64，缺省时返回 0
+func configInt(config map[string]any, key string) int {
+	switch v := config[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// canBridgeOptionsFromConfig 使用全局配置覆盖 can-bridge 客户端的默认重试和熔断参数
+func canBridgeOptionsFromConfig() CanBridgeOptions {
+	opts := CanBridgeOptions{
+		Retry:   DefaultRetryPolicy(),
+		Breaker: DefaultBreakerConfig(),
+	}
+	if config.Config == nil {
+		return opts
+	}
+
+	if config.Config.SendRetries >= 0 {
+		opts.Retry.MaxRetries = config.Config.SendRetries
+	}
+	if config.Config.RetryBaseDelay > 0 {
+		opts.Retry.BaseDelay = config.Config.RetryBaseDelay
+	}
+	if config.Config.BreakerThreshold >= 0 {
+		opts.Breaker.FailureThreshold = config.Config.BreakerThreshold
+	}
+	if config.Config.BreakerOpenTimeout > 0 {
+		opts.Breaker.OpenTimeout = config.Config.BreakerOpenTimeout
+	}
+	return opts
+}
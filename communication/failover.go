@@ -0,0 +1,412 @@
+package communication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	failbackProbes    = 3  // 首选 bridge 连续多少次探测正常后切回
+	maxSwitchoverLogs = 20 // 保留的最近切换记录数
+)
+
+// BridgeSwitchover 一次 can-bridge 切换
+type BridgeSwitchover struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BridgeState 故障转移组中一个 can-bridge 的状态
+type BridgeState struct {
+	URL       string `json:"url"`
+	Healthy   bool   `json:"healthy"`
+	Active    bool   `json:"active"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// FailoverStatus 故障转移组的状态快照
+type FailoverStatus struct {
+	ActiveURL   string             `json:"activeUrl"`
+	Bridges     []BridgeState      `json:"bridges"`
+	Switchovers int                `json:"switchovers"` // 累计切换次数
+	Recent      []BridgeSwitchover `json:"recent"`      // 最近的切换记录，最新的在最后
+}
+
+// FailoverReporter 由支持多个 can-bridge 故障转移的 Communicator 实现
+type FailoverReporter interface {
+	FailoverStatus() FailoverStatus
+}
+
+// failoverBridge 故障转移组中的一个 can-bridge
+type failoverBridge struct {
+	url     string
+	client  Communicator
+	healthy bool
+	streak  int // 连续探测正常的次数
+	lastErr error
+}
+
+// BridgeFailover 按优先级顺序使用多个 can-bridge 的 Communicator
+// 当前 bridge 正常时始终使用它（粘性）；当前 bridge 探测失败或发送时不可达，切换到顺序最靠前的正常 bridge；
+// 更靠前的 bridge 连续 failbackProbes 次探测正常后自动切回。每次切换都会记录日志
+type BridgeFailover struct {
+	bridges     []*failoverBridge
+	active      int
+	switchovers int
+	recent      []BridgeSwitchover
+	opts        CanBridgeOptions
+	interval    time.Duration
+	hub         *subscriberHub
+	forward     Subscription // 当前 bridge 上的接收订阅，存在本地订阅者时才打开
+	stop        chan struct{}
+	stopOnce    sync.Once
+	mutex       sync.RWMutex
+}
+
+// NewBridgeFailover 创建故障转移组，urls 按优先级排列，第一个为首选 bridge；interval 为探测间隔
+func NewBridgeFailover(urls []string, opts CanBridgeOptions, interval time.Duration) *BridgeFailover {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	f := &BridgeFailover{
+		opts:     opts,
+		interval: interval,
+		hub:      newSubscriberHub(),
+		stop:     make(chan struct{}),
+	}
+	for _, url := range urls {
+		f.bridges = append(f.bridges, f.newBridge(url))
+	}
+	f.hub.onIdle = f.stopForward
+	return f
+}
+
+// newBridge 创建一个 bridge，探测之前视为正常，这样启动时直接使用首选 bridge
+func (f *BridgeFailover) newBridge(url string) *failoverBridge {
+	return &failoverBridge{
+		url:     url,
+		client:  NewCanBridgeClientWithOptions(url, f.opts),
+		healthy: true,
+		streak:  failbackProbes,
+	}
+}
+
+// Start 启动后台探测
+func (f *BridgeFailover) Start() {
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+				f.probe()
+			}
+		}
+	}()
+}
+
+// probe 探测所有 bridge，按探测结果故障转移或切回首选 bridge
+func (f *BridgeFailover) probe() {
+	f.mutex.RLock()
+	bridges := slices.Clone(f.bridges)
+	f.mutex.RUnlock()
+
+	errs := make([]error, len(bridges))
+	var wg sync.WaitGroup
+	for i, bridge := range bridges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = bridge.client.GetAllInterfaceStatuses()
+		}()
+	}
+	wg.Wait()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i, bridge := range bridges {
+		if errs[i] != nil {
+			bridge.healthy = false
+			bridge.streak = 0
+			bridge.lastErr = errs[i]
+		} else {
+			bridge.healthy = true
+			bridge.streak++
+			bridge.lastErr = nil
+		}
+	}
+
+	current := f.bridges[f.active]
+	if !current.healthy {
+		if next := f.firstHealthy(); next >= 0 {
+			f.switchTo(next, fmt.Sprintf("当前 bridge 探测失败：%v", current.lastErr))
+		}
+		return
+	}
+	for i := range f.active {
+		if f.bridges[i].healthy && f.bridges[i].streak >= failbackProbes {
+			f.switchTo(i, "更高优先级的 bridge 已恢复")
+			return
+		}
+	}
+}
+
+// firstHealthy 返回顺序最靠前的正常 bridge，没有时返回 -1，调用方需持有 f.mutex
+func (f *BridgeFailover) firstHealthy() int {
+	for i, bridge := range f.bridges {
+		if bridge.healthy {
+			return i
+		}
+	}
+	return -1
+}
+
+// switchTo 切换当前 bridge 并迁移接收订阅，调用方需持有 f.mutex
+func (f *BridgeFailover) switchTo(index int, reason string) {
+	if index == f.active {
+		return
+	}
+
+	event := BridgeSwitchover{
+		From:      f.bridges[f.active].url,
+		To:        f.bridges[index].url,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+	log.Printf("🔀 can-bridge 切换: %s -> %s (%s)", event.From, event.To, event.Reason)
+
+	f.active = index
+	f.switchovers++
+	f.recent = append(f.recent, event)
+	if len(f.recent) > maxSwitchoverLogs {
+		f.recent = slices.Delete(f.recent, 0, len(f.recent)-maxSwitchoverLogs)
+	}
+
+	if f.forward != nil {
+		f.closeForwardLocked()
+		f.startForwardLocked()
+	}
+}
+
+// markFailed 记录当前 bridge 在发送或查询时不可达并立即故障转移，返回是否切换到了另一个 bridge
+func (f *BridgeFailover) markFailed(client Communicator, err error) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	current := f.bridges[f.active]
+	if current.client != client {
+		// 其它请求已经完成了切换
+		return true
+	}
+	current.healthy = false
+	current.streak = 0
+	current.lastErr = err
+
+	next := f.firstHealthy()
+	if next < 0 {
+		return false
+	}
+	f.switchTo(next, fmt.Sprintf("当前 bridge 不可达：%v", err))
+	return true
+}
+
+// current 返回当前 bridge 的客户端
+func (f *BridgeFailover) current() Communicator {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.bridges[f.active].client
+}
+
+// isBridgeUnavailable 判断发送错误是否说明 bridge 本身不可达，而不是请求被拒绝或被调用方取消
+func isBridgeUnavailable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	return errors.Is(err, ErrCircuitOpen) || isRetryableBridgeError(err)
+}
+
+// SendMessage 通过当前 bridge 发送；bridge 不可达时故障转移，幂等帧在新 bridge 上再发送一次
+func (f *BridgeFailover) SendMessage(ctx context.Context, msg RawMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	return f.withFailover(msg.Idempotent, func(client Communicator) error {
+		return client.SendMessage(ctx, msg)
+	})
+}
+
+// SendBatch 通过当前 bridge 批量发送；全部为幂等帧时才在新 bridge 上重发
+func (f *BridgeFailover) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	idempotent := true
+	for i, frame := range frames {
+		if err := frame.Message.Validate(); err != nil {
+			return fmt.Errorf("批量发送第 %d/%d 帧无效：%w", i+1, len(frames), err)
+		}
+		idempotent = idempotent && frame.Message.Idempotent
+	}
+	return f.withFailover(idempotent, func(client Communicator) error {
+		return client.SendBatch(ctx, frames)
+	})
+}
+
+// withFailover 在当前 bridge 上执行 call，bridge 不可达时故障转移，retry 为 true 时在新 bridge 上再执行一次
+func (f *BridgeFailover) withFailover(retry bool, call func(client Communicator) error) error {
+	client := f.current()
+	err := call(client)
+	if err == nil || !isBridgeUnavailable(err) {
+		return err
+	}
+	if !f.markFailed(client, err) || !retry {
+		return err
+	}
+	return call(f.current())
+}
+
+// GetAllInterfaceStatuses 查询当前 bridge 的接口状态，不可达时故障转移后再查询一次
+func (f *BridgeFailover) GetAllInterfaceStatuses() (map[string]bool, error) {
+	client := f.current()
+	statuses, err := client.GetAllInterfaceStatuses()
+	if err == nil || !f.markFailed(client, err) {
+		return statuses, err
+	}
+	return f.current().GetAllInterfaceStatuses()
+}
+
+// SetServiceURL 将 url 设为首选 bridge（不在组中时加入组的最前面），并立即切换到它
+func (f *BridgeFailover) SetServiceURL(url string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	activeBridge := f.bridges[f.active]
+	index := slices.IndexFunc(f.bridges, func(b *failoverBridge) bool { return b.url == url })
+	var bridge *failoverBridge
+	if index >= 0 {
+		bridge = f.bridges[index]
+		f.bridges = slices.Delete(f.bridges, index, index+1)
+	} else {
+		bridge = f.newBridge(url)
+	}
+	f.bridges = slices.Insert(f.bridges, 0, bridge)
+	f.active = slices.Index(f.bridges, activeBridge)
+	f.switchTo(0, "手动设置首选 bridge")
+}
+
+// IsConnected 检查当前 bridge 是否可达
+func (f *BridgeFailover) IsConnected() bool {
+	_, err := f.GetAllInterfaceStatuses()
+	return err == nil
+}
+
+// Subscribe 订阅当前 bridge 转发的 CAN 帧，切换 bridge 时订阅自动迁移
+// 注册订阅在 f.mutex 下进行，与 stopForward 互斥
+func (f *BridgeFailover) Subscribe(filter FrameFilter) (Subscription, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sub := f.hub.add(filter)
+	if f.forward == nil {
+		f.startForwardLocked()
+	}
+	return sub, nil
+}
+
+// startForwardLocked 在当前 bridge 上打开接收订阅并转发给本地订阅者，调用方需持有 f.mutex
+func (f *BridgeFailover) startForwardLocked() {
+	bridge := f.bridges[f.active]
+	sub, err := bridge.client.Subscribe(FrameFilter{})
+	if err != nil {
+		log.Printf("⚠️ 订阅 can-bridge %s 失败: %v", bridge.url, err)
+		return
+	}
+	f.forward = sub
+	go func() {
+		for msg := range sub.Frames() {
+			f.hub.dispatch(msg)
+		}
+	}()
+}
+
+// stopForward 最后一个本地订阅者退出时关闭 bridge 上的接收订阅；期间又有新的订阅者时保持转发
+func (f *BridgeFailover) stopForward() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.hub.count() == 0 {
+		f.closeForwardLocked()
+	}
+}
+
+// closeForwardLocked 关闭 bridge 上的接收订阅，调用方需持有 f.mutex
+func (f *BridgeFailover) closeForwardLocked() {
+	if f.forward != nil {
+		f.forward.Close()
+		f.forward = nil
+	}
+}
+
+// SupportsFD can-bridge 透传 fd/brs 标志
+func (f *BridgeFailover) SupportsFD() bool { return true }
+
+// CanReceive 判断当前 bridge 是否能接收帧
+func (f *BridgeFailover) CanReceive() bool { return CanReceive(f.current()) }
+
+// BreakerStatus 返回当前 bridge 的熔断器状态
+func (f *BridgeFailover) BreakerStatus() BreakerStatus {
+	if reporter, ok := f.current().(BreakerReporter); ok {
+		return reporter.BreakerStatus()
+	}
+	return BreakerStatus{}
+}
+
+// FailoverStatus 返回各 bridge 的状态和最近的切换记录
+func (f *BridgeFailover) FailoverStatus() FailoverStatus {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	status := FailoverStatus{
+		ActiveURL:   f.bridges[f.active].url,
+		Bridges:     make([]BridgeState, 0, len(f.bridges)),
+		Switchovers: f.switchovers,
+		Recent:      slices.Clone(f.recent),
+	}
+	for i, bridge := range f.bridges {
+		state := BridgeState{URL: bridge.url, Healthy: bridge.healthy, Active: i == f.active}
+		if bridge.lastErr != nil {
+			state.LastError = bridge.lastErr.Error()
+		}
+		status.Bridges = append(status.Bridges, state)
+	}
+	return status
+}
+
+// Close 停止后台探测并关闭所有订阅
+func (f *BridgeFailover) Close() error {
+	f.stopOnce.Do(func() { close(f.stop) })
+	f.mutex.Lock()
+	f.closeForwardLocked()
+	f.mutex.Unlock()
+	f.hub.closeAll()
+	return nil
+}
+
+// ParseServiceURLs 将逗号分隔的 can-bridge 地址列表拆分为按优先级排列的 URL
+func ParseServiceURLs(value string) []string {
+	var urls []string
+	for url := range strings.SplitSeq(value, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,62 @@
+package communication
+
+import (
+	"sync"
+	"testing"
+)
+
+// forwarding 返回 bridge 上的接收订阅是否打开
+func forwarding(f *BridgeFailover) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.forward != nil
+}
+
+func TestFailoverSubscribeWhileLastSubscriberLeaves(t *testing.T) {
+	f := NewBridgeFailover([]string{blockingStreamServer(t).URL}, CanBridgeOptions{}, 0)
+	defer f.Close()
+
+	for range 200 {
+		old, _ := f.Subscribe(FrameFilter{})
+
+		var next Subscription
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			old.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			next, _ = f.Subscribe(FrameFilter{})
+		}()
+		wg.Wait()
+
+		if !forwarding(f) {
+			t.Fatal("仍有订阅者时停止了转发")
+		}
+		next.Close()
+		if forwarding(f) {
+			t.Fatal("最后一个订阅者退出后仍在转发")
+		}
+	}
+}
+
+// TestFailoverLateIdleKeepsForward 最后一个订阅者已从 hub 移除、尚未调用 onIdle 时新订阅者加入
+func TestFailoverLateIdleKeepsForward(t *testing.T) {
+	f := NewBridgeFailover([]string{blockingStreamServer(t).URL}, CanBridgeOptions{}, 0)
+	defer f.Close()
+
+	old, _ := f.Subscribe(FrameFilter{})
+	f.hub.mutex.Lock()
+	delete(f.hub.subscribers, old.(*frameSubscription))
+	f.hub.mutex.Unlock()
+
+	next, _ := f.Subscribe(FrameFilter{})
+	defer next.Close()
+	f.hub.onIdle()
+
+	if !forwarding(f) {
+		t.Fatal("仍有订阅者时停止了转发")
+	}
+}
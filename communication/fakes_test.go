@@ -0,0 +1,28 @@
+package communication
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// messageRecorder 按发送顺序记录完整帧的 Communicator，供需要检查接口、ID 等字段的测试使用
+type messageRecorder struct {
+	Communicator
+	sent  []RawMessage
+	mutex sync.Mutex
+}
+
+func (r *messageRecorder) SendMessage(_ context.Context, msg RawMessage) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	msg.Data = slices.Clone(msg.Data)
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func (r *messageRecorder) sentMessages() []RawMessage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.sent)
+}
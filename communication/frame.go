@@ -0,0 +1,83 @@
+package communication
+
+import "fmt"
+
+const (
+	CANMaxDataLen   = 8  // 经典 CAN 帧最大数据长度
+	CANFDMaxDataLen = 64 // CAN FD 帧最大数据长度
+)
+
+// canFDLengths CAN FD 帧允许的数据长度，DLC 9~15 依次对应 12、16、20、24、32、48、64 字节
+var canFDLengths = [...]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 12, 16, 20, 24, 32, 48, 64}
+
+// FDCapable 由能够发送 CAN FD 帧的 Communicator 实现
+type FDCapable interface {
+	SupportsFD() bool
+}
+
+// SupportsFD 判断通信客户端（沿装饰器链查找）是否能发送 CAN FD 帧
+func SupportsFD(comm Communicator) bool {
+	fd, ok := As[FDCapable](comm)
+	return ok && fd.SupportsFD()
+}
+
+// IsValidFDLength 判断 n 是否是 CAN FD 帧合法的数据长度
+func IsValidFDLength(n int) bool {
+	_, ok := fdLengthToDLC(n)
+	return ok
+}
+
+// FDPaddedLength 返回不小于 n 的最小合法 CAN FD 数据长度，n 超过 64 时返回 -1
+func FDPaddedLength(n int) int {
+	for _, length := range canFDLengths {
+		if length >= n {
+			return length
+		}
+	}
+	return -1
+}
+
+func fdLengthToDLC(n int) (byte, bool) {
+	for dlc, length := range canFDLengths {
+		if length == n {
+			return byte(dlc), true
+		}
+	}
+	return 0, false
+}
+
+// DLC 返回帧的数据长度码，经典帧为数据长度，FD 帧按长度表换算；长度无效时返回 0
+func (m RawMessage) DLC() byte {
+	if !m.FD {
+		return byte(min(len(m.Data), CANMaxDataLen))
+	}
+	dlc, _ := fdLengthToDLC(len(m.Data))
+	return dlc
+}
+
+// IsExtended 判断帧是否为扩展帧：显式标记为扩展帧，或 ID 超出标准帧范围
+func (m RawMessage) IsExtended() bool {
+	return m.Extended || m.ID > canStandardIDMax
+}
+
+// Validate 校验帧类型标志和数据长度：经典帧最多 8 字节且不能带 BRS，
+// FD 帧最多 64 字节且长度必须是 DLC 可以表示的值
+func (m RawMessage) Validate() error {
+	if !m.FD {
+		if m.BRS {
+			return fmt.Errorf("经典 CAN 帧不能设置 BRS 标志")
+		}
+		if len(m.Data) > CANMaxDataLen {
+			return fmt.Errorf("CAN 帧数据长度 %d 超过 %d 字节", len(m.Data), CANMaxDataLen)
+		}
+		return nil
+	}
+
+	if len(m.Data) > CANFDMaxDataLen {
+		return fmt.Errorf("CAN FD 帧数据长度 %d 超过 %d 字节", len(m.Data), CANFDMaxDataLen)
+	}
+	if !IsValidFDLength(len(m.Data)) {
+		return fmt.Errorf("CAN FD 帧数据长度 %d 无效，8 字节以上只能是 12/16/20/24/32/48/64", len(m.Data))
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package communication
+
+import (
+	"hands/config"
+	"log"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultHealthInterval 未配置时健康检查的轮询间隔
+const defaultHealthInterval = 2 * time.Second
+
+// 健康事件类型
+const (
+	HealthEventService   = "service"   // 通信服务（如 can-bridge）可达性变化
+	HealthEventInterface = "interface" // CAN 接口可用性变化
+)
+
+// HealthEvent 一次健康状态变化
+type HealthEvent struct {
+	Type         string    `json:"type"`                // HealthEventService 或 HealthEventInterface
+	Communicator string    `json:"communicator"`        // 通信客户端标识，与 GetCommunicators 的 key 相同
+	Interface    string    `json:"interface,omitempty"` // 接口名，仅接口事件
+	Up           bool      `json:"up"`                  // 变化后的状态
+	Error        string    `json:"error,omitempty"`     // 服务不可达时的错误
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// HealthSnapshot 健康监视器缓存的最近一次检查结果
+type HealthSnapshot struct {
+	Reachable  bool            `json:"reachable"`
+	Interfaces map[string]bool `json:"interfaces"`
+	LastCheck  time.Time       `json:"lastCheck,omitzero"`
+	LastError  string          `json:"lastError,omitempty"`
+	Interval   time.Duration   `json:"interval"`
+}
+
+// HealthMonitor 在后台按固定间隔轮询 GetAllInterfaceStatuses 的 Communicator 装饰器
+// IsConnected 和 GetAllInterfaceStatuses 直接返回缓存结果，不再每次发起阻塞的状态请求；
+// 状态变化通过 SubscribeHealthEvents 发布
+type HealthMonitor struct {
+	Communicator
+	name      string
+	interval  time.Duration
+	checked   bool            // 是否完成过至少一次检查
+	reachable bool            // 最近一次检查时服务是否可达
+	statuses  map[string]bool // 最近一次检查得到的接口状态
+	lastCheck time.Time
+	lastError error
+	stop      chan struct{}
+	stopOnce  sync.Once
+	checkMu   sync.Mutex // 保证同一时间只有一次检查
+	mutex     sync.RWMutex
+}
+
+// NewHealthMonitor 创建健康监视器，name 用于标识事件来源，interval <= 0 时使用默认间隔
+func NewHealthMonitor(name string, comm Communicator, interval time.Duration) *HealthMonitor {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	return &HealthMonitor{
+		Communicator: comm,
+		name:         name,
+		interval:     interval,
+		statuses:     make(map[string]bool),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start 启动后台轮询，立即执行第一次检查
+func (m *HealthMonitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		m.check()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.check()
+			}
+		}
+	}()
+}
+
+// Stop 停止后台轮询
+func (m *HealthMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stop) })
+}
+
+// Name 返回监视器标识
+func (m *HealthMonitor) Name() string { return m.name }
+
+// check 查询一次接口状态，更新缓存并发布状态变化
+func (m *HealthMonitor) check() {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	statuses, err := m.Communicator.GetAllInterfaceStatuses()
+	now := time.Now()
+
+	m.mutex.Lock()
+	var events []HealthEvent
+	reachable := err == nil
+
+	if !m.checked || reachable != m.reachable {
+		event := HealthEvent{Type: HealthEventService, Communicator: m.name, Up: reachable, Timestamp: now}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		events = append(events, event)
+	}
+
+	// 服务不可达时所有已知接口都视为不可用
+	next := make(map[string]bool, len(m.statuses))
+	if reachable {
+		maps.Copy(next, statuses)
+	} else {
+		for ifName := range m.statuses {
+			next[ifName] = false
+		}
+	}
+	for ifName, up := range next {
+		if prev, ok := m.statuses[ifName]; !m.checked || !ok || prev != up {
+			events = append(events, HealthEvent{Type: HealthEventInterface, Communicator: m.name, Interface: ifName, Up: up, Timestamp: now})
+		}
+	}
+
+	m.checked = true
+	m.reachable = reachable
+	m.statuses = next
+	m.lastCheck = now
+	m.lastError = err
+	m.mutex.Unlock()
+
+	for _, event := range events {
+		logHealthEvent(event)
+		healthEvents.publish(event)
+	}
+}
+
+func logHealthEvent(event HealthEvent) {
+	switch {
+	case event.Type == HealthEventService && event.Up:
+		log.Printf("💚 通信服务 %s 可用", event.Communicator)
+	case event.Type == HealthEventService:
+		log.Printf("💔 通信服务 %s 不可用: %s", event.Communicator, event.Error)
+	case event.Up:
+		log.Printf("🟢 %s 接口 %s 已启用", event.Communicator, event.Interface)
+	default:
+		log.Printf("🔴 %s 接口 %s 已停用", event.Communicator, event.Interface)
+	}
+}
+
+// ensureChecked 在第一次后台检查完成前同步执行一次检查
+func (m *HealthMonitor) ensureChecked() {
+	m.mutex.RLock()
+	checked := m.checked
+	m.mutex.RUnlock()
+	if !checked {
+		m.check()
+	}
+}
+
+// GetAllInterfaceStatuses 返回缓存的接口状态，服务不可达时返回最近一次的错误
+func (m *HealthMonitor) GetAllInterfaceStatuses() (map[string]bool, error) {
+	m.ensureChecked()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.lastError != nil {
+		return nil, m.lastError
+	}
+	return maps.Clone(m.statuses), nil
+}
+
+// IsConnected 返回缓存的服务可达状态
+func (m *HealthMonitor) IsConnected() bool {
+	m.ensureChecked()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.reachable
+}
+
+// InterfaceStatus 返回缓存的接口状态，known 为 false 表示还没有检查结果
+func (m *HealthMonitor) InterfaceStatus(ifName string) (up bool, known bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if !m.checked {
+		return false, false
+	}
+	up, known = m.statuses[ifName]
+	return up, known || !m.reachable
+}
+
+// Snapshot 返回缓存的检查结果
+func (m *HealthMonitor) Snapshot() HealthSnapshot {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	snapshot := HealthSnapshot{
+		Reachable:  m.reachable,
+		Interfaces: maps.Clone(m.statuses),
+		LastCheck:  m.lastCheck,
+		Interval:   m.interval,
+	}
+	if m.lastError != nil {
+		snapshot.LastError = m.lastError.Error()
+	}
+	return snapshot
+}
+
+// Unwrap 返回被包装的 Communicator
+func (m *HealthMonitor) Unwrap() Communicator { return m.Communicator }
+
+// healthIntervalFromConfig 返回全局配置的健康检查间隔
+func healthIntervalFromConfig() time.Duration {
+	if config.Config == nil {
+		return defaultHealthInterval
+	}
+	return config.Config.HealthInterval
+}
+
+// HealthSubscription 健康事件订阅
+type HealthSubscription struct {
+	events  chan HealthEvent
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Events 返回事件通道，订阅关闭后通道被关闭
+func (s *HealthSubscription) Events() <-chan HealthEvent { return s.events }
+
+// Dropped 返回因消费过慢而被丢弃的事件数量
+func (s *HealthSubscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Close 取消订阅
+func (s *HealthSubscription) Close() { s.once.Do(func() { healthEvents.remove(s) }) }
+
+// healthEventHub 向所有订阅者分发健康事件
+type healthEventHub struct {
+	subscribers map[*HealthSubscription]struct{}
+	mutex       sync.RWMutex
+}
+
+// healthEvents 所有健康监视器共享的事件分发器
+var healthEvents = &healthEventHub{subscribers: make(map[*HealthSubscription]struct{})}
+
+// SubscribeHealthEvents 订阅所有通信客户端的健康状态变化
+func SubscribeHealthEvents() *HealthSubscription {
+	healthEvents.mutex.Lock()
+	defer healthEvents.mutex.Unlock()
+
+	sub := &HealthSubscription{events: make(chan HealthEvent, defaultSubscriptionBuffer)}
+	healthEvents.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (h *healthEventHub) remove(sub *HealthSubscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// publish 分发事件，消费过慢的订阅者会丢事件而不会阻塞监视器
+func (h *healthEventHub) publish(event HealthEvent) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// GetHealthSnapshots 返回所有通信客户端的健康状态，key 与 GetCommunicators 相同
+func GetHealthSnapshots() map[string]HealthSnapshot {
+	result := make(map[string]HealthSnapshot)
+	for name, comm := range GetCommunicators() {
+		if monitor, ok := As[*HealthMonitor](comm); ok {
+			result[name] = monitor.Snapshot()
+		}
+	}
+	return result
+}
@@ -0,0 +1,401 @@
+package communication
+
+import (
+	"context"
+	"fmt"
+	"hands/config"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimit   = 1000      // 未配置时每个接口每秒最多发送的帧数
+	defaultRateBurst   = 100       // 未配置时令牌桶容量
+	defaultBitrate     = 1_000_000 // 未配置时的仲裁段波特率
+	defaultDataBitrate = 5_000_000 // 未配置时 CAN FD 数据段波特率
+	busStatsWindow     = time.Second
+)
+
+// RateLimitConfig 发送限速配置
+type RateLimitConfig struct {
+	Rate        float64 // 每个接口每秒最多发送的帧数，<= 0 表示不限速
+	Burst       int     // 令牌桶容量，允许的突发帧数
+	Bitrate     int     // 总线仲裁段波特率，用于估算总线负载
+	DataBitrate int     // CAN FD 数据段波特率，BRS 帧的数据段按此估算
+}
+
+// DefaultRateLimitConfig 返回默认的限速配置
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Rate:        defaultRateLimit,
+		Burst:       defaultRateBurst,
+		Bitrate:     defaultBitrate,
+		DataBitrate: defaultDataBitrate,
+	}
+}
+
+// InterfaceStats 一个接口的发送统计
+type InterfaceStats struct {
+	TotalFrames  uint64  `json:"totalFrames"`  // 已发送的帧数
+	FramesPerSec float64 `json:"framesPerSec"` // 最近 1 秒发送的帧数
+	BitsPerSec   float64 `json:"bitsPerSec"`   // 最近 1 秒发送的位数（按仲裁段波特率折算）
+	Utilisation  float64 `json:"utilisation"`  // 估算的总线负载 (0~1)
+	Pending      int     `json:"pending"`      // 正在等待令牌的帧数
+	Throttled    uint64  `json:"throttled"`    // 因限速而等待过的帧数
+	Coalesced    uint64  `json:"coalesced"`    // 等待期间被同一 CAN ID 的新帧取代而丢弃的帧数
+	RateLimit    float64 `json:"rateLimit"`    // 每秒最多发送的帧数，0 表示不限速
+	Burst        int     `json:"burst"`
+	Bitrate      int     `json:"bitrate"`
+}
+
+// StatsReporter 由能提供按接口发送统计的 Communicator 实现
+type StatsReporter interface {
+	InterfaceStats() map[string]InterfaceStats
+}
+
+// coalesceKey 同一接口、同一 CAN ID、同一指令前缀的帧可以互相取代
+type coalesceKey struct {
+	id     uint32
+	prefix byte
+}
+
+// rateWaiter 一个正在等待令牌的帧
+type rateWaiter struct {
+	key        coalesceKey
+	coalesce   bool          // 是否可以被同一 key 的新帧取代
+	superseded chan struct{} // 被取代时关闭
+}
+
+// sentFrame 统计窗口中的一帧
+type sentFrame struct {
+	at   time.Time
+	bits int
+}
+
+// interfaceLimiter 单个接口的令牌桶和统计
+type interfaceLimiter struct {
+	tokens    float64
+	last      time.Time
+	queue     []*rateWaiter               // 按到达顺序等待令牌的帧，只有队首可以取令牌
+	pending   map[coalesceKey]*rateWaiter // 队列中可被取代的帧
+	window    []sentFrame                 // 最近 busStatsWindow 内发送的帧
+	total     uint64
+	throttled uint64
+	coalesced uint64
+}
+
+// RateLimitedCommunicator 按接口限制发送速率的 Communicator 装饰器
+// 每个接口一个令牌桶，等待令牌的帧按到达顺序发送；等待令牌期间，标记为 Coalesce 的帧会被同接口、同 CAN ID、
+// 同前缀的新帧取代，新帧沿用旧帧的排队位置，被取代的帧不再发送，其 SendMessage 返回 nil
+type RateLimitedCommunicator struct {
+	Communicator
+	config   RateLimitConfig
+	limiters map[string]*interfaceLimiter
+	mutex    sync.Mutex
+}
+
+// NewRateLimitedCommunicator 用限速器包装一个 Communicator
+func NewRateLimitedCommunicator(comm Communicator, cfg RateLimitConfig) *RateLimitedCommunicator {
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.Bitrate <= 0 {
+		cfg.Bitrate = defaultBitrate
+	}
+	if cfg.DataBitrate <= 0 {
+		cfg.DataBitrate = cfg.Bitrate
+	}
+	return &RateLimitedCommunicator{
+		Communicator: comm,
+		config:       cfg,
+		limiters:     make(map[string]*interfaceLimiter),
+	}
+}
+
+// limiter 获取（必要时创建）接口的令牌桶，调用方需持有 c.mutex
+func (c *RateLimitedCommunicator) limiter(ifName string) *interfaceLimiter {
+	l, ok := c.limiters[ifName]
+	if !ok {
+		l = &interfaceLimiter{
+			tokens:  float64(c.config.Burst),
+			last:    time.Now(),
+			pending: make(map[coalesceKey]*rateWaiter),
+		}
+		c.limiters[ifName] = l
+	}
+	return l
+}
+
+// refill 按经过的时间补充令牌，调用方需持有 c.mutex
+func (c *RateLimitedCommunicator) refill(l *interfaceLimiter, now time.Time) {
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*c.config.Rate, float64(c.config.Burst))
+	l.last = now
+}
+
+// acquire 等待接口上的一个令牌；send 为 false 表示帧在等待期间被取代，不需要再发送，waited 表示帧曾排队等待
+// 只有没有帧在等待时才直接取令牌，否则排在等待的帧后面，避免新帧抢在同一 CAN ID 的旧帧之前发送
+func (c *RateLimitedCommunicator) acquire(ctx context.Context, msg RawMessage) (send, waited bool, err error) {
+	if c.config.Rate <= 0 {
+		return true, false, nil
+	}
+
+	c.mutex.Lock()
+	l := c.limiter(msg.Interface)
+	c.refill(l, time.Now())
+	if len(l.queue) == 0 && l.tokens >= 1 {
+		l.tokens--
+		c.mutex.Unlock()
+		return true, false, nil
+	}
+
+	// 需要等待：可取代的帧取代同一 key 上等待的旧帧并沿用它的位置，否则排到队尾
+	waiter := &rateWaiter{superseded: make(chan struct{})}
+	if msg.Coalesce && len(msg.Data) > 0 {
+		waiter.key = coalesceKey{id: msg.ID, prefix: msg.Data[0]}
+		waiter.coalesce = true
+	}
+	if previous, ok := l.pending[waiter.key]; ok && waiter.coalesce {
+		l.queue[slices.Index(l.queue, previous)] = waiter
+		close(previous.superseded)
+	} else {
+		l.queue = append(l.queue, waiter)
+	}
+	if waiter.coalesce {
+		l.pending[waiter.key] = waiter
+	}
+	l.throttled++
+	c.mutex.Unlock()
+
+	for {
+		c.mutex.Lock()
+		select {
+		case <-waiter.superseded:
+			l.coalesced++
+			c.mutex.Unlock()
+			return false, true, nil
+		default:
+		}
+
+		c.refill(l, time.Now())
+		position := slices.Index(l.queue, waiter)
+		if position == 0 && l.tokens >= 1 {
+			l.tokens--
+			l.removeWaiter(waiter)
+			c.mutex.Unlock()
+			return true, true, nil
+		}
+		// 前面还有 position 帧，第 position+1 个令牌到达时轮到本帧
+		wait := time.Duration((float64(position+1) - l.tokens) / c.config.Rate * float64(time.Second))
+		wait = max(wait, time.Millisecond)
+		c.mutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.mutex.Lock()
+			l.removeWaiter(waiter)
+			c.mutex.Unlock()
+			return false, true, fmt.Errorf("等待接口 %s 发送令牌被取消：%w", msg.Interface, ctx.Err())
+		case <-waiter.superseded:
+			timer.Stop()
+			c.mutex.Lock()
+			l.coalesced++
+			c.mutex.Unlock()
+			return false, true, nil
+		case <-timer.C:
+		}
+	}
+}
+
+// release 归还一帧已取得的令牌并撤销其限速计数，用于帧最终没有发送的情况
+func (c *RateLimitedCommunicator) release(msg RawMessage, acquired, waited bool) {
+	if c.config.Rate <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	l := c.limiter(msg.Interface)
+	if acquired {
+		c.refill(l, time.Now())
+		l.tokens = min(l.tokens+1, float64(c.config.Burst))
+	}
+	if waited {
+		l.throttled--
+	}
+}
+
+// removeWaiter 将帧移出等待队列，调用方需持有 c.mutex
+func (l *interfaceLimiter) removeWaiter(waiter *rateWaiter) {
+	if i := slices.Index(l.queue, waiter); i >= 0 {
+		l.queue = slices.Delete(l.queue, i, i+1)
+	}
+	if waiter.coalesce && l.pending[waiter.key] == waiter {
+		delete(l.pending, waiter.key)
+	}
+}
+
+// account 记录一帧已发送，用于计算帧率和总线负载
+func (c *RateLimitedCommunicator) account(msg RawMessage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	l := c.limiter(msg.Interface)
+	now := time.Now()
+	l.total++
+	l.window = append(l.window, sentFrame{at: now, bits: c.frameBits(msg)})
+	pruneWindow(l, now)
+}
+
+// pruneWindow 移除统计窗口以外的帧，调用方需持有 c.mutex
+func pruneWindow(l *interfaceLimiter, now time.Time) {
+	cut := 0
+	for cut < len(l.window) && now.Sub(l.window[cut].at) > busStatsWindow {
+		cut++
+	}
+	if cut > 0 {
+		l.window = append(l.window[:0], l.window[cut:]...)
+	}
+}
+
+// frameBits 估算一帧在总线上占用的位时间（按仲裁段波特率折算），包含最坏情况的位填充
+func (c *RateLimitedCommunicator) frameBits(msg RawMessage) int {
+	n := len(msg.Data)
+	extended := msg.IsExtended()
+
+	if !msg.FD {
+		// 经典帧：SOF 到 CRC 的位需要位填充，另加 CRC 界定符、ACK、EOF 和帧间隔 13 位
+		stuffed := 34 + 8*n
+		if extended {
+			stuffed = 54 + 8*n
+		}
+		return stuffed + (stuffed-1)/4 + 13
+	}
+
+	// FD 帧：仲裁段约 29/49 位，数据段包含控制位、数据和 CRC (17 或 21 位)
+	arbitration := 29
+	if extended {
+		arbitration = 49
+	}
+	crc := 17
+	if n > 16 {
+		crc = 21
+	}
+	dataPhase := 8*n + crc + 10
+	dataPhase += dataPhase / 4 // 位填充
+	if msg.BRS {
+		dataPhase = dataPhase * c.config.Bitrate / c.config.DataBitrate
+	}
+	return arbitration + dataPhase + 13
+}
+
+func (c *RateLimitedCommunicator) SendMessage(ctx context.Context, msg RawMessage) error {
+	send, _, err := c.acquire(ctx, msg)
+	if err != nil || !send {
+		return err
+	}
+	if err := c.Communicator.SendMessage(ctx, msg); err != nil {
+		return err
+	}
+	c.account(msg)
+	return nil
+}
+
+// SendBatch 批量中的每一帧都要占用令牌，批量帧不参与合并
+// 中途等待令牌失败时整批不发送，已取得的令牌和限速计数都会归还
+func (c *RateLimitedCommunicator) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	waited := make([]bool, 0, len(frames))
+	for _, frame := range frames {
+		msg := frame.Message
+		msg.Coalesce = false
+		_, w, err := c.acquire(ctx, msg)
+		if err != nil {
+			c.release(msg, false, w)
+			for i, ok := range waited {
+				c.release(frames[i].Message, true, ok)
+			}
+			return err
+		}
+		waited = append(waited, w)
+	}
+	if err := c.Communicator.SendBatch(ctx, frames); err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		c.account(frame.Message)
+	}
+	return nil
+}
+
+// InterfaceStats 返回各接口的发送统计
+func (c *RateLimitedCommunicator) InterfaceStats() map[string]InterfaceStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	result := make(map[string]InterfaceStats, len(c.limiters))
+	for ifName, l := range c.limiters {
+		pruneWindow(l, now)
+		bits := 0
+		for _, frame := range l.window {
+			bits += frame.bits
+		}
+		seconds := busStatsWindow.Seconds()
+
+		stats := InterfaceStats{
+			TotalFrames:  l.total,
+			FramesPerSec: float64(len(l.window)) / seconds,
+			BitsPerSec:   float64(bits) / seconds,
+			Pending:      len(l.queue),
+			Throttled:    l.throttled,
+			Coalesced:    l.coalesced,
+			RateLimit:    max(c.config.Rate, 0),
+			Burst:        c.config.Burst,
+			Bitrate:      c.config.Bitrate,
+		}
+		stats.Utilisation = min(stats.BitsPerSec/float64(c.config.Bitrate), 1)
+		result[ifName] = stats
+	}
+	return result
+}
+
+// Unwrap 返回被包装的 Communicator
+func (c *RateLimitedCommunicator) Unwrap() Communicator { return c.Communicator }
+
+// rateLimitConfigFromConfig 使用全局配置覆盖默认的限速参数
+func rateLimitConfigFromConfig() RateLimitConfig {
+	cfg := DefaultRateLimitConfig()
+	if config.Config == nil {
+		return cfg
+	}
+
+	cfg.Rate = config.Config.RateLimit
+	if config.Config.RateBurst > 0 {
+		cfg.Burst = config.Config.RateBurst
+	}
+	if config.Config.Bitrate > 0 {
+		cfg.Bitrate = config.Config.Bitrate
+	}
+	if config.Config.DataBitrate > 0 {
+		cfg.DataBitrate = config.Config.DataBitrate
+	}
+	if cfg.Rate <= 0 {
+		log.Printf("⚠️ 未开启 CAN 发送限速")
+	}
+	return cfg
+}
+
+// GetInterfaceStats 返回所有通信客户端按接口的发送统计，key 与 GetCommunicators 相同
+func GetInterfaceStats() map[string]map[string]InterfaceStats {
+	result := make(map[string]map[string]InterfaceStats)
+	for name, comm := range GetCommunicators() {
+		if reporter, ok := As[StatsReporter](comm); ok {
+			result[name] = maps.Clone(reporter.InterfaceStats())
+		}
+	}
+	return result
+}
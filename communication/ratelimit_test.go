@@ -0,0 +1,131 @@
+package communication
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingCommunicator 记录发送顺序的 Communicator
+type recordingCommunicator struct {
+	Communicator
+	sent  [][]byte
+	mutex sync.Mutex
+}
+
+func (r *recordingCommunicator) SendMessage(_ context.Context, msg RawMessage) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sent = append(r.sent, slices.Clone(msg.Data))
+	return nil
+}
+
+func (r *recordingCommunicator) sentData() [][]byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.sent)
+}
+
+// sendAsync 在后台发送一帧，并等待它登记到令牌等待队列，保证到达顺序
+func sendAsync(t *testing.T, c *RateLimitedCommunicator, wg *sync.WaitGroup, msg RawMessage) {
+	t.Helper()
+	throttled := func() uint64 {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.limiter(msg.Interface).throttled
+	}
+	before := throttled()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := c.SendMessage(context.Background(), msg); err != nil {
+			t.Errorf("SendMessage 失败: %v", err)
+		}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for throttled() == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("帧 % X 没有进入等待队列", msg.Data)
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+}
+
+func TestRateLimitKeepsArrivalOrder(t *testing.T) {
+	inner := &recordingCommunicator{}
+	c := NewRateLimitedCommunicator(inner, RateLimitConfig{Rate: 50, Burst: 1})
+
+	// 第一帧取走唯一的令牌，之后的帧都要等待
+	if err := c.SendMessage(context.Background(), RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := byte(1); i <= 5; i++ {
+		sendAsync(t, c, &wg, RawMessage{Interface: "can0", ID: 0x27, Data: []byte{i}})
+	}
+	wg.Wait()
+
+	want := [][]byte{{0}, {1}, {2}, {3}, {4}, {5}}
+	if got := inner.sentData(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+}
+
+func TestRateLimitCoalescesWaitingPose(t *testing.T) {
+	inner := &recordingCommunicator{}
+	c := NewRateLimitedCommunicator(inner, RateLimitConfig{Rate: 20, Burst: 1})
+
+	if err := c.SendMessage(context.Background(), RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0x01, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	sendAsync(t, c, &wg, RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0x01, 1}, Coalesce: true})
+	sendAsync(t, c, &wg, RawMessage{Interface: "can0", ID: 0x28, Data: []byte{0x01, 2}, Coalesce: true})
+	// 取代第一个等待的帧并沿用它的位置，仍然排在 0x28 之前
+	sendAsync(t, c, &wg, RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0x01, 3}, Coalesce: true})
+	wg.Wait()
+
+	want := [][]byte{{0x01, 0}, {0x01, 3}, {0x01, 2}}
+	if got := inner.sentData(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+	if stats := c.InterfaceStats()["can0"]; stats.Coalesced != 1 || stats.Pending != 0 {
+		t.Fatalf("coalesced=%d pending=%d，期望 1 和 0", stats.Coalesced, stats.Pending)
+	}
+}
+
+func TestRateLimitBatchCancelReturnsTokens(t *testing.T) {
+	inner := &recordingCommunicator{}
+	c := NewRateLimitedCommunicator(inner, RateLimitConfig{Rate: 1, Burst: 2})
+
+	// 前两帧取走全部令牌，第三帧等待期间超时，整批不发送
+	frames := []BatchFrame{
+		{Message: RawMessage{Interface: "can0", ID: 0x27, Data: []byte{1}}},
+		{Message: RawMessage{Interface: "can0", ID: 0x27, Data: []byte{2}}},
+		{Message: RawMessage{Interface: "can0", ID: 0x27, Data: []byte{3}}},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.SendBatch(ctx, frames); err == nil {
+		t.Fatal("等待令牌超时时 SendBatch 应返回错误")
+	}
+
+	if got := inner.sentData(); len(got) != 0 {
+		t.Fatalf("不应发送任何帧，实际发送 %v", got)
+	}
+	c.mutex.Lock()
+	tokens := c.limiter("can0").tokens
+	c.mutex.Unlock()
+	if tokens < 2 {
+		t.Fatalf("令牌为 %.2f，期望归还到 2", tokens)
+	}
+	if stats := c.InterfaceStats()["can0"]; stats.Pending != 0 || stats.Throttled != 0 {
+		t.Fatalf("pending=%d throttled=%d，期望都为 0", stats.Pending, stats.Throttled)
+	}
+}
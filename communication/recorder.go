@@ -0,0 +1,200 @@
+package communication
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hands/config"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultRecordDir 未配置录制目录时使用的目录
+const defaultRecordDir = "recordings"
+
+// RecordingInfo 一次录制的状态
+type RecordingInfo struct {
+	Interface string    `json:"interface"`
+	File      string    `json:"file"`
+	Active    bool      `json:"active"`
+	Frames    int       `json:"frames"`
+	StartedAt time.Time `json:"startedAt"`
+	StoppedAt time.Time `json:"stoppedAt,omitzero"`
+}
+
+// recordingSession 一个接口上正在进行的录制
+type recordingSession struct {
+	info   RecordingInfo
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// Recorder 按接口把发送成功的帧写入 candump -l 格式的日志文件
+type Recorder struct {
+	dir      string                       // 录制目录，为空时使用全局配置或 defaultRecordDir
+	sessions map[string]*recordingSession // interface -> 当前录制
+	history  []RecordingInfo              // 已结束的录制
+	mutex    sync.Mutex
+}
+
+// NewRecorder 创建录制器，dir 为空时使用全局配置的录制目录
+func NewRecorder(dir string) *Recorder {
+	return &Recorder{
+		dir:      dir,
+		sessions: make(map[string]*recordingSession),
+	}
+}
+
+// defaultRecorder 所有通信客户端共享的录制器
+var defaultRecorder = NewRecorder("")
+
+// DefaultRecorder 返回进程内共享的录制器
+func DefaultRecorder() *Recorder { return defaultRecorder }
+
+// Dir 返回录制文件所在目录
+func (r *Recorder) Dir() string {
+	if r.dir != "" {
+		return r.dir
+	}
+	if config.Config != nil && config.Config.RecordDir != "" {
+		return config.Config.RecordDir
+	}
+	return defaultRecordDir
+}
+
+// Start 开始录制指定接口，每次录制写入一个新文件
+func (r *Recorder) Start(ifName string) (RecordingInfo, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session, ok := r.sessions[ifName]; ok {
+		return session.info, fmt.Errorf("接口 %s 已在录制中", ifName)
+	}
+
+	dir := r.Dir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return RecordingInfo{}, fmt.Errorf("创建录制目录失败：%w", err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.log", ifName, now.Format("20060102-150405.000"))
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return RecordingInfo{}, fmt.Errorf("创建录制文件失败：%w", err)
+	}
+
+	session := &recordingSession{
+		info: RecordingInfo{
+			Interface: ifName,
+			File:      name,
+			Active:    true,
+			StartedAt: now,
+		},
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}
+	r.sessions[ifName] = session
+
+	log.Printf("⏺️ 开始录制接口 %s -> %s", ifName, name)
+	return session.info, nil
+}
+
+// Stop 停止录制指定接口并关闭文件
+func (r *Recorder) Stop(ifName string) (RecordingInfo, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, ok := r.sessions[ifName]
+	if !ok {
+		return RecordingInfo{}, fmt.Errorf("接口 %s 没有在录制", ifName)
+	}
+	delete(r.sessions, ifName)
+
+	flushErr := session.writer.Flush()
+	closeErr := session.file.Close()
+
+	session.info.Active = false
+	session.info.StoppedAt = time.Now()
+	r.history = append(r.history, session.info)
+
+	log.Printf("⏹️ 接口 %s 录制结束，共 %d 帧 -> %s", ifName, session.info.Frames, session.info.File)
+
+	if flushErr != nil {
+		return session.info, fmt.Errorf("写入录制文件失败：%w", flushErr)
+	}
+	if closeErr != nil {
+		return session.info, fmt.Errorf("关闭录制文件失败：%w", closeErr)
+	}
+	return session.info, nil
+}
+
+// Recordings 返回正在进行和已结束的录制，按开始时间排序
+func (r *Recorder) Recordings() []RecordingInfo {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]RecordingInfo, 0, len(r.sessions)+len(r.history))
+	result = append(result, r.history...)
+	for _, session := range r.sessions {
+		result = append(result, session.info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt.Before(result[j].StartedAt) })
+	return result
+}
+
+// record 如果帧所在接口正在录制，则追加一行日志
+func (r *Recorder) record(ts time.Time, msg RawMessage) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session, ok := r.sessions[msg.Interface]
+	if !ok {
+		return
+	}
+
+	if _, err := session.writer.WriteString(FormatCandumpLine(ts, msg) + "\n"); err != nil {
+		log.Printf("⚠️ 接口 %s 写入录制文件失败: %v", msg.Interface, err)
+		return
+	}
+	session.info.Frames++
+}
+
+// RecordingCommunicator 在发送成功后把帧交给 Recorder 的 Communicator 装饰器
+type RecordingCommunicator struct {
+	Communicator
+	recorder *Recorder
+}
+
+// NewRecordingCommunicator 用录制器包装一个 Communicator
+func NewRecordingCommunicator(comm Communicator, recorder *Recorder) Communicator {
+	return &RecordingCommunicator{Communicator: comm, recorder: recorder}
+}
+
+func (c *RecordingCommunicator) SendMessage(ctx context.Context, msg RawMessage) error {
+	ts := time.Now()
+	if err := c.Communicator.SendMessage(ctx, msg); err != nil {
+		return err
+	}
+	c.recorder.record(ts, msg)
+	return nil
+}
+
+// SendBatch 批量发送成功后按帧间延时推算每帧的时间戳
+func (c *RecordingCommunicator) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	ts := time.Now()
+	if err := c.Communicator.SendBatch(ctx, frames); err != nil {
+		return err
+	}
+	for _, frame := range frames {
+		ts = ts.Add(frame.Delay)
+		c.recorder.record(ts, frame.Message)
+	}
+	return nil
+}
+
+// Unwrap 返回被包装的 Communicator
+func (c *RecordingCommunicator) Unwrap() Communicator { return c.Communicator }
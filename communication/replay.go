@@ -0,0 +1,218 @@
+package communication
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReplayOptions 回放选项
+type ReplayOptions struct {
+	// Speed 时间缩放系数：1 按原始节奏回放，2 为两倍速，0.5 为半速，<= 0 表示不等待、尽快发送
+	Speed float64
+	// InterfaceMap 接口重映射，原接口 -> 目标接口；未列出的接口保持不变
+	InterfaceMap map[string]string
+	// IDMap CAN ID 重映射，原 ID -> 目标 ID；未列出的 ID 保持不变。例如把右手的录制回放到左手时映射 0x27 -> 0x28，
+	// 两只手互换时同时映射 0x27 -> 0x28 和 0x28 -> 0x27
+	IDMap map[uint32]uint32
+	// Progress 每成功发送一帧后调用，参数为已发送帧数，可为空
+	Progress func(sent int)
+}
+
+// ReplayFrames 通过 comm 按日志中的时间间隔重新发送帧，返回成功发送的帧数
+func ReplayFrames(ctx context.Context, comm Communicator, frames []LoggedFrame, opts ReplayOptions) (int, error) {
+	if len(frames) == 0 {
+		return 0, nil
+	}
+
+	start := time.Now()
+	first := frames[0].Timestamp
+	sent := 0
+	for i, frame := range frames {
+		if opts.Speed > 0 {
+			offset := time.Duration(float64(frame.Timestamp.Sub(first)) / opts.Speed)
+			if wait := time.Until(start.Add(offset)); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return sent, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+
+		msg := frame.Message
+		if target, ok := opts.InterfaceMap[msg.Interface]; ok {
+			msg.Interface = target
+		}
+		if target, ok := opts.IDMap[msg.ID]; ok {
+			msg.ID = target
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		err := comm.SendMessage(sendCtx, msg)
+		cancel()
+		if err != nil {
+			return sent, fmt.Errorf("回放第 %d 帧失败：%w", i+1, err)
+		}
+		sent++
+		if opts.Progress != nil {
+			opts.Progress(sent)
+		}
+	}
+	return sent, nil
+}
+
+// ParseCANIDMap 解析 CAN ID 映射，ID 可以写成十进制或带 0x 前缀的十六进制，例如 {"0x27": "0x28"}
+func ParseCANIDMap(raw map[string]string) (map[uint32]uint32, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	parse := func(s string) (uint32, error) {
+		id, err := strconv.ParseUint(s, 0, 32)
+		if err != nil || id > canExtendedIDMax {
+			return 0, fmt.Errorf("无效的 CAN ID: %s", s)
+		}
+		return uint32(id), nil
+	}
+
+	idMap := make(map[uint32]uint32, len(raw))
+	for from, to := range raw {
+		fromID, err := parse(from)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := idMap[fromID]; ok {
+			return nil, fmt.Errorf("CAN ID %s 重复映射", from)
+		}
+		toID, err := parse(to)
+		if err != nil {
+			return nil, err
+		}
+		idMap[fromID] = toID
+	}
+	return idMap, nil
+}
+
+// ReplayStatus 回放任务状态
+type ReplayStatus struct {
+	File      string    `json:"file"`
+	Running   bool      `json:"running"`
+	Total     int       `json:"total"`
+	Sent      int       `json:"sent"`
+	Speed     float64   `json:"speed"`
+	StartedAt time.Time `json:"startedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Replayer 管理录制文件的后台回放，同一时间只运行一个回放任务
+type Replayer struct {
+	recorder *Recorder
+	status   ReplayStatus
+	cancel   context.CancelFunc
+	done     chan struct{} // 当前回放 goroutine 退出时关闭
+	mutex    sync.Mutex
+}
+
+// NewReplayer 创建回放器，从 recorder 的录制目录读取文件
+func NewReplayer(recorder *Recorder) *Replayer {
+	return &Replayer{recorder: recorder}
+}
+
+// defaultReplayer 进程内共享的回放器
+var defaultReplayer = NewReplayer(defaultRecorder)
+
+// DefaultReplayer 返回进程内共享的回放器
+func DefaultReplayer() *Replayer { return defaultReplayer }
+
+// Start 在后台回放录制目录中的文件
+func (p *Replayer) Start(comm Communicator, file string, opts ReplayOptions) (ReplayStatus, error) {
+	// 只允许回放录制目录中的文件
+	if file == "" || filepath.Base(file) != file {
+		return ReplayStatus{}, fmt.Errorf("无效的录制文件名: %s", file)
+	}
+
+	f, err := os.Open(filepath.Join(p.recorder.Dir(), file))
+	if err != nil {
+		return ReplayStatus{}, fmt.Errorf("打开录制文件失败：%w", err)
+	}
+	frames, err := ReadCandumpLog(f)
+	f.Close()
+	if err != nil {
+		return ReplayStatus{}, fmt.Errorf("解析录制文件失败：%w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.status.Running {
+		return p.status, fmt.Errorf("已有回放任务在运行: %s", p.status.File)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	p.cancel = cancel
+	p.done = done
+	p.status = ReplayStatus{
+		File:      file,
+		Running:   true,
+		Total:     len(frames),
+		Speed:     opts.Speed,
+		StartedAt: time.Now(),
+	}
+
+	opts.Progress = func(sent int) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.status.Sent = sent
+	}
+
+	log.Printf("▶️ 开始回放 %s (%d 帧, 速度 %.2fx)", file, len(frames), opts.Speed)
+	go func() {
+		defer close(done)
+		sent, err := ReplayFrames(ctx, comm, frames, opts)
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.status.Running = false
+		p.status.Sent = sent
+		if err != nil {
+			p.status.Error = err.Error()
+			log.Printf("⚠️ 回放 %s 中止，已发送 %d/%d 帧: %v", file, sent, len(frames), err)
+		} else {
+			log.Printf("✅ 回放 %s 完成，共发送 %d 帧", file, sent)
+		}
+		cancel()
+	}()
+
+	return p.status, nil
+}
+
+// Stop 停止当前回放任务，等待正在发送的帧完成后返回，之后不会再有回放的帧发出
+func (p *Replayer) Stop() ReplayStatus {
+	p.mutex.Lock()
+	running, done := p.status.Running, p.done
+	if running && p.cancel != nil {
+		p.cancel()
+	}
+	p.mutex.Unlock()
+
+	if running && done != nil {
+		<-done
+	}
+	return p.Status()
+}
+
+// Status 返回最近一次回放任务的状态
+func (p *Replayer) Status() ReplayStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.status
+}
@@ -0,0 +1,71 @@
+package communication
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseCANIDMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		want    map[uint32]uint32
+		wantErr bool
+	}{
+		{name: "空映射", raw: nil, want: nil},
+		{name: "十六进制互换", raw: map[string]string{"0x27": "0x28", "0x28": "0x27"}, want: map[uint32]uint32{0x27: 0x28, 0x28: 0x27}},
+		{name: "十进制", raw: map[string]string{"39": "40"}, want: map[uint32]uint32{0x27: 0x28}},
+		{name: "扩展帧 ID", raw: map[string]string{"0x1FFFFFFF": "0x27"}, want: map[uint32]uint32{0x1FFFFFFF: 0x27}},
+		{name: "超出 29 位", raw: map[string]string{"0x27": "0x20000000"}, wantErr: true},
+		{name: "无效 ID", raw: map[string]string{"right": "0x28"}, wantErr: true},
+		{name: "同一 ID 的两种写法", raw: map[string]string{"0x27": "0x28", "39": "0x29"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCANIDMap(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("得到 %v，期望 %v", got, tt.want)
+			}
+			for from, to := range tt.want {
+				if got[from] != to {
+					t.Fatalf("得到 %v，期望 %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayFramesRemapsInterfaceAndID(t *testing.T) {
+	frames := []LoggedFrame{
+		{Message: RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0x01, 10}}},
+		{Message: RawMessage{Interface: "can0", ID: 0x28, Data: []byte{0x01, 20}}},
+		{Message: RawMessage{Interface: "can1", ID: 0x30, Data: []byte{0x01, 30}}},
+	}
+	inner := &messageRecorder{}
+
+	sent, err := ReplayFrames(context.Background(), inner, frames, ReplayOptions{
+		InterfaceMap: map[string]string{"can0": "vcan0"},
+		IDMap:        map[uint32]uint32{0x27: 0x28, 0x28: 0x27},
+	})
+	if err != nil || sent != len(frames) {
+		t.Fatalf("sent=%d err=%v，期望发送 %d 帧", sent, err, len(frames))
+	}
+
+	want := []struct {
+		ifName string
+		id     uint32
+	}{{"vcan0", 0x28}, {"vcan0", 0x27}, {"can1", 0x30}}
+	got := inner.sentMessages()
+	for i, w := range want {
+		if got[i].Interface != w.ifName || got[i].ID != w.id {
+			t.Fatalf("第 %d 帧为 %s/%03X，期望 %s/%03X", i+1, got[i].Interface, got[i].ID, w.ifName, w.id)
+		}
+	}
+}
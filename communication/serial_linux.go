@@ -0,0 +1,78 @@
+//go:build linux
+
+package communication
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// serialBaudRates 支持的串口波特率
+var serialBaudRates = map[int]uint32{
+	9600:    unix.B9600,
+	19200:   unix.B19200,
+	38400:   unix.B38400,
+	57600:   unix.B57600,
+	115200:  unix.B115200,
+	230400:  unix.B230400,
+	460800:  unix.B460800,
+	921600:  unix.B921600,
+	1000000: unix.B1000000,
+	2000000: unix.B2000000,
+	3000000: unix.B3000000,
+}
+
+// openSerialPort 以原始模式 (8N1，无流控，无回显) 打开串口
+func openSerialPort(path string, baud int) (io.ReadWriteCloser, error) {
+	speed, ok := serialBaudRates[baud]
+	if !ok {
+		return nil, fmt.Errorf("不支持的串口波特率: %d", baud)
+	}
+
+	// 非阻塞打开，os.File 才能使用运行时轮询器，Close 时可以唤醒阻塞的读取
+	file, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	var termErr error
+	err = raw.Control(func(fd uintptr) {
+		termErr = setRawTermios(int(fd), speed)
+	})
+	if err == nil {
+		err = termErr
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("设置串口参数失败：%w", err)
+	}
+	return file, nil
+}
+
+// setRawTermios 等价于 cfmakeraw 加上波特率设置
+func setRawTermios(fd int, speed uint32) error {
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | speed
+	t.Ispeed = speed
+	t.Ospeed = speed
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
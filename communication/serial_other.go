@@ -0,0 +1,12 @@
+//go:build !linux
+
+package communication
+
+import (
+	"fmt"
+	"io"
+)
+
+func openSerialPort(path string, baud int) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("slcan 串口目前仅支持 Linux 平台")
+}
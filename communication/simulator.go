@@ -0,0 +1,198 @@
+package communication
+
+import (
+	"context"
+	"fmt"
+	"hands/config"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SimServiceURLPrefix 以此前缀开头的服务 URL 表示使用进程内模拟总线，例如 "sim://"
+const SimServiceURLPrefix = "sim://"
+
+// 模拟手部识别的 L10 帧前缀，其他前缀按通用寄存器处理
+const (
+	simFingerPrefix   byte = 0x01
+	simPalmPrefix     byte = 0x04
+	simFullPosePrefix byte = 0x10 // CAN FD 组合帧：手指 6 字节 + 手掌 4 字节 + 手指速度 6 字节
+)
+
+// SimulatedHandState 模拟手部的状态
+// 模拟手不区分型号：每个指令前缀对应一个寄存器，保存最近一次写入的数据，
+// 因此前缀和长度不同的型号 (L10、L20 等) 都可以在总线上模拟
+type SimulatedHandState struct {
+	Interface  string          `json:"interface"`
+	ID         uint32          `json:"id"`
+	Registers  map[byte][]byte `json:"registers"` // 指令前缀 -> 最近写入的数据
+	Speed      []byte          `json:"speed"`     // L10 组合帧中的手指速度
+	FrameCount int             `json:"frameCount"`
+	LastUpdate time.Time       `json:"lastUpdate"`
+}
+
+// simHandKey 用接口名和 CAN ID 唯一确定总线上的一只手
+type simHandKey struct {
+	ifName string
+	id     uint32
+}
+
+// VirtualBus 进程内的虚拟 CAN 总线，总线上每个 (接口, CAN ID) 对应一只模拟手
+type VirtualBus struct {
+	hands map[simHandKey]*SimulatedHandState
+	hub   *subscriberHub
+	mutex sync.Mutex
+}
+
+// NewVirtualBus 创建一条空的虚拟总线
+func NewVirtualBus() *VirtualBus {
+	return &VirtualBus{
+		hands: make(map[simHandKey]*SimulatedHandState),
+		hub:   newSubscriberHub(),
+	}
+}
+
+// defaultVirtualBus 所有模拟通信客户端共享的总线
+var defaultVirtualBus = NewVirtualBus()
+
+// DefaultVirtualBus 返回进程内共享的虚拟总线
+func DefaultVirtualBus() *VirtualBus { return defaultVirtualBus }
+
+// getHand 获取（必要时创建）模拟手，新手处于 L10 的默认姿态
+func (b *VirtualBus) getHand(key simHandKey) *SimulatedHandState {
+	hand, ok := b.hands[key]
+	if !ok {
+		hand = &SimulatedHandState{
+			Interface: key.ifName,
+			ID:        key.id,
+			Registers: map[byte][]byte{
+				simFingerPrefix: {64, 64, 64, 64, 64, 64},
+				simPalmPrefix:   {128, 128, 128, 128},
+			},
+			Speed:      []byte{255, 255, 255, 255, 255, 255},
+			LastUpdate: time.Now(),
+		}
+		b.hands[key] = hand
+	}
+	return hand
+}
+
+// deliver 将一帧投递到总线：把数据写入模拟手对应前缀的寄存器，并以相同前缀回送寄存器内容作为反馈
+// 只含前缀的帧视为查询，仅回送寄存器内容，没有写入过的前缀不应答；L10 组合帧分别回送手指和手掌位置
+// 任意 CAN ID 都会应答，描述文件定义的型号可以使用自定义的 CAN ID
+func (b *VirtualBus) deliver(msg RawMessage) error {
+	if len(msg.Data) == 0 {
+		return nil // 空帧，总线上没有节点响应
+	}
+
+	b.mutex.Lock()
+	hand := b.getHand(simHandKey{ifName: msg.Interface, id: msg.ID})
+	prefix, payload := msg.Data[0], msg.Data[1:]
+
+	feedbackFor := func(prefix byte) RawMessage {
+		return RawMessage{Interface: msg.Interface, ID: msg.ID, Data: append([]byte{prefix}, hand.Registers[prefix]...)}
+	}
+
+	var feedback []RawMessage
+	switch {
+	case prefix == simFullPosePrefix:
+		fullLen := 6 + 4 + len(hand.Speed)
+		if !msg.FD || len(payload) < fullLen {
+			b.mutex.Unlock()
+			return fmt.Errorf("模拟手收到无效的组合姿态帧 (FD: %v, 长度: %d)", msg.FD, len(payload))
+		}
+		hand.Registers[simFingerPrefix] = slices.Clone(payload[:6])
+		hand.Registers[simPalmPrefix] = slices.Clone(payload[6:10])
+		copy(hand.Speed, payload[10:])
+		feedback = append(feedback, feedbackFor(simFingerPrefix), feedbackFor(simPalmPrefix))
+	case len(payload) > 0:
+		hand.Registers[prefix] = slices.Clone(payload)
+		feedback = append(feedback, feedbackFor(prefix))
+	default:
+		if _, ok := hand.Registers[prefix]; ok {
+			feedback = append(feedback, feedbackFor(prefix))
+		}
+	}
+	hand.FrameCount++
+	hand.LastUpdate = time.Now()
+	b.mutex.Unlock()
+
+	for _, frame := range feedback {
+		b.hub.dispatch(frame)
+	}
+	return nil
+}
+
+// HandStates 返回总线上所有模拟手的状态快照
+func (b *VirtualBus) HandStates() []SimulatedHandState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	states := make([]SimulatedHandState, 0, len(b.hands))
+	for _, hand := range b.hands {
+		state := *hand
+		state.Registers = make(map[byte][]byte, len(hand.Registers))
+		for prefix, data := range hand.Registers {
+			state.Registers[prefix] = slices.Clone(data)
+		}
+		state.Speed = slices.Clone(hand.Speed)
+		states = append(states, state)
+	}
+	return states
+}
+
+// SimulatedClient 基于进程内虚拟总线的 Communicator 实现，用于演示和测试
+type SimulatedClient struct {
+	bus *VirtualBus
+}
+
+func NewSimulatedClient(bus *VirtualBus) Communicator {
+	if bus == nil {
+		bus = defaultVirtualBus
+	}
+	return &SimulatedClient{bus: bus}
+}
+
+// IsSimServiceURL 判断服务 URL 是否指向模拟总线
+func IsSimServiceURL(url string) bool {
+	return strings.HasPrefix(url, SimServiceURLPrefix)
+}
+
+func (c *SimulatedClient) SendMessage(ctx context.Context, msg RawMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !config.IsValidInterface(msg.Interface) {
+		return fmt.Errorf("模拟总线上不存在接口 %s", msg.Interface)
+	}
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	return c.bus.deliver(msg)
+}
+
+func (c *SimulatedClient) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	return sendSequentially(ctx, frames, c.SendMessage)
+}
+
+// SupportsFD 模拟总线上的接口均支持 CAN FD
+func (c *SimulatedClient) SupportsFD() bool { return true }
+
+// GetAllInterfaceStatuses 模拟总线上所有配置的接口始终处于活动状态
+func (c *SimulatedClient) GetAllInterfaceStatuses() (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, ifName := range config.Config.AvailableInterfaces {
+		result[ifName] = true
+	}
+	return result, nil
+}
+
+// SetServiceURL 对模拟总线无意义，保留以满足 Communicator 接口
+func (c *SimulatedClient) SetServiceURL(url string) {}
+
+func (c *SimulatedClient) IsConnected() bool { return true }
+
+func (c *SimulatedClient) Subscribe(filter FrameFilter) (Subscription, error) {
+	return c.bus.hub.add(filter), nil
+}
@@ -0,0 +1,339 @@
+package communication
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSlcanBaud         = 115200                  // 串口波特率，USB CDC 适配器通常忽略该值
+	defaultSlcanBitrate      = 1_000_000               // 未配置时的 CAN 总线波特率
+	slcanCommandTimeout      = 500 * time.Millisecond  // 等待单条指令应答的时间
+	slcanOpenTimeout         = 4 * slcanCommandTimeout // 打开通道需要依次发送 4 条指令
+	slcanAckBuffer           = 16
+	slcanOK             byte = '\r' // 指令执行成功
+	slcanError          byte = 0x07 // 指令执行失败 (BEL)
+)
+
+// slcanBitrates Lawicel 协议 Sn 指令支持的 CAN 波特率
+var slcanBitrates = map[int]byte{
+	10_000:    '0',
+	20_000:    '1',
+	50_000:    '2',
+	100_000:   '3',
+	125_000:   '4',
+	250_000:   '5',
+	500_000:   '6',
+	800_000:   '7',
+	1_000_000: '8',
+}
+
+// errSlcanNack 适配器对指令回复了 BEL
+var errSlcanNack = errors.New("slcan 适配器拒绝了指令")
+
+// SlcanClient 通过串口上的 Lawicel slcan ASCII 协议收发 CAN 帧，用于没有 SocketCAN 驱动的 USB-CAN 适配器
+// 一个适配器只有一个 CAN 通道，对应配置中的一个接口名；串口断开后在下次发送或健康检查时重新打开
+type SlcanClient struct {
+	portPath string
+	baud     int
+	ifName   string
+	bitrate  int
+	port     io.ReadWriteCloser // 当前打开的串口，为空表示未打开
+	acks     chan error         // 读取循环收到的指令应答
+	hub      *subscriberHub
+	mutex    sync.Mutex // 保护 port，并保证指令与应答一一对应
+}
+
+// NewSlcanClient 创建 slcan 通信客户端，串口在第一次使用时打开
+// ifName 为该适配器对应的 CAN 接口名，bitrate 为 CAN 总线波特率，baud 为串口波特率
+func NewSlcanClient(portPath, ifName string, bitrate, baud int) (Communicator, error) {
+	if portPath == "" {
+		return nil, fmt.Errorf("缺少 slcan 串口配置")
+	}
+	if bitrate <= 0 {
+		bitrate = defaultSlcanBitrate
+	}
+	if _, ok := slcanBitrates[bitrate]; !ok {
+		return nil, fmt.Errorf("slcan 不支持的 CAN 波特率: %d", bitrate)
+	}
+	if baud <= 0 {
+		baud = defaultSlcanBaud
+	}
+	return &SlcanClient{
+		portPath: portPath,
+		baud:     baud,
+		ifName:   ifName,
+		bitrate:  bitrate,
+		acks:     make(chan error, slcanAckBuffer),
+		hub:      newSubscriberHub(),
+	}, nil
+}
+
+// ensureOpen 打开串口并开启 CAN 通道，调用方需持有 c.mutex
+func (c *SlcanClient) ensureOpen(ctx context.Context) error {
+	if c.port != nil {
+		return nil
+	}
+
+	port, err := openSerialPort(c.portPath, c.baud)
+	if err != nil {
+		return fmt.Errorf("打开 slcan 串口 %s 失败：%w", c.portPath, err)
+	}
+	c.port = port
+	go c.readLoop(port)
+
+	// 先用空指令清掉适配器缓冲区中不完整的输入，再关闭可能处于打开状态的通道，这两步的应答不做要求
+	c.command(ctx, "")
+	c.command(ctx, "C")
+
+	if err := c.command(ctx, "S"+string(slcanBitrates[c.bitrate])); err != nil {
+		c.closePort()
+		return fmt.Errorf("设置 slcan 波特率失败：%w", err)
+	}
+	if err := c.command(ctx, "O"); err != nil {
+		c.closePort()
+		return fmt.Errorf("打开 slcan 通道失败：%w", err)
+	}
+
+	log.Printf("🔌 slcan 适配器 %s 已打开 (接口 %s，波特率 %d)", c.portPath, c.ifName, c.bitrate)
+	return nil
+}
+
+// closePort 关闭当前串口，调用方需持有 c.mutex
+func (c *SlcanClient) closePort() {
+	if c.port == nil {
+		return
+	}
+	c.port.Close()
+	c.port = nil
+}
+
+// command 发送一条以 CR 结尾的指令并等待应答，调用方需持有 c.mutex
+func (c *SlcanClient) command(ctx context.Context, cmd string) error {
+	// 丢弃之前超时未取走的应答，避免与本次指令错位
+	for len(c.acks) > 0 {
+		<-c.acks
+	}
+
+	if _, err := io.WriteString(c.port, cmd+"\r"); err != nil {
+		return fmt.Errorf("写入 slcan 指令失败：%w", err)
+	}
+
+	timer := time.NewTimer(slcanCommandTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-c.acks:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("等待 slcan 应答超时")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readLoop 读取串口上的应答和接收帧，串口关闭或出错后退出
+func (c *SlcanClient) readLoop(port io.ReadWriteCloser) {
+	reader := bufio.NewReader(port)
+	var line []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			c.mutex.Lock()
+			if c.port == port {
+				log.Printf("⚠️ slcan 串口 %s 读取失败: %v", c.portPath, err)
+				c.closePort()
+			}
+			c.mutex.Unlock()
+			return
+		}
+
+		switch b {
+		case slcanError:
+			line = line[:0]
+			c.ack(errSlcanNack)
+		case slcanOK:
+			c.handleLine(string(line))
+			line = line[:0]
+		case '\n':
+			// 部分适配器在 CR 后追加 LF
+		default:
+			line = append(line, b)
+		}
+	}
+}
+
+// handleLine 处理一行以 CR 结尾的输出：接收帧分发给订阅者，其余视为指令应答
+func (c *SlcanClient) handleLine(line string) {
+	if line == "" || line == "z" || line == "Z" {
+		// 空行是普通指令的成功应答，z/Z 是发送标准帧/扩展帧的成功应答
+		c.ack(nil)
+		return
+	}
+
+	switch line[0] {
+	case 't', 'T':
+		msg, err := decodeSlcanFrame(c.ifName, line)
+		if err != nil {
+			log.Printf("⚠️ slcan 串口 %s 收到无效帧 %q: %v", c.portPath, line, err)
+			return
+		}
+		c.hub.dispatch(msg)
+	case 'r', 'R':
+		// 远程帧不携带数据，设备协议不使用
+	default:
+		// 版本号、序列号等查询指令的应答
+		c.ack(nil)
+	}
+}
+
+// ack 投递一次指令应答，缓冲区已满时丢弃
+func (c *SlcanClient) ack(err error) {
+	select {
+	case c.acks <- err:
+	default:
+	}
+}
+
+// encodeSlcanFrame 将 RawMessage 编码为 slcan 发送指令：标准帧 tiiildd...，扩展帧 Tiiiiiiiildd...
+func encodeSlcanFrame(msg RawMessage) (string, error) {
+	if msg.FD {
+		return "", fmt.Errorf("slcan 不支持 CAN FD 帧")
+	}
+	if err := msg.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if msg.IsExtended() {
+		fmt.Fprintf(&b, "T%08X", msg.ID&canExtendedIDMax)
+	} else {
+		fmt.Fprintf(&b, "t%03X", msg.ID)
+	}
+	b.WriteByte('0' + byte(len(msg.Data)))
+	b.WriteString(strings.ToUpper(hex.EncodeToString(msg.Data)))
+	return b.String(), nil
+}
+
+// decodeSlcanFrame 解析 slcan 接收帧，忽略适配器开启时间戳后附加在末尾的时间戳
+func decodeSlcanFrame(ifName, line string) (RawMessage, error) {
+	idLen := 3
+	if line[0] == 'T' {
+		idLen = 8
+	}
+	if len(line) < 1+idLen+1 {
+		return RawMessage{}, fmt.Errorf("帧长度不足")
+	}
+
+	id, err := strconv.ParseUint(line[1:1+idLen], 16, 32)
+	if err != nil {
+		return RawMessage{}, fmt.Errorf("无效的 CAN ID：%w", err)
+	}
+	if id > canExtendedIDMax {
+		return RawMessage{}, fmt.Errorf("CAN ID %X 超出范围", id)
+	}
+
+	length := int(line[1+idLen] - '0')
+	if length < 0 || length > CANMaxDataLen {
+		return RawMessage{}, fmt.Errorf("无效的数据长度 %c", line[1+idLen])
+	}
+	start := 2 + idLen
+	if len(line) < start+2*length {
+		return RawMessage{}, fmt.Errorf("数据长度不足 %d 字节", length)
+	}
+	data, err := hex.DecodeString(line[start : start+2*length])
+	if err != nil {
+		return RawMessage{}, fmt.Errorf("无效的数据：%w", err)
+	}
+
+	return RawMessage{Interface: ifName, ID: uint32(id), Data: data, Extended: idLen == 8}, nil
+}
+
+func (c *SlcanClient) SendMessage(ctx context.Context, msg RawMessage) error {
+	if msg.Interface != c.ifName {
+		return fmt.Errorf("slcan 适配器 %s 只连接接口 %s，无法发送到 %s", c.portPath, c.ifName, msg.Interface)
+	}
+	cmd, err := encodeSlcanFrame(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.ensureOpen(ctx); err != nil {
+		return err
+	}
+	if err := c.command(ctx, cmd); err != nil {
+		if !errors.Is(err, errSlcanNack) && !errors.Is(err, ctx.Err()) {
+			// 写入失败或适配器无响应，关闭串口，下次发送时重新打开
+			c.closePort()
+		}
+		return fmt.Errorf("slcan 发送失败：%w", err)
+	}
+	return nil
+}
+
+// SendBatch 逐帧发送，帧间按 Delay 等待
+func (c *SlcanClient) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	return sendSequentially(ctx, frames, c.SendMessage)
+}
+
+// GetAllInterfaceStatuses 返回适配器对应接口的状态，串口未打开时会尝试打开，健康检查因此也负责重连
+func (c *SlcanClient) GetAllInterfaceStatuses() (map[string]bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), slcanOpenTimeout)
+	defer cancel()
+	if err := c.ensureOpen(ctx); err != nil {
+		return map[string]bool{c.ifName: false}, err
+	}
+	return map[string]bool{c.ifName: true}, nil
+}
+
+// SetServiceURL 对 slcan 无意义，保留以满足 Communicator 接口
+func (c *SlcanClient) SetServiceURL(url string) {}
+
+// IsConnected 串口已打开并开启了 CAN 通道即认为已连接
+func (c *SlcanClient) IsConnected() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.port != nil
+}
+
+// Subscribe 订阅接收到的 CAN 帧，会按需打开串口
+func (c *SlcanClient) Subscribe(filter FrameFilter) (Subscription, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), slcanOpenTimeout)
+	defer cancel()
+	if err := c.ensureOpen(ctx); err != nil {
+		log.Printf("⚠️ slcan 串口 %s 暂时无法接收: %v", c.portPath, err)
+	}
+	return c.hub.add(filter), nil
+}
+
+// Close 关闭 CAN 通道和串口
+func (c *SlcanClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.port != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), slcanCommandTimeout)
+		c.command(ctx, "C")
+		cancel()
+		c.closePort()
+	}
+	c.hub.closeAll()
+	return nil
+}
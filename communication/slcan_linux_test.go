@@ -0,0 +1,150 @@
+//go:build linux
+
+package communication
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY 打开一对伪终端，返回主设备和从设备路径；从设备作为 slcan 串口
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("无法打开伪终端: %v", err)
+	}
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		t.Skipf("无法解锁伪终端: %v", err)
+	}
+	n, err := unix.IoctlGetUint32(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		t.Skipf("无法获取伪终端编号: %v", err)
+	}
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+// runFakeAdapter 模拟 slcan 适配器：记录收到的指令，发送帧回复 z/Z，ID 666 的帧回复 BEL，其它指令回复 CR
+func runFakeAdapter(master *os.File, commands chan<- string) {
+	defer close(commands)
+	reader := bufio.NewReader(master)
+	for {
+		cmd, err := reader.ReadString('\r')
+		if err != nil {
+			return
+		}
+		cmd = strings.TrimSuffix(cmd, "\r")
+		commands <- cmd
+
+		reply := "\r"
+		switch {
+		case strings.HasPrefix(cmd, "t666"):
+			reply = "\a"
+		case strings.HasPrefix(cmd, "t"):
+			reply = "z\r"
+		case strings.HasPrefix(cmd, "T"):
+			reply = "Z\r"
+		}
+		if _, err := master.WriteString(reply); err != nil {
+			return
+		}
+	}
+}
+
+// expectCommands 按顺序读取适配器收到的指令
+func expectCommands(t *testing.T, commands <-chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-commands:
+			if got != w {
+				t.Fatalf("适配器收到 %q，期望 %q", got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("适配器没有收到 %q", w)
+		}
+	}
+}
+
+func TestSlcanRoundTripOverPTY(t *testing.T) {
+	master, slavePath := openPTY(t)
+	defer master.Close()
+
+	commands := make(chan string, 16)
+	go runFakeAdapter(master, commands)
+
+	comm, err := NewSlcanClient(slavePath, "can0", 500_000, 115200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := comm.(*SlcanClient)
+	defer client.Close()
+
+	// 订阅时打开串口：清空缓冲区、关闭通道、设置波特率、打开通道
+	sub, err := client.Subscribe(FrameFilter{Interface: "can0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	expectCommands(t, commands, "", "C", "S6", "O")
+	if !client.IsConnected() {
+		t.Fatal("打开通道后应已连接")
+	}
+
+	ctx := context.Background()
+	if err := client.SendMessage(ctx, RawMessage{Interface: "can0", ID: 0x27, Data: []byte{0x01, 0x80, 0xFF}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendMessage(ctx, RawMessage{Interface: "can0", ID: 0x12345, Data: []byte{0xAA}}); err != nil {
+		t.Fatal(err)
+	}
+	expectCommands(t, commands, "t02730180FF", "T000123451AA")
+
+	err = client.SendMessage(ctx, RawMessage{Interface: "can0", ID: 0x666})
+	if !errors.Is(err, errSlcanNack) {
+		t.Fatalf("适配器回复 BEL 时应返回 errSlcanNack，得到 %v", err)
+	}
+	expectCommands(t, commands, "t6660")
+	if !client.IsConnected() {
+		t.Fatal("适配器拒绝指令后串口应保持打开")
+	}
+
+	if err := client.SendMessage(ctx, RawMessage{Interface: "can1", ID: 0x27}); err == nil {
+		t.Fatal("发送到其它接口时应返回错误")
+	}
+
+	// 适配器转发的接收帧，第二帧带时间戳且以 CR LF 结尾
+	if _, err := master.WriteString("t0282AABB\rt0281CCEA60\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []RawMessage{
+		{Interface: "can0", ID: 0x28, Data: []byte{0xAA, 0xBB}},
+		{Interface: "can0", ID: 0x28, Data: []byte{0xCC}},
+	} {
+		select {
+		case got := <-sub.Frames():
+			if got.Interface != want.Interface || got.ID != want.ID || !bytes.Equal(got.Data, want.Data) {
+				t.Fatalf("收到 %+v，期望 %+v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("没有收到适配器转发的帧")
+		}
+	}
+
+	// 关闭时先关闭 CAN 通道
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectCommands(t, commands, "C")
+}
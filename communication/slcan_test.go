@@ -0,0 +1,103 @@
+package communication
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeSlcanFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     RawMessage
+		want    string
+		wantErr bool
+	}{
+		{name: "标准帧", msg: RawMessage{ID: 0x27, Data: []byte{0x01, 0xab, 0x00}}, want: "t027301AB00"},
+		{name: "空数据", msg: RawMessage{ID: 0x7FF}, want: "t7FF0"},
+		{name: "8 字节", msg: RawMessage{ID: 0x28, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}, want: "t02880102030405060708"},
+		{name: "扩展帧", msg: RawMessage{ID: 0x800, Data: []byte{0xFF}}, want: "T000008001FF"},
+		{name: "最大扩展帧 ID", msg: RawMessage{ID: 0x1FFFFFFF}, want: "T1FFFFFFF0"},
+		{name: "ID 较小的扩展帧", msg: RawMessage{ID: 0x27, Data: []byte{0x01}, Extended: true}, want: "T00000027101"},
+		{name: "FD 帧", msg: RawMessage{ID: 0x27, Data: []byte{1}, FD: true}, wantErr: true},
+		{name: "超过 8 字节", msg: RawMessage{ID: 0x27, Data: make([]byte, 9)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeSlcanFrame(tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("得到 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeSlcanFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		id       uint32
+		data     []byte
+		extended bool
+		wantErr  bool
+	}{
+		{name: "标准帧", line: "t0273010203", id: 0x27, data: []byte{1, 2, 3}},
+		{name: "小写十六进制", line: "t7ff2abcd", id: 0x7FF, data: []byte{0xAB, 0xCD}},
+		{name: "空数据", line: "t1230", id: 0x123, data: []byte{}},
+		{name: "扩展帧", line: "T1FFFFFFF1AA", id: 0x1FFFFFFF, data: []byte{0xAA}, extended: true},
+		{name: "ID 较小的扩展帧", line: "T000000271AA", id: 0x27, data: []byte{0xAA}, extended: true},
+		{name: "带时间戳", line: "t02820102EA60", id: 0x28, data: []byte{0x01, 0x02}},
+		{name: "ID 不完整", line: "t02", wantErr: true},
+		{name: "缺少长度", line: "t027", wantErr: true},
+		{name: "无效 ID", line: "t0G71AA", wantErr: true},
+		{name: "扩展帧 ID 超出 29 位", line: "T200000000", wantErr: true},
+		{name: "长度超过 8", line: "t0279", wantErr: true},
+		{name: "数据不足", line: "t0273AABB", wantErr: true},
+		{name: "无效数据", line: "t0271ZZ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSlcanFrame("can0", tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Interface != "can0" || got.ID != tt.id || got.Extended != tt.extended || !bytes.Equal(got.Data, tt.data) {
+				t.Fatalf("得到 %+v，期望 ID %X 扩展帧 %v 数据 % X", got, tt.id, tt.extended, tt.data)
+			}
+		})
+	}
+}
+
+func TestSlcanBitrateCodes(t *testing.T) {
+	// Lawicel 协议的 S0-S8
+	want := map[int]byte{
+		10_000: '0', 20_000: '1', 50_000: '2', 100_000: '3', 125_000: '4',
+		250_000: '5', 500_000: '6', 800_000: '7', 1_000_000: '8',
+	}
+	if len(slcanBitrates) != len(want) {
+		t.Fatalf("支持 %d 种波特率，期望 %d 种", len(slcanBitrates), len(want))
+	}
+	for bitrate, code := range want {
+		if slcanBitrates[bitrate] != code {
+			t.Errorf("波特率 %d 的指令为 S%c，期望 S%c", bitrate, slcanBitrates[bitrate], code)
+		}
+	}
+
+	if _, err := NewSlcanClient("/dev/null", "can0", 83_333, 0); err == nil {
+		t.Fatal("不支持的波特率应返回错误")
+	}
+	comm, err := NewSlcanClient("/dev/null", "can0", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := comm.(*SlcanClient); c.bitrate != defaultSlcanBitrate || c.baud != defaultSlcanBaud {
+		t.Fatalf("默认波特率为 %d/%d，期望 %d/%d", c.bitrate, c.baud, defaultSlcanBitrate, defaultSlcanBaud)
+	}
+}
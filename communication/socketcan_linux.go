@@ -0,0 +1,309 @@
+//go:build linux
+
+package communication
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hands/config"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	canFrameSize   = 16         // struct can_frame 的大小
+	canFDFrameSize = 72         // struct canfd_frame 的大小
+	canFDFlagBRS   = 0x01       // canfd_frame.flags 中的位速率切换标志
+	canFDFlagFDF   = 0x04       // canfd_frame.flags 中的 FD 帧标志
+	canEFFFlag     = 0x80000000 // 扩展帧标志
+	canSFFMask     = 0x000007FF // 标准帧 ID 掩码
+	canEFFMask     = 0x1FFFFFFF // 扩展帧 ID 掩码
+)
+
+// canSocket 封装一个绑定到指定接口的 CAN_RAW 套接字
+type canSocket struct {
+	ifName    string
+	file      *os.File
+	fdEnabled bool       // 是否已开启 CAN_RAW_FD_FRAMES，可以收发 FD 帧
+	mutex     sync.Mutex // 保证单个套接字上的写操作串行
+}
+
+// SocketCANClient 通过 Linux 原生 SocketCAN 直接收发 CAN 帧，无需 can-bridge 服务
+type SocketCANClient struct {
+	sockets map[string]*canSocket // interface -> socket
+	hub     *subscriberHub
+	mutex   sync.Mutex
+}
+
+func NewSocketCANClient() (Communicator, error) {
+	return &SocketCANClient{
+		sockets: make(map[string]*canSocket),
+		hub:     newSubscriberHub(),
+	}, nil
+}
+
+// openCANSocket 创建 CAN_RAW 套接字并绑定到指定接口
+func openCANSocket(ifName string) (*canSocket, error) {
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, fmt.Errorf("查找 CAN 接口 %s 失败：%w", ifName, err)
+	}
+
+	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW, unix.CAN_RAW)
+	if err != nil {
+		return nil, fmt.Errorf("创建 CAN 套接字失败：%w", err)
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrCAN{Ifindex: iface.Index}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("绑定 CAN 接口 %s 失败：%w", ifName, err)
+	}
+
+	// 尽量开启 FD 帧收发，内核或接口不支持时仍可收发经典帧
+	fdEnabled := unix.SetsockoptInt(fd, unix.SOL_CAN_RAW, unix.CAN_RAW_FD_FRAMES, 1) == nil
+
+	// 设置为非阻塞模式，使 os.File 可以使用运行时网络轮询器并支持读写超时
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("设置 CAN 套接字非阻塞模式失败：%w", err)
+	}
+
+	return &canSocket{
+		ifName:    ifName,
+		file:      os.NewFile(uintptr(fd), "can:"+ifName),
+		fdEnabled: fdEnabled,
+	}, nil
+}
+
+// getSocket 获取（必要时打开）指定接口的套接字
+func (c *SocketCANClient) getSocket(ifName string) (*canSocket, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if sock, ok := c.sockets[ifName]; ok {
+		return sock, nil
+	}
+
+	sock, err := openCANSocket(ifName)
+	if err != nil {
+		return nil, err
+	}
+	c.sockets[ifName] = sock
+	go c.readLoop(sock)
+	log.Printf("🔌 SocketCAN 接口 %s 已打开", ifName)
+	return sock, nil
+}
+
+// readLoop 持续读取套接字上的帧并分发给订阅者，套接字关闭后退出
+func (c *SocketCANClient) readLoop(sock *canSocket) {
+	buf := make([]byte, canFDFrameSize)
+	for {
+		n, err := sock.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Printf("⚠️ SocketCAN 接口 %s 读取失败: %v", sock.ifName, err)
+				c.dropSocket(sock)
+			}
+			return
+		}
+
+		msg, err := decodeCANFrame(sock.ifName, buf[:n])
+		if err != nil {
+			log.Printf("⚠️ SocketCAN 接口 %s 收到无效帧: %v", sock.ifName, err)
+			continue
+		}
+		c.hub.dispatch(msg)
+	}
+}
+
+// dropSocket 关闭并移除出错的套接字，下次发送时重新打开
+func (c *SocketCANClient) dropSocket(sock *canSocket) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if current, ok := c.sockets[sock.ifName]; ok && current == sock {
+		delete(c.sockets, sock.ifName)
+		sock.file.Close()
+	}
+}
+
+// encodeCANFrame 将 RawMessage 编码为内核 struct can_frame 或 struct canfd_frame 布局
+// 两种结构体的前 8 字节布局相同：can_id、长度、FD 标志（经典帧中为填充）
+func encodeCANFrame(msg RawMessage) ([]byte, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	canID := msg.ID & canSFFMask
+	if msg.IsExtended() {
+		canID = (msg.ID & canEFFMask) | canEFFFlag
+	}
+
+	size := canFrameSize
+	if msg.FD {
+		size = canFDFrameSize
+	}
+
+	frame := make([]byte, size)
+	binary.NativeEndian.PutUint32(frame[0:4], canID)
+	frame[4] = byte(len(msg.Data))
+	if msg.FD {
+		frame[5] = canFDFlagFDF
+		if msg.BRS {
+			frame[5] |= canFDFlagBRS
+		}
+	}
+	copy(frame[8:], msg.Data)
+	return frame, nil
+}
+
+// decodeCANFrame 将内核 struct can_frame 或 struct canfd_frame 解码为 RawMessage，按读取长度区分帧类型
+func decodeCANFrame(ifName string, frame []byte) (RawMessage, error) {
+	var fd bool
+	switch len(frame) {
+	case canFrameSize:
+	case canFDFrameSize:
+		fd = true
+	default:
+		return RawMessage{}, fmt.Errorf("帧长度 %d 无效", len(frame))
+	}
+
+	canID := binary.NativeEndian.Uint32(frame[0:4])
+	extended := canID&canEFFFlag != 0
+	if extended {
+		canID &= canEFFMask
+	} else {
+		canID &= canSFFMask
+	}
+
+	msg := RawMessage{Interface: ifName, ID: canID, FD: fd, Extended: extended}
+	if fd {
+		msg.BRS = frame[5]&canFDFlagBRS != 0
+	}
+
+	length := int(frame[4])
+	msg.Data = make([]byte, length)
+	copy(msg.Data, frame[8:min(8+length, len(frame))])
+	if err := msg.Validate(); err != nil {
+		return RawMessage{}, err
+	}
+	return msg, nil
+}
+
+func (c *SocketCANClient) SendMessage(ctx context.Context, msg RawMessage) error {
+	frame, err := encodeCANFrame(msg)
+	if err != nil {
+		return err
+	}
+
+	sock, err := c.getSocket(msg.Interface)
+	if err != nil {
+		return err
+	}
+
+	if msg.FD {
+		if err := checkFDInterface(sock); err != nil {
+			return err
+		}
+	}
+
+	sock.mutex.Lock()
+	defer sock.mutex.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := sock.file.SetWriteDeadline(deadline); err != nil {
+		return fmt.Errorf("设置写超时失败：%w", err)
+	}
+
+	if _, err := sock.file.Write(frame); err != nil {
+		c.dropSocket(sock)
+		return fmt.Errorf("写入 CAN 帧失败：%w", err)
+	}
+
+	return nil
+}
+
+// SendBatch 逐帧写入套接字，帧间按 Delay 等待；没有 HTTP 往返，帧间隔只取决于 Delay
+func (c *SocketCANClient) SendBatch(ctx context.Context, frames []BatchFrame) error {
+	return sendSequentially(ctx, frames, c.SendMessage)
+}
+
+// checkFDInterface 检查套接字和接口是否能发送 FD 帧，接口 MTU 为 72 表示已配置为 CAN FD 模式
+func checkFDInterface(sock *canSocket) error {
+	if !sock.fdEnabled {
+		return fmt.Errorf("CAN 接口 %s 的套接字不支持 FD 帧", sock.ifName)
+	}
+	iface, err := net.InterfaceByName(sock.ifName)
+	if err != nil {
+		return fmt.Errorf("查找 CAN 接口 %s 失败：%w", sock.ifName, err)
+	}
+	if iface.MTU < canFDFrameSize {
+		return fmt.Errorf("CAN 接口 %s 未开启 CAN FD (MTU %d)", sock.ifName, iface.MTU)
+	}
+	return nil
+}
+
+// SupportsFD SocketCAN 可以发送 FD 帧，具体接口是否开启 FD 在发送时检查
+func (c *SocketCANClient) SupportsFD() bool { return true }
+
+func (c *SocketCANClient) GetAllInterfaceStatuses() (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, ifName := range config.Config.AvailableInterfaces {
+		iface, err := net.InterfaceByName(ifName)
+		result[ifName] = err == nil && iface.Flags&net.FlagUp != 0
+	}
+	return result, nil
+}
+
+// SetServiceURL 对 SocketCAN 无意义，保留以满足 Communicator 接口
+func (c *SocketCANClient) SetServiceURL(url string) {}
+
+// IsConnected 只要有一个可用接口处于 UP 状态即认为已连接
+func (c *SocketCANClient) IsConnected() bool {
+	statuses, _ := c.GetAllInterfaceStatuses()
+	for _, up := range statuses {
+		if up {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe 订阅接收到的 CAN 帧，会按需打开过滤条件涉及的接口
+func (c *SocketCANClient) Subscribe(filter FrameFilter) (Subscription, error) {
+	if filter.Interface != "" {
+		if _, err := c.getSocket(filter.Interface); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, ifName := range config.Config.AvailableInterfaces {
+			if _, err := c.getSocket(ifName); err != nil {
+				log.Printf("⚠️ SocketCAN 接口 %s 无法接收: %v", ifName, err)
+			}
+		}
+	}
+	return c.hub.add(filter), nil
+}
+
+// Close 关闭所有已打开的套接字
+func (c *SocketCANClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for ifName, sock := range c.sockets {
+		sock.file.Close()
+		delete(c.sockets, ifName)
+	}
+	c.hub.closeAll()
+	return nil
+}
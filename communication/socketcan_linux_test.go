@@ -0,0 +1,130 @@
+//go:build linux
+
+package communication
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestEncodeCANFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     RawMessage
+		size    int
+		canID   uint32
+		length  byte
+		flags   byte
+		wantErr bool
+	}{
+		{name: "标准帧", msg: RawMessage{ID: 0x27, Data: []byte{0x01, 0xFF}}, size: canFrameSize, canID: 0x27, length: 2},
+		{name: "空数据", msg: RawMessage{ID: 0x7FF}, size: canFrameSize, canID: 0x7FF},
+		{name: "扩展帧", msg: RawMessage{ID: 0x12345678, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}, size: canFrameSize, canID: 0x12345678 | canEFFFlag, length: 8},
+		{name: "ID 较小的扩展帧", msg: RawMessage{ID: 0x27, Extended: true}, size: canFrameSize, canID: 0x27 | canEFFFlag},
+		{name: "FD 帧", msg: RawMessage{ID: 0x28, Data: make([]byte, 12), FD: true}, size: canFDFrameSize, canID: 0x28, length: 12, flags: canFDFlagFDF},
+		{name: "FD 帧带 BRS", msg: RawMessage{ID: 0x28, Data: make([]byte, 64), FD: true, BRS: true}, size: canFDFrameSize, canID: 0x28, length: 64, flags: canFDFlagFDF | canFDFlagBRS},
+		{name: "经典帧超过 8 字节", msg: RawMessage{ID: 0x27, Data: make([]byte, 9)}, wantErr: true},
+		{name: "FD 帧长度无效", msg: RawMessage{ID: 0x27, Data: make([]byte, 9), FD: true}, wantErr: true},
+		{name: "经典帧设置 BRS", msg: RawMessage{ID: 0x27, BRS: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := encodeCANFrame(tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v，期望出错 %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(frame) != tt.size {
+				t.Fatalf("帧大小为 %d，期望 %d", len(frame), tt.size)
+			}
+			if canID := binary.NativeEndian.Uint32(frame[0:4]); canID != tt.canID {
+				t.Fatalf("can_id 为 %08X，期望 %08X", canID, tt.canID)
+			}
+			if frame[4] != tt.length || frame[5] != tt.flags {
+				t.Fatalf("长度 %d 标志 %02X，期望 %d 和 %02X", frame[4], frame[5], tt.length, tt.flags)
+			}
+			if !bytes.Equal(frame[8:8+len(tt.msg.Data)], tt.msg.Data) {
+				t.Fatalf("数据为 % X，期望 % X", frame[8:8+len(tt.msg.Data)], tt.msg.Data)
+			}
+		})
+	}
+}
+
+func TestDecodeCANFrame(t *testing.T) {
+	messages := []RawMessage{
+		{Interface: "can0", ID: 0x27, Data: []byte{0x01, 0x80, 0x80}},
+		{Interface: "can0", ID: 0x1FFFFFFF, Data: []byte{}},
+		{Interface: "can0", ID: 0x27, Data: []byte{0x01}, Extended: true},
+		{Interface: "can0", ID: 0x28, Data: bytes.Repeat([]byte{0xAA}, 48), FD: true, BRS: true},
+	}
+	for _, msg := range messages {
+		frame, err := encodeCANFrame(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeCANFrame("can0", frame)
+		if err != nil {
+			t.Fatalf("解码 %+v 失败: %v", msg, err)
+		}
+		if got.ID != msg.ID || got.IsExtended() != msg.IsExtended() || got.FD != msg.FD || got.BRS != msg.BRS || !bytes.Equal(got.Data, msg.Data) {
+			t.Fatalf("解码得到 %+v，期望 %+v", got, msg)
+		}
+	}
+
+	// 标准帧只保留 11 位 ID
+	frame := make([]byte, canFrameSize)
+	binary.NativeEndian.PutUint32(frame[0:4], 0x12345)
+	if got, err := decodeCANFrame("can0", frame); err != nil || got.ID != 0x345 {
+		t.Fatalf("得到 %+v %v，期望 ID 345", got, err)
+	}
+
+	if _, err := decodeCANFrame("can0", make([]byte, 20)); err == nil {
+		t.Fatal("帧长度无效时应返回错误")
+	}
+	frame[4] = 9
+	if _, err := decodeCANFrame("can0", frame); err == nil {
+		t.Fatal("经典帧长度超过 8 字节时应返回错误")
+	}
+}
+
+// TestSocketCANVcan 在 vcan0 上收发一帧，没有 vcan0 时跳过：
+//
+//	ip link add dev vcan0 type vcan && ip link set up vcan0
+func TestSocketCANVcan(t *testing.T) {
+	if iface, err := net.InterfaceByName("vcan0"); err != nil || iface.Flags&net.FlagUp == 0 {
+		t.Skip("vcan0 不存在或未启用")
+	}
+
+	receiver, _ := NewSocketCANClient()
+	defer receiver.(*SocketCANClient).Close()
+	sender, _ := NewSocketCANClient()
+	defer sender.(*SocketCANClient).Close()
+
+	sub, err := receiver.Subscribe(FrameFilter{Interface: "vcan0", IDs: []uint32{0x27}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	msg := RawMessage{Interface: "vcan0", ID: 0x27, Data: []byte{0x01, 10, 20, 30, 40, 50, 60}}
+	if err := sender.SendMessage(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-sub.Frames():
+		if got.Interface != "vcan0" || got.ID != msg.ID || !bytes.Equal(got.Data, msg.Data) {
+			t.Fatalf("收到 %+v，期望 %+v", got, msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 vcan0 上的帧")
+	}
+}
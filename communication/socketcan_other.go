@@ -0,0 +1,9 @@
+//go:build !linux
+
+package communication
+
+import "fmt"
+
+func NewSocketCANClient() (Communicator, error) {
+	return nil, fmt.Errorf("SocketCAN 仅支持 Linux 平台")
+}
@@ -0,0 +1,130 @@
+package communication
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultSubscriptionBuffer 订阅通道的默认缓冲大小
+const defaultSubscriptionBuffer = 64
+
+// FrameFilter 定义订阅接收帧时的过滤条件，零值字段表示不过滤
+type FrameFilter struct {
+	Interface string   // 只接收该接口上的帧，为空表示所有接口
+	IDs       []uint32 // 只接收这些 CAN ID 的帧，为空表示所有 ID
+}
+
+// Match 判断帧是否满足过滤条件
+func (f FrameFilter) Match(msg RawMessage) bool {
+	if f.Interface != "" && f.Interface != msg.Interface {
+		return false
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, msg.ID) {
+		return false
+	}
+	return true
+}
+
+// Subscription 代表一个接收帧的订阅
+type Subscription interface {
+	// Frames 返回接收帧的通道，订阅关闭后通道被关闭
+	Frames() <-chan RawMessage
+
+	// Dropped 返回因消费过慢而被丢弃的帧数量
+	Dropped() uint64
+
+	// Close 取消订阅
+	Close()
+}
+
+// frameSubscription 是 Subscription 的默认实现
+type frameSubscription struct {
+	filter  FrameFilter
+	frames  chan RawMessage
+	dropped atomic.Uint64
+	hub     *subscriberHub
+	once    sync.Once
+}
+
+func (s *frameSubscription) Frames() <-chan RawMessage { return s.frames }
+
+func (s *frameSubscription) Dropped() uint64 { return s.dropped.Load() }
+
+func (s *frameSubscription) Close() { s.once.Do(func() { s.hub.remove(s) }) }
+
+// subscriberHub 管理一组订阅者并向其分发接收到的帧，供各 Communicator 实现复用
+type subscriberHub struct {
+	subscribers map[*frameSubscription]struct{}
+	onIdle      func() // 最后一个订阅者退出时调用，可为空
+	mutex       sync.RWMutex
+}
+
+func newSubscriberHub() *subscriberHub {
+	return &subscriberHub{subscribers: make(map[*frameSubscription]struct{})}
+}
+
+// add 注册一个新的订阅
+func (h *subscriberHub) add(filter FrameFilter) *frameSubscription {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	sub := &frameSubscription{
+		filter: filter,
+		frames: make(chan RawMessage, defaultSubscriptionBuffer),
+		hub:    h,
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// remove 移除订阅并关闭其通道
+func (h *subscriberHub) remove(sub *frameSubscription) {
+	h.mutex.Lock()
+	idle := false
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.frames)
+		idle = len(h.subscribers) == 0
+	}
+	h.mutex.Unlock()
+
+	if idle && h.onIdle != nil {
+		h.onIdle()
+	}
+}
+
+// count 返回当前订阅者数量
+func (h *subscriberHub) count() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.subscribers)
+}
+
+// dispatch 将帧分发给所有匹配的订阅者，消费过慢的订阅者会丢帧而不会阻塞接收
+func (h *subscriberHub) dispatch(msg RawMessage) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for sub := range h.subscribers {
+		if !sub.filter.Match(msg) {
+			continue
+		}
+		select {
+		case sub.frames <- msg:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// closeAll 关闭所有订阅
+func (h *subscriberHub) closeAll() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		close(sub.frames)
+	}
+}
@@ -3,6 +3,7 @@ package component
 import (
 	"hands/device"
 	"math/rand/v2"
+	"sync"
 	"time"
 )
 
@@ -16,16 +17,26 @@ type Sensor interface {
 	MockData()
 }
 
+// FeedbackSensor 可接收设备反馈帧更新的传感器
+type FeedbackSensor interface {
+	// ApplyFingerFeedback 使用设备上报的手指实际位置更新数据
+	ApplyFingerFeedback(pose []byte)
+	// ApplyPalmFeedback 使用设备上报的手掌实际位置更新数据
+	ApplyPalmFeedback(pose []byte)
+}
+
 // SensorDataImpl 传感器数据的具体实现
 type SensorDataImpl struct {
-	Interface    string    `json:"interface"`
-	Thumb        int       `json:"thumb"`
-	Index        int       `json:"index"`
-	Middle       int       `json:"middle"`
-	Ring         int       `json:"ring"`
-	Pinky        int       `json:"pinky"`
-	PalmPosition []byte    `json:"palmPosition"`
-	LastUpdate   time.Time `json:"lastUpdate"`
+	Interface      string       `json:"interface"`
+	Thumb          int          `json:"thumb"`
+	Index          int          `json:"index"`
+	Middle         int          `json:"middle"`
+	Ring           int          `json:"ring"`
+	Pinky          int          `json:"pinky"`
+	FingerPosition []byte       `json:"fingerPosition"`
+	PalmPosition   []byte       `json:"palmPosition"`
+	LastUpdate     time.Time    `json:"lastUpdate"`
+	mutex          sync.RWMutex // 保护数据字段，模拟数据与设备反馈会并发写入
 }
 
 func NewSensorData(ifName string) *SensorDataImpl {
@@ -44,26 +55,45 @@ func NewSensorData(ifName string) *SensorDataImpl {
 func (s *SensorDataImpl) MockData() {
 	go func() {
 		for {
+			s.mutex.Lock()
 			s.Thumb = rand.IntN(101)
 			s.Index = rand.IntN(101)
 			s.Middle = rand.IntN(101)
 			s.Ring = rand.IntN(101)
 			s.Pinky = rand.IntN(101)
 			s.LastUpdate = time.Now()
+			s.mutex.Unlock()
 			time.Sleep(500 * time.Millisecond)
 		}
 	}()
 }
 
+func (s *SensorDataImpl) ApplyFingerFeedback(pose []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.FingerPosition = append([]byte(nil), pose...)
+	s.LastUpdate = time.Now()
+}
+
+func (s *SensorDataImpl) ApplyPalmFeedback(pose []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.PalmPosition = append([]byte(nil), pose...)
+	s.LastUpdate = time.Now()
+}
+
 func (s *SensorDataImpl) Values() map[string]any {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return map[string]any{
-		"thumb":        s.Thumb,
-		"index":        s.Index,
-		"middle":       s.Middle,
-		"ring":         s.Ring,
-		"pinky":        s.Pinky,
-		"palmPosition": s.PalmPosition,
-		"lastUpdate":   s.LastUpdate,
+		"thumb":          s.Thumb,
+		"index":          s.Index,
+		"middle":         s.Middle,
+		"ring":           s.Ring,
+		"pinky":          s.Pinky,
+		"fingerPosition": s.FingerPosition,
+		"palmPosition":   s.PalmPosition,
+		"lastUpdate":     s.LastUpdate,
 	}
 }
 
@@ -1,11 +1,34 @@
 package define
 
+import "time"
+
 // 配置结构体
 type Config struct {
 	CanServiceURL       string
+	Transport           string // 默认设备使用的传输方式，"can-bridge"、"socketcan" 或 "sim"
 	WebPort             string
 	DefaultInterface    string
 	AvailableInterfaces []string
+
+	// can-bridge 发送的重试与熔断配置
+	SendRetries        int           // 幂等帧发送失败后的最大重试次数
+	RetryBaseDelay     time.Duration // 重试退避的基础间隔
+	BreakerThreshold   int           // 连续失败多少次后熔断，0 表示不熔断
+	BreakerOpenTimeout time.Duration // 熔断后多久尝试恢复
+
+	RecordDir string // CAN 流量录制文件 (candump -l 格式) 的存放目录
+
+	DeviceStore string // 设备注册表文件路径，为空时不持久化设备
+
+	ModelDir string // 型号描述文件 (*.json) 所在目录，启动时注册其中定义的型号
+
+	HealthInterval time.Duration // 通信服务和接口健康检查的轮询间隔
+
+	// 按接口的发送限速与总线负载估算
+	RateLimit   float64 // 每个接口每秒最多发送的帧数，0 表示不限速
+	RateBurst   int     // 限速令牌桶容量
+	Bitrate     int     // CAN 总线仲裁段波特率
+	DataBitrate int     // CAN FD 数据段波特率
 }
 
 // API 响应结构体
@@ -25,3 +25,11 @@ func HandTypeFromString(s string) HandType {
 		return HAND_TYPE_UNKNOWN
 	}
 }
+
+// Key 返回配置和 API 中使用的手型字符串，与 HandTypeFromString 互逆
+func (ht HandType) Key() string {
+	if ht == HAND_TYPE_LEFT {
+		return "left"
+	}
+	return "right"
+}
@@ -0,0 +1,116 @@
+package device
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// JointCapability 单个关节的名称和取值范围
+type JointCapability struct {
+	Name          string  `json:"name"`
+	Min           byte    `json:"min"`           // 允许的最小原始值，设备配置了 joint_limits 时为配置的范围
+	Max           byte    `json:"max"`           // 允许的最大原始值
+	OpenDegrees   float64 `json:"openDegrees"`   // 完全张开时的角度，没有标定时为 0
+	ClosedDegrees float64 `json:"closedDegrees"` // 完全闭合时的角度，没有标定时为 0
+}
+
+// Capabilities 型号或设备实例的能力描述，客户端据此适配界面，API 据此校验请求
+type Capabilities struct {
+	Model      string            `json:"model"`
+	Finger     []JointCapability `json:"finger"`
+	Palm       []JointCapability `json:"palm"`
+	Commands   []string          `json:"commands"`   // 支持的指令类型，如 "SetFingerPose"
+	Units      []PoseUnit        `json:"units"`      // 支持的姿态单位
+	Presets    []string          `json:"presets"`    // 支持的预设姿势
+	Animations []string          `json:"animations"` // 支持的动画
+}
+
+// NewCapabilities 由各关节组的关节数和关节标定表生成能力描述，关节范围为 0-255
+// 标定表中没有的关节以 "finger[0]" 的形式命名，标定表为空时只支持 raw 单位
+func NewCapabilities(model string, joints map[JointGroup]int, calibration JointCalibrationTable) Capabilities {
+	describe := func(group JointGroup) []JointCapability {
+		table := calibration.Joints(group)
+		result := make([]JointCapability, joints[group])
+		for i := range result {
+			result[i] = JointCapability{Name: fmt.Sprintf("%s[%d]", group, i), Min: 0, Max: 255}
+			if i < len(table) {
+				result[i].Name = table[i].Name
+				result[i].OpenDegrees = table[i].OpenDegrees
+				result[i].ClosedDegrees = table[i].ClosedDegrees
+			}
+		}
+		return result
+	}
+
+	units := []PoseUnit{UnitRaw}
+	if len(calibration.Finger) > 0 {
+		units = append(units, UnitPercent, UnitDegrees)
+	}
+
+	return Capabilities{
+		Model:  model,
+		Finger: describe(JointGroupFinger),
+		Palm:   describe(JointGroupPalm),
+		Units:  units,
+	}
+}
+
+// Joints 返回关节组的关节描述
+func (c Capabilities) Joints(group JointGroup) []JointCapability {
+	switch group {
+	case JointGroupFinger:
+		return c.Finger
+	case JointGroupPalm:
+		return c.Palm
+	}
+	return nil
+}
+
+// WithLimits 返回关节范围替换为 limits 的副本，limits 为空时不修改
+func (c Capabilities) WithLimits(group JointGroup, limits []JointLimit) Capabilities {
+	joints := slices.Clone(c.Joints(group))
+	for i := range min(len(joints), len(limits)) {
+		joints[i].Min, joints[i].Max = limits[i].Min, limits[i].Max
+	}
+	switch group {
+	case JointGroupFinger:
+		c.Finger = joints
+	case JointGroupPalm:
+		c.Palm = joints
+	}
+	return c
+}
+
+// ValidatePose 检查姿态的长度与关节组的关节数一致，不一致时返回 ErrInvalidPose
+func (c Capabilities) ValidatePose(group JointGroup, pose []byte) error {
+	if n := len(c.Joints(group)); len(pose) != n {
+		return fmt.Errorf("%w：%s 的 %s 姿态需要 %d 个字节，收到 %d 个", ErrInvalidPose, c.Model, group, n, len(pose))
+	}
+	return nil
+}
+
+// SupportsCommand 判断是否支持指令类型
+func (c Capabilities) SupportsCommand(cmdType string) bool {
+	return slices.Contains(c.Commands, cmdType)
+}
+
+var (
+	modelCapabilities      = make(map[string]Capabilities)
+	modelCapabilitiesMutex sync.RWMutex
+)
+
+// RegisterModelCapabilities 注册型号的能力描述，与 RegisterDeviceType 一起调用
+func RegisterModelCapabilities(model string, capabilities Capabilities) {
+	modelCapabilitiesMutex.Lock()
+	defer modelCapabilitiesMutex.Unlock()
+	modelCapabilities[model] = capabilities
+}
+
+// GetModelCapabilities 获取型号的能力描述，与具体设备的配置无关
+func GetModelCapabilities(model string) (Capabilities, bool) {
+	modelCapabilitiesMutex.RLock()
+	defer modelCapabilitiesMutex.RUnlock()
+	capabilities, ok := modelCapabilities[model]
+	return capabilities, ok
+}
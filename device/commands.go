@@ -22,6 +22,33 @@ func (c *PalmPoseCommand) Type() string { return "SetPalmPose" }
 
 func (c *PalmPoseCommand) Payload() []byte { return c.poseData }
 
+// FullPoseCommand 手指、手掌姿态和手指速度的组合指令，需要一帧 CAN FD 发送
+type FullPoseCommand struct {
+	fingerPose []byte
+	palmPose   []byte
+	speed      []byte
+}
+
+func NewFullPoseCommand(fingerPose, palmPose, speed []byte) *FullPoseCommand {
+	return &FullPoseCommand{fingerPose: fingerPose, palmPose: palmPose, speed: speed}
+}
+
+func (c *FullPoseCommand) Type() string { return "SetFullPose" }
+
+// Payload 依次为手指姿态、手掌姿态和手指速度
+func (c *FullPoseCommand) Payload() []byte {
+	payload := make([]byte, 0, len(c.fingerPose)+len(c.palmPose)+len(c.speed))
+	payload = append(payload, c.fingerPose...)
+	payload = append(payload, c.palmPose...)
+	return append(payload, c.speed...)
+}
+
+func (c *FullPoseCommand) FingerPose() []byte { return c.fingerPose }
+
+func (c *FullPoseCommand) PalmPose() []byte { return c.palmPose }
+
+func (c *FullPoseCommand) Speed() []byte { return c.speed }
+
 // GenericCommand 通用指令
 type GenericCommand struct {
 	cmdType string
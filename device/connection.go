@@ -0,0 +1,33 @@
+package device
+
+import "slices"
+
+// ConnectionState 设备连接状态
+type ConnectionState string
+
+const (
+	StateDisconnected ConnectionState = "disconnected" // 已主动断开，不发送指令也不自动重连
+	StateConnecting   ConnectionState = "connecting"   // 正在检查接口并与设备握手
+	StateConnected    ConnectionState = "connected"    // 接口可用且设备有应答
+	StateDegraded     ConnectionState = "degraded"     // 接口可用但设备未应答或发送出错，仍允许发送指令，后台继续重连
+	StateFaulted      ConnectionState = "faulted"      // 接口或通信服务不可用，拒绝指令，后台按退避间隔重连
+)
+
+// connectionTransitions 允许的状态转换
+var connectionTransitions = map[ConnectionState][]ConnectionState{
+	StateDisconnected: {StateConnecting},
+	StateConnecting:   {StateConnected, StateDegraded, StateFaulted, StateDisconnected},
+	StateConnected:    {StateConnecting, StateDegraded, StateFaulted, StateDisconnected},
+	StateDegraded:     {StateConnecting, StateConnected, StateFaulted, StateDisconnected},
+	StateFaulted:      {StateConnecting, StateDisconnected},
+}
+
+// CanTransitionTo 判断是否允许从 s 转换到 next
+func (s ConnectionState) CanTransitionTo(next ConnectionState) bool {
+	return slices.Contains(connectionTransitions[s], next)
+}
+
+// AcceptsCommands 判断处于该状态的设备是否可以发送指令
+func (s ConnectionState) AcceptsCommands() bool {
+	return s == StateConnected || s == StateDegraded
+}
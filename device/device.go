@@ -18,6 +18,7 @@ type Device interface {
 	GetStatus() (DeviceStatus, error)                      // 获取设备状态
 	Connect() error                                        // 连接设备
 	Disconnect() error                                     // 断开设备连接
+	GetCapabilities() Capabilities                         // 获取设备的能力描述：关节、范围、指令、预设和动画
 
 	// --- 新增 ---
 	PoseExecutor                          // 嵌入 PoseExecutor 接口，Device 需实现它
@@ -30,6 +31,23 @@ type Device interface {
 	GetPresetDetails(presetName string) (PresetPose, bool) // 获取预设姿势详细信息
 }
 
+// CANFDDevice 由可能需要 CAN FD 的设备型号实现
+// RequiresCANFD 返回 true 时设备发送 FD 帧，通信客户端必须支持 CAN FD
+type CANFDDevice interface {
+	RequiresCANFD() bool
+}
+
+// FilterableDevice 由支持姿态滤波管道的设备型号实现，滤波器可以在运行时替换
+type FilterableDevice interface {
+	GetPoseFilters() []FilterConfig
+	SetPoseFilters(configs []FilterConfig) error
+}
+
+// ClosableDevice 由持有后台资源（发送 goroutine、帧订阅、定时器）的设备实现，从管理器删除时调用 Close 释放
+type ClosableDevice interface {
+	Close() error
+}
+
 // Command 代表一个发送给设备的指令
 type Command interface {
 	Type() string    // 指令类型，例如 "SetFingerPose", "SetPalmAngle"
@@ -62,9 +80,28 @@ type Component interface {
 
 // DeviceStatus 代表设备状态
 type DeviceStatus struct {
-	IsConnected bool
-	IsActive    bool
-	LastUpdate  time.Time
-	ErrorCount  int
-	LastError   string
+	State             ConnectionState // 连接状态，IsConnected 和 IsActive 由它派生
+	ReconnectAttempts int             // 当前这轮自动重连已尝试的次数
+	IsConnected       bool
+	IsActive          bool
+	LastUpdate        time.Time
+	LastFeedback      time.Time // 最近一次收到设备反馈帧的时间
+	ErrorCount        int
+	LastError         string
+
+	// 配置了多个 can-bridge 时的故障转移状态
+	ActiveBridge      string    // 当前使用的 can-bridge URL
+	BridgeSwitchovers int       // 累计切换次数
+	LastSwitchover    string    // 最近一次切换的描述，如 "http://a -> http://b: 原因"
+	LastSwitchoverAt  time.Time // 最近一次切换的时间
+
+	Limits LimitStats // 关节限制配置和违规计数
+	Pose   PoseValues // 最近一次发送的姿态，单位为 raw
+	Queue  QueueStats // 指令队列的深度、计数和延迟
+
+	// 急停锁定期间拒绝所有姿态指令，直到解除急停
+	EmergencyStop   bool      // 是否处于急停锁定
+	EmergencyStopAt time.Time // 急停的时间
+
+	Watchdog WatchdogStatus // 控制会话 watchdog 的配置、当前会话和心跳超时次数
 }
@@ -54,15 +54,32 @@ func (e *AnimationEngine) getAnimation(name string) (Animation, bool) {
 	return anim, exists
 }
 
-// getDeviceName 尝试获取设备 ID 用于日志记录
-func (e *AnimationEngine) getDeviceName() string {
+// getDeviceID 尝试获取设备 ID 用于事件，执行器没有 ID 时返回空
+func (e *AnimationEngine) getDeviceID() string {
 	// 尝试通过接口断言获取 ID
 	if idProvider, ok := e.executor.(interface{ GetID() string }); ok {
 		return idProvider.GetID()
 	}
+	return ""
+}
+
+// getDeviceName 尝试获取设备 ID 用于日志记录
+func (e *AnimationEngine) getDeviceName() string {
+	if id := e.getDeviceID(); id != "" {
+		return id
+	}
 	return "设备" // 默认名称
 }
 
+// executorFor 返回执行动画 name 的执行器：设备记录指令来源时，动画帧以动画名称为来源通过 animation 通道提交，
+// API 指令优先
+func (e *AnimationEngine) executorFor(name string) PoseExecutor {
+	if d, ok := e.executor.(OriginDevice); ok {
+		return d.WithOrigin(CommandOrigin{Source: SourceAnimation, Detail: name, Priority: PriorityAnimation})
+	}
+	return e.executor
+}
+
 // Start 启动一个动画
 func (e *AnimationEngine) Start(name string, speedMs int) error {
 	e.engineMutex.Lock()
@@ -94,6 +111,11 @@ func (e *AnimationEngine) Start(name string, speedMs int) error {
 	}
 
 	log.Printf("🚀 准备启动动画 %s (设备: %s, 速度: %dms)", name, e.getDeviceName(), actualSpeedMs)
+	PublishEvent(Event{
+		Type:      EventAnimationStarted,
+		DeviceID:  e.getDeviceID(),
+		Animation: &AnimationChange{Name: name, SpeedMs: actualSpeedMs},
+	})
 
 	// 启动动画 goroutine
 	go e.runAnimationLoop(anim, e.stopChan, actualSpeedMs)
@@ -151,9 +173,11 @@ func (e *AnimationEngine) GetCurrentAnimation() string {
 func (e *AnimationEngine) runAnimationLoop(anim Animation, stopChan <-chan struct{}, speedMs int) {
 	deviceName := e.getDeviceName()
 	animName := anim.Name()
+	executor := e.executorFor(animName)
 
 	// 使用 defer 确保无论如何都能执行清理逻辑
-	defer e.handleLoopExit(stopChan, deviceName, animName)
+	var runErr error
+	defer func() { e.handleLoopExit(executor, stopChan, deviceName, animName, runErr) }()
 
 	log.Printf("▶️ %s 动画 %s 已启动", deviceName, animName)
 
@@ -165,9 +189,8 @@ func (e *AnimationEngine) runAnimationLoop(anim Animation, stopChan <-chan struc
 			return // 接收到停止信号，退出循环
 		default:
 			// 执行一轮动画
-			err := anim.Run(e.executor, stopChan, speedMs)
-			if err != nil {
-				log.Printf("❌ %s 动画 %s 执行出错: %v", deviceName, animName, err)
+			if runErr = anim.Run(executor, stopChan, speedMs); runErr != nil {
+				log.Printf("❌ %s 动画 %s 执行出错: %v", deviceName, animName, runErr)
 				return // 出错则退出
 			}
 
@@ -183,11 +206,21 @@ func (e *AnimationEngine) runAnimationLoop(anim Animation, stopChan <-chan struc
 	}
 }
 
-// handleLoopExit 是动画 Goroutine 退出时执行的清理函数。
-func (e *AnimationEngine) handleLoopExit(stopChan <-chan struct{}, deviceName, animName string) {
+// handleLoopExit 是动画 Goroutine 退出时执行的清理函数，runErr 为动画出错退出的原因。
+func (e *AnimationEngine) handleLoopExit(executor PoseExecutor, stopChan <-chan struct{}, deviceName, animName string, runErr error) {
 	e.engineMutex.Lock()
 	defer e.engineMutex.Unlock()
 
+	event := Event{
+		Type:      EventAnimationStopped,
+		DeviceID:  e.getDeviceID(),
+		Animation: &AnimationChange{Name: animName, Replaced: stopChan != e.stopChan},
+	}
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+	defer PublishEvent(event)
+
 	// --- 关键并发控制 ---
 	// 检查当前引擎的 stopChan 是否与此 Goroutine 启动时的 stopChan 相同。
 	// 如果不相同，说明一个新的动画已经启动，并且接管了引擎状态。
@@ -198,7 +231,7 @@ func (e *AnimationEngine) handleLoopExit(stopChan <-chan struct{}, deviceName, a
 		e.isRunning = false
 		e.current = ""
 		log.Printf("👋 %s 动画 %s 已完成或停止，正在重置姿态...", deviceName, animName)
-		if err := e.executor.ResetPose(); err != nil {
+		if err := executor.ResetPose(); err != nil {
 			log.Printf("⚠️ %s 动画结束后重置姿态失败: %v", deviceName, err)
 		} else {
 			log.Printf("✅ %s 姿态已重置", deviceName)
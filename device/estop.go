@@ -0,0 +1,177 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hands/communication"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrEmergencyStop 设备处于急停锁定状态，拒绝所有非紧急指令
+var ErrEmergencyStop = errors.New("设备已急停")
+
+// EmergencyStopper 由支持急停的设备实现
+type EmergencyStopper interface {
+	// EmergencyStop 停止动画并锁定设备，再以 emergency 优先级驱动到安全姿态；发送失败时锁定仍然生效
+	EmergencyStop(origin CommandOrigin) error
+	// ReleaseEmergencyStop 解除锁定，设备停留在安全姿态
+	ReleaseEmergencyStop()
+}
+
+// SafePose 急停时驱动到的安全姿态，来自设备配置的 safe_pose，未配置的关节组使用型号的默认姿态：
+//
+//	"safe_pose": {"finger": [64, 64, 64, 64, 64, 64], "palm": [128, 128, 128, 128]}
+type SafePose struct {
+	Finger []byte `json:"finger,omitempty"`
+	Palm   []byte `json:"palm,omitempty"`
+}
+
+// SafePoseFromConfig 解析设备配置中的 safe_pose，没有配置时返回空的 SafePose，长度由设备按能力描述校验
+func SafePoseFromConfig(config map[string]any) (SafePose, error) {
+	var pose SafePose
+	raw, ok := config["safe_pose"]
+	if !ok || raw == nil {
+		return pose, nil
+	}
+
+	// 配置可能来自 JSON 请求或注册表，统一经过一次 JSON 编解码
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return pose, fmt.Errorf("无效的 safe_pose 配置：%w", err)
+	}
+	if err := json.Unmarshal(data, &pose); err != nil {
+		return pose, fmt.Errorf("无效的 safe_pose 配置：%w", err)
+	}
+	return pose, nil
+}
+
+// EmergencyStopStatus 系统的急停状态
+type EmergencyStopStatus struct {
+	Latched bool                `json:"latched"`           // 是否处于急停锁定
+	Since   time.Time           `json:"since,omitzero"`    // 急停的时间
+	Client  string              `json:"client,omitempty"`  // 触发急停的客户端地址
+	Results []GroupMemberResult `json:"results,omitempty"` // 最近一次急停或解除时各设备的结果，按设备 ID 排序
+}
+
+// EmergencyStopState 返回系统的急停状态
+func (m *DeviceManager) EmergencyStopState() EmergencyStopStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := m.estop
+	status.Results = slices.Clone(status.Results)
+	return status
+}
+
+// EmergencyStop 急停所有设备：停止 CAN 回放，并发地停止动画、锁定并驱动到安全姿态，返回各设备的结果
+// 锁定一直保持到 ReleaseEmergencyStop，期间创建或恢复的设备同样被锁定，也不能开始新的回放
+func (m *DeviceManager) EmergencyStop(origin CommandOrigin) EmergencyStopStatus {
+	m.mutex.Lock()
+	if !m.estop.Latched {
+		m.estop = EmergencyStopStatus{Latched: true, Since: time.Now(), Client: origin.Client}
+	}
+	devices := make([]Device, 0, len(m.devices))
+	for _, dev := range m.devices {
+		devices = append(devices, dev)
+	}
+	m.mutex.Unlock()
+
+	// 回放直接经通信客户端发送帧，不经过设备的指令队列，必须在驱动到安全姿态之前停止
+	replayer := communication.DefaultReplayer()
+	if replay := replayer.Status(); replay.Running {
+		log.Printf("🛑 急停：正在停止回放 %s", replay.File)
+	}
+	replayer.Stop()
+
+	log.Printf("🛑 急停：正在锁定 %d 个设备并驱动到安全姿态", len(devices))
+	results := forEachDevice(devices, func(dev Device) error {
+		return emergencyStopDevice(dev, origin)
+	})
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.estop.Results = results
+	status := m.estop
+	status.Results = slices.Clone(results)
+	return status
+}
+
+// ReleaseEmergencyStop 解除所有设备的急停锁定，没有急停时不做任何操作
+func (m *DeviceManager) ReleaseEmergencyStop() EmergencyStopStatus {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.estop.Latched {
+		return EmergencyStopStatus{}
+	}
+
+	devices := make([]Device, 0, len(m.devices))
+	for _, dev := range m.devices {
+		devices = append(devices, dev)
+	}
+	results := forEachDevice(devices, func(dev Device) error {
+		stopper, ok := dev.(EmergencyStopper)
+		if !ok {
+			return fmt.Errorf("设备 %s (%s) 不支持急停", dev.GetID(), dev.GetModel())
+		}
+		stopper.ReleaseEmergencyStop()
+		return nil
+	})
+
+	m.estop = EmergencyStopStatus{Results: results}
+	log.Printf("✅ 急停已解除 (%d 个设备)", len(devices))
+	return m.estop
+}
+
+// latchNewDeviceLocked 急停锁定期间加入的设备同样锁定，调用方需持有 m.mutex
+// 新设备尚未完成握手，驱动到安全姿态会立即失败，只保留锁定
+func (m *DeviceManager) latchNewDeviceLocked(dev Device) {
+	if !m.estop.Latched {
+		return
+	}
+	if err := emergencyStopDevice(dev, CommandOrigin{Source: SourceDevice, Detail: "estop"}); err != nil {
+		log.Printf("ℹ️ 设备 %s 在急停期间加入，已锁定: %v", dev.GetID(), err)
+	}
+}
+
+// emergencyStopDevice 停止设备的动画并急停；不支持急停的设备只停止动画并重置姿态，返回错误表示无法锁定
+func emergencyStopDevice(dev Device, origin CommandOrigin) error {
+	if stopper, ok := dev.(EmergencyStopper); ok {
+		return stopper.EmergencyStop(origin)
+	}
+
+	if err := dev.GetAnimationEngine().Stop(); err != nil {
+		log.Printf("⚠️ 设备 %s 停止动画失败: %v", dev.GetID(), err)
+	}
+	if err := dev.ResetPose(); err != nil {
+		log.Printf("⚠️ 设备 %s 重置姿态失败: %v", dev.GetID(), err)
+	}
+	return fmt.Errorf("设备 %s (%s) 不支持急停锁定", dev.GetID(), dev.GetModel())
+}
+
+// forEachDevice 并发地在每个设备上执行 op，按设备 ID 排序返回结果
+func forEachDevice(devices []Device, op func(Device) error) []GroupMemberResult {
+	results := make([]GroupMemberResult, len(devices))
+	var wg sync.WaitGroup
+	for i, dev := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].DeviceID = dev.GetID()
+			if err := op(dev); err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Success = true
+		}()
+	}
+	wg.Wait()
+
+	slices.SortFunc(results, func(a, b GroupMemberResult) int { return strings.Compare(a.DeviceID, b.DeviceID) })
+	return results
+}
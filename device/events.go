@@ -0,0 +1,155 @@
+package device
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventBuffer 订阅通道的默认缓冲大小
+const DefaultEventBuffer = 64
+
+// 设备事件类型
+const (
+	EventDeviceAdded           = "device_added"      // 设备创建、恢复或注册到管理器
+	EventDeviceRemoved         = "device_removed"    // 设备从管理器删除
+	EventStateChanged          = "state_changed"     // 连接状态变化
+	EventCommand               = "command"           // 一个动作有了结果，与指令历史中的记录相同
+	EventAnimationStarted      = "animation_started" // 动画启动
+	EventAnimationStopped      = "animation_stopped" // 动画 goroutine 退出：被停止、被新动画取代或出错
+	EventHandTypeChanged       = "hand_type_changed" // 手型变化
+	EventError                 = "error"             // 设备错误计数增加
+	EventEmergencyStop         = "estop"             // 设备被急停锁定
+	EventEmergencyStopReleased = "estop_released"    // 设备的急停锁定解除
+	EventWatchdogTrip          = "watchdog_trip"     // 控制会话心跳超时，设备驱动到安全姿态
+)
+
+// StateChange 连接状态变化
+type StateChange struct {
+	From   ConnectionState `json:"from"`
+	To     ConnectionState `json:"to"`
+	Reason string          `json:"reason,omitempty"`
+}
+
+// AnimationChange 动画启动或退出
+type AnimationChange struct {
+	Name     string `json:"name"`
+	SpeedMs  int    `json:"speedMs,omitempty"`  // 仅启动事件
+	Replaced bool   `json:"replaced,omitempty"` // 退出事件：被新启动的动画取代，没有重置姿态
+}
+
+// Event 一个设备事件，Type 决定哪个字段有值
+type Event struct {
+	Type      string    `json:"type"`
+	DeviceID  string    `json:"deviceId"`
+	Timestamp time.Time `json:"timestamp"`
+
+	Model     string           `json:"model,omitempty"`     // EventDeviceAdded、EventDeviceRemoved
+	State     *StateChange     `json:"state,omitempty"`     // EventStateChanged
+	Command   *HistoryEntry    `json:"command,omitempty"`   // EventCommand
+	Animation *AnimationChange `json:"animation,omitempty"` // EventAnimationStarted、EventAnimationStopped
+	HandType  string           `json:"handType,omitempty"`  // EventHandTypeChanged："left" 或 "right"
+	Client    string           `json:"client,omitempty"`    // EventEmergencyStop：触发急停的客户端地址
+	Error     string           `json:"error,omitempty"`     // EventError；动画出错退出、急停或 watchdog 驱动到安全姿态失败时的原因
+}
+
+// EventFilter 订阅条件，零值表示接收所有事件
+type EventFilter struct {
+	DeviceID string   // 只接收该设备的事件
+	Types    []string // 只接收这些类型的事件
+}
+
+// matches 判断事件是否满足条件
+func (f EventFilter) matches(event *Event) bool {
+	return (f.DeviceID == "" || event.DeviceID == f.DeviceID) &&
+		(len(f.Types) == 0 || slices.Contains(f.Types, event.Type))
+}
+
+// EventSubscription 设备事件订阅
+type EventSubscription struct {
+	bus     *EventBus
+	filter  EventFilter
+	events  chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Events 返回事件通道，订阅关闭后通道被关闭
+func (s *EventSubscription) Events() <-chan Event { return s.events }
+
+// Dropped 返回因消费过慢而被丢弃的事件数量
+func (s *EventSubscription) Dropped() uint64 { return s.dropped.Load() }
+
+// Close 取消订阅
+func (s *EventSubscription) Close() { s.once.Do(func() { s.bus.remove(s) }) }
+
+// EventBus 向订阅者分发设备事件。发布从不阻塞：订阅者的通道已满时丢弃事件并计数，
+// 因此可以在持有设备锁时发布
+type EventBus struct {
+	subscribers map[*EventSubscription]struct{}
+	mutex       sync.RWMutex
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[*EventSubscription]struct{})}
+}
+
+// Subscribe 订阅满足 filter 的事件，buffer <= 0 时使用 DefaultEventBuffer
+func (b *EventBus) Subscribe(filter EventFilter, buffer int) *EventSubscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	sub := &EventSubscription{bus: b, filter: filter, events: make(chan Event, buffer)}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *EventBus) remove(sub *EventSubscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Publish 分发事件，Timestamp 为零时使用当前时间
+func (b *EventBus) Publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.matches(&event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// deviceEvents 设备、动画引擎和设备管理器共享的事件总线
+var deviceEvents = NewEventBus()
+
+// SubscribeEvents 订阅所有设备的事件，buffer <= 0 时使用 DefaultEventBuffer
+func SubscribeEvents(filter EventFilter, buffer int) *EventSubscription {
+	return deviceEvents.Subscribe(filter, buffer)
+}
+
+// PublishEvent 向所有订阅者发布设备事件
+func PublishEvent(event Event) {
+	deviceEvents.Publish(event)
+}
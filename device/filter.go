@@ -0,0 +1,265 @@
+package device
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// 姿态滤波器类型
+const (
+	FilterJitter      = "jitter"      // 随机扰动，可指定种子以便复现
+	FilterClamp       = "clamp"       // 限制到 [min, max]
+	FilterCalibration = "calibration" // 按关节线性校准：value*scale + offset
+	FilterSmoothing   = "smoothing"   // 指数平滑：prev + alpha*(value-prev)
+)
+
+// FilterConfig 单个滤波器的配置，设备配置中 filters 为它的数组，按顺序依次应用
+//
+//	"filters": [
+//	  {"type": "calibration", "finger": {"offset": [0, 0, 4, 0, 0, 0]}},
+//	  {"type": "smoothing", "alpha": 0.5},
+//	  {"type": "jitter", "finger_delta": 5, "palm_delta": 8, "seed": 42},
+//	  {"type": "clamp", "min": 16, "max": 240}
+//	]
+type FilterConfig struct {
+	Type string `json:"type"`
+
+	// jitter
+	FingerDelta int     `json:"finger_delta,omitempty"` // 手指扰动幅度 ±delta
+	PalmDelta   int     `json:"palm_delta,omitempty"`   // 手掌扰动幅度 ±delta
+	Seed        *uint64 `json:"seed,omitempty"`         // 随机种子，为空时每次创建都不同
+
+	// clamp
+	Min *int `json:"min,omitempty"` // 默认 0
+	Max *int `json:"max,omitempty"` // 默认 255
+
+	// calibration
+	Finger *Calibration `json:"finger,omitempty"`
+	Palm   *Calibration `json:"palm,omitempty"`
+
+	// smoothing
+	Alpha float64 `json:"alpha,omitempty"` // (0, 1]，越小越平滑
+}
+
+// Calibration 一个关节组的线性校准参数，为空表示 offset 为 0、scale 为 1
+type Calibration struct {
+	Offset []float64 `json:"offset,omitempty"`
+	Scale  []float64 `json:"scale,omitempty"`
+}
+
+// PoseFilter 对一个关节组的姿态做变换，返回新的切片
+type PoseFilter interface {
+	Apply(group JointGroup, pose []byte) []byte
+}
+
+// FilterPipeline 按顺序应用一组滤波器，可以在运行时整体替换
+type FilterPipeline struct {
+	joints  map[JointGroup]int
+	configs []FilterConfig
+	filters []PoseFilter
+	mutex   sync.Mutex
+}
+
+// NewFilterPipeline 根据配置创建滤波管道，joints 为各关节组的关节数，用于校验按关节的参数
+func NewFilterPipeline(configs []FilterConfig, joints map[JointGroup]int) (*FilterPipeline, error) {
+	p := &FilterPipeline{joints: joints}
+	if err := p.SetConfigs(configs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FilterConfigsFromConfig 读取设备配置中的 filters，未配置时返回空
+func FilterConfigsFromConfig(config map[string]any) ([]FilterConfig, error) {
+	raw, ok := config["filters"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	// 配置可能来自 JSON 请求或注册表，统一经过一次 JSON 编解码
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 filters 配置：%w", err)
+	}
+	var configs []FilterConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("无效的 filters 配置：%w", err)
+	}
+	return configs, nil
+}
+
+// SetConfigs 替换所有滤波器，配置无效时保持原有滤波器不变；平滑等有状态的滤波器会重新开始
+func (p *FilterPipeline) SetConfigs(configs []FilterConfig) error {
+	filters := make([]PoseFilter, 0, len(configs))
+	for i, config := range configs {
+		filter, err := newPoseFilter(config, p.joints)
+		if err != nil {
+			return fmt.Errorf("第 %d 个滤波器无效：%w", i+1, err)
+		}
+		filters = append(filters, filter)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.configs = slices.Clone(configs)
+	p.filters = filters
+	return nil
+}
+
+// Configs 返回当前的滤波器配置
+func (p *FilterPipeline) Configs() []FilterConfig {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.configs)
+}
+
+// Apply 依次应用所有滤波器，不修改 pose
+func (p *FilterPipeline) Apply(group JointGroup, pose []byte) []byte {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	result := slices.Clone(pose)
+	for _, filter := range p.filters {
+		result = filter.Apply(group, result)
+	}
+	return result
+}
+
+func newPoseFilter(config FilterConfig, joints map[JointGroup]int) (PoseFilter, error) {
+	switch config.Type {
+	case FilterJitter:
+		if config.FingerDelta < 0 || config.PalmDelta < 0 {
+			return nil, fmt.Errorf("jitter 的扰动幅度不能为负数")
+		}
+		seed := rand.Uint64()
+		if config.Seed != nil {
+			seed = *config.Seed
+		}
+		return &jitterFilter{
+			deltas: map[JointGroup]int{JointGroupFinger: config.FingerDelta, JointGroupPalm: config.PalmDelta},
+			rng:    rand.New(rand.NewPCG(seed, seed)),
+		}, nil
+
+	case FilterClamp:
+		lo, hi := 0, 255
+		if config.Min != nil {
+			lo = *config.Min
+		}
+		if config.Max != nil {
+			hi = *config.Max
+		}
+		if lo < 0 || hi > 255 || lo > hi {
+			return nil, fmt.Errorf("clamp 的范围无效：%d-%d", lo, hi)
+		}
+		return &clampFilter{min: lo, max: hi}, nil
+
+	case FilterCalibration:
+		calibrations := map[JointGroup]*Calibration{JointGroupFinger: config.Finger, JointGroupPalm: config.Palm}
+		for group, calibration := range calibrations {
+			if calibration == nil {
+				continue
+			}
+			n := joints[group]
+			if len(calibration.Offset) > 0 && len(calibration.Offset) != n {
+				return nil, fmt.Errorf("%s 的 offset 数量为 %d，需要 %d 个", group, len(calibration.Offset), n)
+			}
+			if len(calibration.Scale) > 0 && len(calibration.Scale) != n {
+				return nil, fmt.Errorf("%s 的 scale 数量为 %d，需要 %d 个", group, len(calibration.Scale), n)
+			}
+		}
+		return &calibrationFilter{calibrations: calibrations}, nil
+
+	case FilterSmoothing:
+		if config.Alpha <= 0 || config.Alpha > 1 {
+			return nil, fmt.Errorf("smoothing 的 alpha 必须在 (0, 1] 范围内：%v", config.Alpha)
+		}
+		return &smoothingFilter{alpha: config.Alpha, last: make(map[JointGroup][]float64)}, nil
+	}
+	return nil, fmt.Errorf("未知的滤波器类型：%q", config.Type)
+}
+
+// toByte 四舍五入并限制到 [0, 255]
+func toByte(v float64) byte {
+	return byte(min(max(math.Round(v), 0), 255))
+}
+
+// jitterFilter 在每个关节上添加 ±delta 的均匀随机扰动
+type jitterFilter struct {
+	deltas map[JointGroup]int
+	rng    *rand.Rand
+}
+
+func (f *jitterFilter) Apply(group JointGroup, pose []byte) []byte {
+	delta := f.deltas[group]
+	if delta == 0 {
+		return pose
+	}
+	for i, v := range pose {
+		offset := f.rng.IntN(2*delta+1) - delta
+		pose[i] = byte(min(max(int(v)+offset, 0), 255))
+	}
+	return pose
+}
+
+// clampFilter 将所有关节限制到 [min, max]
+type clampFilter struct{ min, max int }
+
+func (f *clampFilter) Apply(_ JointGroup, pose []byte) []byte {
+	for i, v := range pose {
+		pose[i] = byte(min(max(int(v), f.min), f.max))
+	}
+	return pose
+}
+
+// calibrationFilter 按关节做线性校准
+type calibrationFilter struct {
+	calibrations map[JointGroup]*Calibration
+}
+
+func (f *calibrationFilter) Apply(group JointGroup, pose []byte) []byte {
+	calibration := f.calibrations[group]
+	if calibration == nil {
+		return pose
+	}
+	for i, v := range pose {
+		value := float64(v)
+		if i < len(calibration.Scale) {
+			value *= calibration.Scale[i]
+		}
+		if i < len(calibration.Offset) {
+			value += calibration.Offset[i]
+		}
+		pose[i] = toByte(value)
+	}
+	return pose
+}
+
+// smoothingFilter 对每个关节组做指数平滑，第一次的姿态原样通过
+type smoothingFilter struct {
+	alpha float64
+	last  map[JointGroup][]float64
+}
+
+func (f *smoothingFilter) Apply(group JointGroup, pose []byte) []byte {
+	last := f.last[group]
+	if len(last) != len(pose) {
+		last = make([]float64, len(pose))
+		for i, v := range pose {
+			last[i] = float64(v)
+		}
+	} else {
+		for i, v := range pose {
+			last[i] += f.alpha * (float64(v) - last[i])
+		}
+	}
+	f.last[group] = last
+
+	for i := range pose {
+		pose[i] = toByte(last[i])
+	}
+	return pose
+}
@@ -0,0 +1,170 @@
+package device
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+var testJoints = map[JointGroup]int{JointGroupFinger: 6, JointGroupPalm: 4}
+
+func newTestPipeline(t *testing.T, configs ...FilterConfig) *FilterPipeline {
+	t.Helper()
+	pipeline, err := NewFilterPipeline(configs, testJoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pipeline
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestJitterFilterSeedIsReproducible(t *testing.T) {
+	config := FilterConfig{Type: FilterJitter, FingerDelta: 5, PalmDelta: 8, Seed: ptr[uint64](42)}
+	a := newTestPipeline(t, config)
+	b := newTestPipeline(t, config)
+	other := newTestPipeline(t, FilterConfig{Type: FilterJitter, FingerDelta: 5, PalmDelta: 8, Seed: ptr[uint64](43)})
+
+	finger := []byte{128, 128, 128, 128, 128, 128}
+	palm := []byte{128, 128, 128, 128}
+	differs := false
+	for range 20 {
+		gotA, gotB := a.Apply(JointGroupFinger, finger), b.Apply(JointGroupFinger, finger)
+		if !bytes.Equal(gotA, gotB) {
+			t.Fatalf("相同种子得到不同结果：%v 和 %v", gotA, gotB)
+		}
+		if !bytes.Equal(gotA, other.Apply(JointGroupFinger, finger)) {
+			differs = true
+		}
+		for _, v := range gotA {
+			if v < 123 || v > 133 {
+				t.Fatalf("手指扰动超出 ±5：%v", gotA)
+			}
+		}
+
+		palmA, palmB := a.Apply(JointGroupPalm, palm), b.Apply(JointGroupPalm, palm)
+		if !bytes.Equal(palmA, palmB) {
+			t.Fatalf("相同种子得到不同结果：%v 和 %v", palmA, palmB)
+		}
+		for _, v := range palmA {
+			if v < 120 || v > 136 {
+				t.Fatalf("手掌扰动超出 ±8：%v", palmA)
+			}
+		}
+	}
+	if !differs {
+		t.Fatal("不同种子的结果完全相同")
+	}
+
+	// 重新设置配置后从种子重新开始
+	first := newTestPipeline(t, config).Apply(JointGroupFinger, finger)
+	a.SetConfigs([]FilterConfig{config})
+	if got := a.Apply(JointGroupFinger, finger); !bytes.Equal(got, first) {
+		t.Fatalf("重新设置后得到 %v，期望 %v", got, first)
+	}
+
+	if !bytes.Equal(finger, []byte{128, 128, 128, 128, 128, 128}) {
+		t.Fatal("Apply 不应修改传入的姿态")
+	}
+}
+
+func TestJitterFilterSaturates(t *testing.T) {
+	pipeline := newTestPipeline(t, FilterConfig{Type: FilterJitter, FingerDelta: 50, Seed: ptr[uint64](1)})
+	for range 20 {
+		got := pipeline.Apply(JointGroupFinger, []byte{0, 255, 0, 255, 0, 255})
+		for i, v := range got {
+			if (i%2 == 0 && v > 50) || (i%2 == 1 && v < 205) {
+				t.Fatalf("扰动应在 0-255 内饱和：%v", got)
+			}
+		}
+	}
+	// 幅度为 0 的关节组不扰动
+	if got := pipeline.Apply(JointGroupPalm, []byte{1, 2, 3, 4}); !bytes.Equal(got, []byte{1, 2, 3, 4}) {
+		t.Fatalf("手掌不应被扰动：%v", got)
+	}
+}
+
+func TestFilterPipelineOrder(t *testing.T) {
+	pipeline := newTestPipeline(t,
+		FilterConfig{Type: FilterCalibration, Palm: &Calibration{Offset: []float64{10, 0, -10, 0}, Scale: []float64{1, 2, 1, 0.5}}},
+		FilterConfig{Type: FilterClamp, Min: ptr(20), Max: ptr(240)},
+	)
+	got := pipeline.Apply(JointGroupPalm, []byte{0, 200, 5, 101})
+	if want := []byte{20, 240, 20, 51}; !bytes.Equal(got, want) {
+		t.Fatalf("得到 %v，期望 %v", got, want)
+	}
+	// 没有校准参数的关节组只经过 clamp
+	if got := pipeline.Apply(JointGroupFinger, []byte{0, 100, 255, 100, 100, 100}); !bytes.Equal(got, []byte{20, 100, 240, 100, 100, 100}) {
+		t.Fatalf("得到 %v", got)
+	}
+}
+
+func TestSmoothingFilter(t *testing.T) {
+	pipeline := newTestPipeline(t, FilterConfig{Type: FilterSmoothing, Alpha: 0.5})
+
+	steps := [][]byte{{0, 0, 0, 0}, {100, 200, 0, 0}, {100, 200, 0, 0}}
+	want := [][]byte{{0, 0, 0, 0}, {50, 100, 0, 0}, {75, 150, 0, 0}}
+	for i, pose := range steps {
+		if got := pipeline.Apply(JointGroupPalm, pose); !bytes.Equal(got, want[i]) {
+			t.Fatalf("第 %d 步得到 %v，期望 %v", i+1, got, want[i])
+		}
+	}
+	// 各关节组分别平滑，第一次原样通过
+	if got := pipeline.Apply(JointGroupFinger, []byte{9, 9, 9, 9, 9, 9}); !bytes.Equal(got, []byte{9, 9, 9, 9, 9, 9}) {
+		t.Fatalf("得到 %v", got)
+	}
+}
+
+func TestFilterConfigErrors(t *testing.T) {
+	invalid := map[string]FilterConfig{
+		"未知类型":         {Type: "median"},
+		"负的扰动":         {Type: FilterJitter, FingerDelta: -1},
+		"clamp 范围颠倒":   {Type: FilterClamp, Min: ptr(200), Max: ptr(100)},
+		"clamp 超出 255": {Type: FilterClamp, Max: ptr(256)},
+		"offset 数量不符":  {Type: FilterCalibration, Finger: &Calibration{Offset: []float64{1}}},
+		"scale 数量不符":   {Type: FilterCalibration, Palm: &Calibration{Scale: []float64{1, 1, 1, 1, 1}}},
+		"alpha 为 0":    {Type: FilterSmoothing},
+		"alpha 大于 1":   {Type: FilterSmoothing, Alpha: 1.5},
+	}
+	for name, config := range invalid {
+		if _, err := NewFilterPipeline([]FilterConfig{config}, testJoints); err == nil {
+			t.Errorf("%s：应返回错误", name)
+		}
+	}
+
+	// 配置无效时保持原有滤波器
+	valid := []FilterConfig{{Type: FilterClamp, Max: ptr(100)}}
+	pipeline := newTestPipeline(t, valid...)
+	if err := pipeline.SetConfigs([]FilterConfig{{Type: "median"}}); err == nil {
+		t.Fatal("无效配置应返回错误")
+	}
+	if got := pipeline.Configs(); len(got) != 1 || got[0].Type != FilterClamp {
+		t.Fatalf("配置被替换为 %+v", got)
+	}
+	if got := pipeline.Apply(JointGroupPalm, []byte{200, 0, 0, 0}); got[0] != 100 {
+		t.Fatalf("滤波器被替换，得到 %v", got)
+	}
+}
+
+func TestFilterConfigsFromConfig(t *testing.T) {
+	configs, err := FilterConfigsFromConfig(map[string]any{
+		"filters": []any{
+			map[string]any{"type": "jitter", "finger_delta": 5, "seed": 42},
+			map[string]any{"type": "clamp", "min": 16},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := []string{configs[0].Type, configs[1].Type}
+	if !slices.Equal(types, []string{FilterJitter, FilterClamp}) || *configs[0].Seed != 42 || *configs[1].Min != 16 || configs[1].Max != nil {
+		t.Fatalf("解析得到 %+v", configs)
+	}
+
+	if configs, err := FilterConfigsFromConfig(map[string]any{}); err != nil || configs != nil {
+		t.Fatalf("未配置时得到 %v %v", configs, err)
+	}
+	if _, err := FilterConfigsFromConfig(map[string]any{"filters": "jitter"}); err == nil {
+		t.Fatal("类型错误时应返回错误")
+	}
+}
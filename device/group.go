@@ -0,0 +1,217 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrGroupPrecheckFailed 全部或全不执行模式下有成员预检失败，没有向任何成员发送指令
+var ErrGroupPrecheckFailed = errors.New("设备组有成员无法执行指令")
+
+// DeviceGroup 一组一起控制的设备，例如一双手或一排手
+type DeviceGroup struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	DeviceIDs []string `json:"deviceIds"`
+}
+
+// GroupMemberResult 组指令在单个成员上的执行结果
+type GroupMemberResult struct {
+	DeviceID string `json:"deviceId"`
+	Success  bool   `json:"success"`
+	Skipped  bool   `json:"skipped,omitempty"` // 全部或全不执行模式下因其它成员预检失败而未执行
+	Error    string `json:"error,omitempty"`
+}
+
+// validateGroupLocked 检查组 ID 和成员，调用方需持有 m.mutex
+func (m *DeviceManager) validateGroupLocked(group DeviceGroup) error {
+	if group.ID == "" {
+		return fmt.Errorf("设备组 ID 不能为空")
+	}
+	if len(group.DeviceIDs) == 0 {
+		return fmt.Errorf("设备组 %s 至少需要一个设备", group.ID)
+	}
+	seen := make(map[string]bool, len(group.DeviceIDs))
+	for _, id := range group.DeviceIDs {
+		if seen[id] {
+			return fmt.Errorf("设备 %s 在设备组 %s 中重复", id, group.ID)
+		}
+		seen[id] = true
+		if _, exists := m.devices[id]; !exists {
+			return fmt.Errorf("设备 %s 不存在", id)
+		}
+	}
+	return nil
+}
+
+// CreateGroup 创建设备组并保存到注册表
+func (m *DeviceManager) CreateGroup(group DeviceGroup) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.groups[group.ID]; exists {
+		return fmt.Errorf("设备组 %s 已存在", group.ID)
+	}
+	if err := m.validateGroupLocked(group); err != nil {
+		return err
+	}
+
+	group.DeviceIDs = slices.Clone(group.DeviceIDs)
+	m.groups[group.ID] = group
+	if err := m.saveLocked(); err != nil {
+		delete(m.groups, group.ID)
+		return err
+	}
+	return nil
+}
+
+// UpdateGroup 替换设备组的名称和成员
+func (m *DeviceManager) UpdateGroup(group DeviceGroup) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, exists := m.groups[group.ID]
+	if !exists {
+		return fmt.Errorf("设备组 %s 不存在", group.ID)
+	}
+	if err := m.validateGroupLocked(group); err != nil {
+		return err
+	}
+
+	group.DeviceIDs = slices.Clone(group.DeviceIDs)
+	m.groups[group.ID] = group
+	if err := m.saveLocked(); err != nil {
+		m.groups[group.ID] = previous
+		return err
+	}
+	return nil
+}
+
+// DeleteGroup 删除设备组，不影响组内的设备
+func (m *DeviceManager) DeleteGroup(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, exists := m.groups[id]
+	if !exists {
+		return fmt.Errorf("设备组 %s 不存在", id)
+	}
+	delete(m.groups, id)
+	if err := m.saveLocked(); err != nil {
+		m.groups[id] = previous
+		return err
+	}
+	return nil
+}
+
+func (m *DeviceManager) GetGroup(id string) (DeviceGroup, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	group, exists := m.groups[id]
+	if !exists {
+		return DeviceGroup{}, fmt.Errorf("设备组 %s 不存在", id)
+	}
+	group.DeviceIDs = slices.Clone(group.DeviceIDs)
+	return group, nil
+}
+
+// GetAllGroups 返回按 ID 排序的所有设备组
+func (m *DeviceManager) GetAllGroups() []DeviceGroup {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	groups := make([]DeviceGroup, 0, len(m.groups))
+	for _, group := range m.groups {
+		group.DeviceIDs = slices.Clone(group.DeviceIDs)
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b DeviceGroup) int { return strings.Compare(a.ID, b.ID) })
+	return groups
+}
+
+// removeFromGroupsLocked 从所有设备组中移除设备，成员为空的组一并删除，返回是否有改动，调用方需持有 m.mutex
+func (m *DeviceManager) removeFromGroupsLocked(deviceID string) bool {
+	changed := false
+	for id, group := range m.groups {
+		idx := slices.Index(group.DeviceIDs, deviceID)
+		if idx < 0 {
+			continue
+		}
+		changed = true
+		group.DeviceIDs = slices.Delete(slices.Clone(group.DeviceIDs), idx, idx+1)
+		if len(group.DeviceIDs) == 0 {
+			log.Printf("🗑️ 设备组 %s 已没有成员，已删除", id)
+			delete(m.groups, id)
+			continue
+		}
+		m.groups[id] = group
+	}
+	return changed
+}
+
+// ExecuteGroup 在设备组的所有成员上并发执行 op，按成员顺序返回每个成员的结果
+// check 不为空时先对每个成员做预检，预检失败的成员不执行；allOrNothing 为 true 时任一成员预检失败则所有成员都不执行
+// 组不存在时返回错误；全部或全不执行模式下预检失败时同时返回结果和 ErrGroupPrecheckFailed；成员执行失败只体现在结果中
+func (m *DeviceManager) ExecuteGroup(groupID string, allOrNothing bool, check, op func(Device) error) ([]GroupMemberResult, error) {
+	m.mutex.RLock()
+	group, exists := m.groups[groupID]
+	if !exists {
+		m.mutex.RUnlock()
+		return nil, fmt.Errorf("设备组 %s 不存在", groupID)
+	}
+	members := make([]Device, len(group.DeviceIDs))
+	for i, id := range group.DeviceIDs {
+		members[i] = m.devices[id] // 未恢复的设备为 nil
+	}
+	m.mutex.RUnlock()
+
+	results := make([]GroupMemberResult, len(members))
+	ready := make([]bool, len(members))
+	allReady := true
+	for i, dev := range members {
+		results[i].DeviceID = group.DeviceIDs[i]
+		var err error
+		switch {
+		case dev == nil:
+			err = fmt.Errorf("设备 %s 不存在", group.DeviceIDs[i])
+		case check != nil:
+			err = check(dev)
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			allReady = false
+			continue
+		}
+		ready[i] = true
+	}
+
+	if allOrNothing && !allReady {
+		for i := range results {
+			results[i].Skipped = ready[i]
+		}
+		return results, ErrGroupPrecheckFailed
+	}
+
+	var wg sync.WaitGroup
+	for i, dev := range members {
+		if !ready[i] {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := op(dev); err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Success = true
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
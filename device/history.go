@@ -0,0 +1,144 @@
+package device
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize 每个设备保留的指令历史条数，超出后覆盖最旧的记录
+const DefaultHistorySize = 256
+
+// 指令来源
+const (
+	SourceAPI       = "api"       // /api/v1 设备接口
+	SourceGroup     = "group"     // /api/v1 设备组接口
+	SourceLegacy    = "legacy"    // /api/legacy 旧版接口
+	SourceAnimation = "animation" // 动画引擎
+	SourceWatchdog  = "watchdog"  // 控制会话心跳超时
+	SourceDevice    = "device"    // 未指定来源，直接调用设备方法
+)
+
+// 指令结果
+const (
+	ResultOK        = "ok"        // 已发送
+	ResultFailed    = "failed"    // 发送失败，或被关节限制拒绝
+	ResultCoalesced = "coalesced" // 发送前被同一通道中更新的姿态取代
+	ResultPreempted = "preempted" // 发送前被紧急指令取消
+	ResultRejected  = "rejected"  // 没有进入队列：设备不接受指令、急停锁定或队列已满
+	ResultClosed    = "closed"    // 设备删除时仍在等待或之后提交，没有发送
+)
+
+// Bytes 编码为数字数组而不是 base64 的字节切片，便于阅读历史记录
+type Bytes []byte
+
+// MarshalJSON 实现 json.Marshaler
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	values := make([]int, len(b))
+	for i, v := range b {
+		values[i] = int(v)
+	}
+	return json.Marshal(values)
+}
+
+// HistoryPose 历史记录中的一组原始姿态，没有涉及的关节组为空
+type HistoryPose struct {
+	Finger Bytes `json:"finger,omitempty"`
+	Palm   Bytes `json:"palm,omitempty"`
+}
+
+// HistoryEntry 一个动作的执行记录
+type HistoryEntry struct {
+	Seq       uint64      `json:"seq"`              // 设备内递增的序号
+	Time      time.Time   `json:"time"`             // 提交时间
+	Source    string      `json:"source"`           // 来源，见 SourceAPI 等常量
+	Detail    string      `json:"detail,omitempty"` // 来源细节：API 路由、设备组或动画名称
+	Client    string      `json:"client,omitempty"` // 发起请求的客户端地址
+	Action    string      `json:"action"`           // 动作：finger、palm、reset、preset:<名称>、estop、watchdog 或 command
+	Priority  string      `json:"priority"`         // 指令队列通道
+	Commands  []string    `json:"commands"`         // 指令类型
+	Input     HistoryPose `json:"input"`            // 滤波前请求的姿态
+	Output    HistoryPose `json:"output"`           // 经过滤波和关节限制后实际发送的姿态，没有发送时为空
+	Frames    []string    `json:"frames,omitempty"` // 编码后的 CAN 帧，格式为 "can0 028#01FF..."
+	Result    string      `json:"result"`           // 结果，见 ResultOK 等常量
+	Error     string      `json:"error,omitempty"`
+	LatencyMs float64     `json:"latencyMs"` // 从提交到得到结果的耗时
+}
+
+// HistoryFilter 查询指令历史的条件，零值表示不过滤
+type HistoryFilter struct {
+	Source string    // 来源，精确匹配
+	Action string    // 动作前缀，例如 "preset" 匹配所有预设姿势
+	Result string    // 结果，精确匹配
+	Since  time.Time // 只返回此时间之后提交的记录
+	Limit  int       // 最多返回的条数，<= 0 表示不限制
+}
+
+// matches 判断记录是否满足条件
+func (f HistoryFilter) matches(entry *HistoryEntry) bool {
+	return (f.Source == "" || entry.Source == f.Source) &&
+		(f.Action == "" || strings.HasPrefix(entry.Action, f.Action)) &&
+		(f.Result == "" || entry.Result == f.Result) &&
+		(f.Since.IsZero() || entry.Time.After(f.Since))
+}
+
+// HistoryDevice 由记录指令历史的设备实现
+type HistoryDevice interface {
+	GetHistory(filter HistoryFilter) []HistoryEntry
+}
+
+// CommandHistory 固定容量的指令历史环形缓冲区，并发安全
+type CommandHistory struct {
+	entries []HistoryEntry
+	next    int    // 下一条记录写入的位置
+	seq     uint64 // 最近一条记录的序号
+	mutex   sync.Mutex
+}
+
+// NewCommandHistory 创建容量为 size 的指令历史，size <= 0 时使用 DefaultHistorySize
+func NewCommandHistory(size int) *CommandHistory {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &CommandHistory{entries: make([]HistoryEntry, 0, size)}
+}
+
+// Add 追加一条记录并返回分配的序号，缓冲区已满时覆盖最旧的记录
+func (h *CommandHistory) Add(entry HistoryEntry) uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.seq++
+	entry.Seq = h.seq
+	if len(h.entries) < cap(h.entries) {
+		h.entries = append(h.entries, entry)
+		return h.seq
+	}
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	return h.seq
+}
+
+// Query 按从新到旧的顺序返回满足条件的记录
+func (h *CommandHistory) Query(filter HistoryFilter) []HistoryEntry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	result := []HistoryEntry{}
+	for i := range h.entries {
+		// 缓冲区未满时 next 为 0，最新的记录在末尾
+		entry := &h.entries[(h.next-1-i+2*len(h.entries))%len(h.entries)]
+		if !filter.matches(entry) {
+			continue
+		}
+		result = append(result, *entry)
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result
+}
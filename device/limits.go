@@ -0,0 +1,252 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ErrJointLimit 姿态超出关节限制且限制模式为 reject
+var ErrJointLimit = errors.New("姿态超出关节限制")
+
+// LimitMode 姿态超出关节限制时的处理方式
+type LimitMode string
+
+const (
+	LimitModeClamp  LimitMode = "clamp"  // 限制到允许范围内后发送
+	LimitModeReject LimitMode = "reject" // 拒绝整条指令
+)
+
+// JointGroup 关节组，对应一条姿态指令的数据
+type JointGroup string
+
+const (
+	JointGroupFinger JointGroup = "finger"
+	JointGroupPalm   JointGroup = "palm"
+)
+
+// JointLimit 单个关节允许的取值范围
+type JointLimit struct {
+	Min byte `json:"min"`
+	Max byte `json:"max"`
+}
+
+// LimitConfig 设备配置中 joint_limits 的内容
+//
+//	"joint_limits": {
+//	  "mode": "clamp",
+//	  "max_step": 40,
+//	  "finger": [{"min": 0, "max": 200}, ...],
+//	  "palm": [{"min": 32, "max": 224}, ...]
+//	}
+type LimitConfig struct {
+	Mode    LimitMode    `json:"mode,omitempty"`     // 默认 clamp
+	MaxStep int          `json:"max_step,omitempty"` // 每条指令中单个关节相对上一次发送的最大变化量，0 表示不限制
+	Finger  []JointLimit `json:"finger,omitempty"`   // 为空表示不限制，否则长度必须与关节数一致
+	Palm    []JointLimit `json:"palm,omitempty"`
+}
+
+// LimitStats 关节限制的配置和违规计数
+type LimitStats struct {
+	Mode       LimitMode      `json:"mode"`
+	MaxStep    int            `json:"maxStep"`
+	Clamped    int            `json:"clamped"`    // 被限制后发送的姿态数
+	Rejected   int            `json:"rejected"`   // 被拒绝的姿态数
+	Violations map[string]int `json:"violations"` // 按关节统计的违规次数，key 如 "finger[2]"
+}
+
+// LimitCheckingDevice 由带关节限制的设备实现，用于在发送前预检姿态，例如设备组的全部或全不执行
+type LimitCheckingDevice interface {
+	// CheckJointLimits 按设备当前的关节限制检查滤波前的姿态，不发送也不计入违规统计；reject 模式下会被拒绝时返回 ErrJointLimit
+	CheckJointLimits(group JointGroup, pose []byte) error
+}
+
+// SafetyEnvelope 检查每条姿态指令是否超出关节范围或单步变化量，由设备在发送前统一调用
+type SafetyEnvelope struct {
+	config LimitConfig
+	joints map[JointGroup]int
+	last   map[JointGroup][]byte // 上一次成功发送的姿态，用于限制单步变化量
+	stats  LimitStats
+	mutex  sync.Mutex
+}
+
+// NewSafetyEnvelopeFromConfig 从设备配置的 joint_limits 创建安全限制，joints 为各关节组的关节数
+// 没有配置 joint_limits 时返回的限制只检查关节数
+func NewSafetyEnvelopeFromConfig(config map[string]any, joints map[JointGroup]int) (*SafetyEnvelope, error) {
+	var limitConfig LimitConfig
+	if raw, ok := config["joint_limits"]; ok && raw != nil {
+		// 配置可能来自 JSON 请求或注册表，统一经过一次 JSON 编解码
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 joint_limits 配置：%w", err)
+		}
+		if err := json.Unmarshal(data, &limitConfig); err != nil {
+			return nil, fmt.Errorf("无效的 joint_limits 配置：%w", err)
+		}
+	}
+
+	switch limitConfig.Mode {
+	case "":
+		limitConfig.Mode = LimitModeClamp
+	case LimitModeClamp, LimitModeReject:
+	default:
+		return nil, fmt.Errorf("无效的关节限制模式：%s，可选值为 clamp 或 reject", limitConfig.Mode)
+	}
+	if limitConfig.MaxStep < 0 {
+		return nil, fmt.Errorf("无效的 max_step：%d", limitConfig.MaxStep)
+	}
+
+	for group, limits := range map[JointGroup][]JointLimit{
+		JointGroupFinger: limitConfig.Finger,
+		JointGroupPalm:   limitConfig.Palm,
+	} {
+		if len(limits) == 0 {
+			continue
+		}
+		if len(limits) != joints[group] {
+			return nil, fmt.Errorf("%s 关节限制数量为 %d，需要 %d 个", group, len(limits), joints[group])
+		}
+		for i, limit := range limits {
+			if limit.Min > limit.Max {
+				return nil, fmt.Errorf("%s[%d] 的关节限制无效：min %d 大于 max %d", group, i, limit.Min, limit.Max)
+			}
+		}
+	}
+
+	return &SafetyEnvelope{
+		config: limitConfig,
+		joints: joints,
+		last:   make(map[JointGroup][]byte),
+		stats: LimitStats{
+			Mode:       limitConfig.Mode,
+			MaxStep:    limitConfig.MaxStep,
+			Violations: make(map[string]int),
+		},
+	}, nil
+}
+
+// limitsFor 返回关节组的范围限制，未配置时为空
+func (e *SafetyEnvelope) limitsFor(group JointGroup) []JointLimit {
+	switch group {
+	case JointGroupFinger:
+		return e.config.Finger
+	case JointGroupPalm:
+		return e.config.Palm
+	}
+	return nil
+}
+
+// Limits 返回关节组配置的范围限制，未配置时为空
+func (e *SafetyEnvelope) Limits(group JointGroup) []JointLimit {
+	return slices.Clone(e.limitsFor(group))
+}
+
+// Enforce 检查一个关节组的姿态：clamp 模式返回限制后的姿态，reject 模式下有任一关节违规时返回 ErrJointLimit
+// 返回的切片是新分配的，不修改 pose
+func (e *SafetyEnvelope) Enforce(deviceID string, group JointGroup, pose []byte) ([]byte, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	result, joints, violations, err := e.evaluateLocked(group, pose)
+	if err != nil || len(violations) == 0 {
+		return result, err
+	}
+
+	for _, joint := range joints {
+		e.stats.Violations[joint]++
+	}
+	if e.config.Mode == LimitModeReject {
+		e.stats.Rejected++
+		log.Printf("⛔ 设备 %s 的 %s 姿态超出关节限制，已拒绝: %v", deviceID, group, violations)
+		return nil, fmt.Errorf("%w：%v", ErrJointLimit, violations)
+	}
+	e.stats.Clamped++
+	log.Printf("⚠️ 设备 %s 的 %s 姿态超出关节限制，已限制: %v", deviceID, group, violations)
+	return result, nil
+}
+
+// Check 与 Enforce 的判断相同，但不修改违规统计：姿态会被拒绝时返回 ErrJointLimit，会被限制时返回 nil
+func (e *SafetyEnvelope) Check(group JointGroup, pose []byte) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	_, _, violations, err := e.evaluateLocked(group, pose)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 && e.config.Mode == LimitModeReject {
+		return fmt.Errorf("%w：%v", ErrJointLimit, violations)
+	}
+	return nil
+}
+
+// evaluateLocked 按关节范围和单步变化量限制姿态，返回限制后的姿态、违规的关节和违规描述，调用方需持有 e.mutex
+func (e *SafetyEnvelope) evaluateLocked(group JointGroup, pose []byte) (result []byte, joints, violations []string, err error) {
+	if n, ok := e.joints[group]; !ok || len(pose) != n {
+		return nil, nil, nil, fmt.Errorf("无效的 %s 姿态数据长度 %d，需要 %d 个字节", group, len(pose), e.joints[group])
+	}
+
+	limits := e.limitsFor(group)
+	last := e.last[group]
+	result = make([]byte, len(pose))
+
+	for i, v := range pose {
+		lo, hi := 0, 255
+		if limits != nil {
+			lo, hi = int(limits[i].Min), int(limits[i].Max)
+		}
+		if e.config.MaxStep > 0 && last != nil {
+			lo = max(lo, int(last[i])-e.config.MaxStep)
+			hi = min(hi, int(last[i])+e.config.MaxStep)
+		}
+
+		bounded := min(max(int(v), lo), hi)
+		if bounded != int(v) {
+			joint := fmt.Sprintf("%s[%d]", group, i)
+			joints = append(joints, joint)
+			violations = append(violations, fmt.Sprintf("%s=%d 允许 %d-%d", joint, v, lo, hi))
+		}
+		result[i] = byte(bounded)
+	}
+	return result, joints, violations, nil
+}
+
+// ClampToRange 将姿态限制到配置的关节范围内，返回限制后的姿态和超出范围的关节；不检查单步变化量，也不计入违规统计
+// 用于急停和 watchdog 的安全姿态，它们必须一次到位，不能被 max_step 截断或拒绝。pose 的长度需要已经校验过
+func (e *SafetyEnvelope) ClampToRange(group JointGroup, pose []byte) ([]byte, []string) {
+	limits := e.limitsFor(group)
+	result := slices.Clone(pose)
+	if limits == nil {
+		return result, nil
+	}
+
+	var violations []string
+	for i, v := range pose {
+		if v < limits[i].Min || v > limits[i].Max {
+			violations = append(violations, fmt.Sprintf("%s[%d]=%d 允许 %d-%d", group, i, v, limits[i].Min, limits[i].Max))
+			result[i] = min(max(v, limits[i].Min), limits[i].Max)
+		}
+	}
+	return result, violations
+}
+
+// Commit 记录成功发送的姿态，作为下一条指令单步变化量的基准
+func (e *SafetyEnvelope) Commit(group JointGroup, pose []byte) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.last[group] = append([]byte(nil), pose...)
+}
+
+// Stats 返回限制配置和违规计数的快照
+func (e *SafetyEnvelope) Stats() LimitStats {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	stats := e.stats
+	stats.Violations = maps.Clone(e.stats.Violations)
+	return stats
+}
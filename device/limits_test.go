@@ -0,0 +1,194 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"maps"
+	"testing"
+)
+
+// newTestEnvelope 创建 L10 关节数的安全限制
+func newTestEnvelope(t *testing.T, limits map[string]any) *SafetyEnvelope {
+	t.Helper()
+	envelope, err := NewSafetyEnvelopeFromConfig(
+		map[string]any{"joint_limits": limits},
+		map[JointGroup]int{JointGroupFinger: 6, JointGroupPalm: 4},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestSafetyEnvelopeCheckDoesNotCount(t *testing.T) {
+	envelope := newTestEnvelope(t, map[string]any{
+		"mode":     "reject",
+		"max_step": 20,
+		"palm":     []map[string]int{{"min": 50, "max": 200}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}},
+	})
+
+	if err := envelope.Check(JointGroupPalm, []byte{40, 128, 128, 128}); !errors.Is(err, ErrJointLimit) {
+		t.Fatalf("超出范围的姿态应返回 ErrJointLimit，得到 %v", err)
+	}
+	if err := envelope.Check(JointGroupPalm, []byte{100, 128, 128, 128}); err != nil {
+		t.Fatalf("范围内的姿态应通过，得到 %v", err)
+	}
+
+	// 单步变化量以上一次发送的姿态为基准
+	envelope.Commit(JointGroupPalm, []byte{100, 128, 128, 128})
+	if err := envelope.Check(JointGroupPalm, []byte{130, 128, 128, 128}); !errors.Is(err, ErrJointLimit) {
+		t.Fatalf("超过 max_step 的姿态应返回 ErrJointLimit，得到 %v", err)
+	}
+
+	if err := envelope.Check(JointGroupPalm, []byte{128}); err == nil || errors.Is(err, ErrJointLimit) {
+		t.Fatalf("长度错误应返回普通错误，得到 %v", err)
+	}
+
+	stats := envelope.Stats()
+	if stats.Rejected != 0 || stats.Clamped != 0 || len(stats.Violations) != 0 {
+		t.Fatalf("Check 不应计入统计，得到 %+v", stats)
+	}
+}
+
+func TestSafetyEnvelopeCheckPassesInClampMode(t *testing.T) {
+	envelope := newTestEnvelope(t, map[string]any{
+		"finger": []map[string]int{{"min": 0, "max": 100}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}},
+	})
+
+	if err := envelope.Check(JointGroupFinger, []byte{255, 0, 0, 0, 0, 0}); err != nil {
+		t.Fatalf("clamp 模式下超出范围的姿态会被限制后发送，预检应通过，得到 %v", err)
+	}
+}
+
+func TestSafetyEnvelopeClampAndReject(t *testing.T) {
+	limits := func(mode string) map[string]any {
+		return map[string]any{
+			"mode":   mode,
+			"finger": []map[string]int{{"min": 10, "max": 200}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 100}},
+		}
+	}
+	pose := []byte{0, 128, 128, 128, 128, 150}
+
+	clamp := newTestEnvelope(t, limits("clamp"))
+	got, err := clamp.Enforce("test", JointGroupFinger, pose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{10, 128, 128, 128, 128, 100}; !bytes.Equal(got, want) {
+		t.Fatalf("clamp 模式得到 %v，期望 %v", got, want)
+	}
+	if pose[0] != 0 {
+		t.Fatal("Enforce 不应修改传入的姿态")
+	}
+
+	reject := newTestEnvelope(t, limits("reject"))
+	if got, err := reject.Enforce("test", JointGroupFinger, pose); !errors.Is(err, ErrJointLimit) || got != nil {
+		t.Fatalf("reject 模式应返回 ErrJointLimit，得到 %v %v", got, err)
+	}
+	if _, err := reject.Enforce("test", JointGroupFinger, []byte{10, 0, 255, 0, 0, 100}); err != nil {
+		t.Fatalf("边界值应通过，得到 %v", err)
+	}
+
+	// 没有配置范围的关节组不限制
+	if got, err := reject.Enforce("test", JointGroupPalm, []byte{0, 255, 0, 255}); err != nil || !bytes.Equal(got, []byte{0, 255, 0, 255}) {
+		t.Fatalf("没有配置范围时得到 %v %v", got, err)
+	}
+}
+
+func TestSafetyEnvelopeMaxStep(t *testing.T) {
+	envelope := newTestEnvelope(t, map[string]any{"max_step": 30})
+
+	// 第一条指令没有基准，不限制单步变化量
+	got, err := envelope.Enforce("test", JointGroupPalm, []byte{0, 255, 128, 128})
+	if err != nil || !bytes.Equal(got, []byte{0, 255, 128, 128}) {
+		t.Fatalf("第一条指令得到 %v %v", got, err)
+	}
+	envelope.Commit(JointGroupPalm, got)
+
+	got, err = envelope.Enforce("test", JointGroupPalm, []byte{100, 200, 140, 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{30, 225, 140, 128}; !bytes.Equal(got, want) {
+		t.Fatalf("得到 %v，期望 %v", got, want)
+	}
+
+	// 没有 Commit 时基准不变
+	got, _ = envelope.Enforce("test", JointGroupPalm, []byte{100, 200, 140, 128})
+	if want := []byte{30, 225, 140, 128}; !bytes.Equal(got, want) {
+		t.Fatalf("得到 %v，期望 %v", got, want)
+	}
+}
+
+func TestSafetyEnvelopeViolationCounters(t *testing.T) {
+	envelope := newTestEnvelope(t, map[string]any{
+		"mode": "reject",
+		"palm": []map[string]int{{"min": 50, "max": 200}, {"min": 50, "max": 200}, {"min": 0, "max": 255}, {"min": 0, "max": 255}},
+	})
+
+	envelope.Enforce("test", JointGroupPalm, []byte{0, 128, 0, 0})
+	envelope.Enforce("test", JointGroupPalm, []byte{255, 255, 0, 0})
+	envelope.Enforce("test", JointGroupPalm, []byte{100, 100, 0, 0})
+
+	stats := envelope.Stats()
+	if stats.Mode != LimitModeReject || stats.Rejected != 2 || stats.Clamped != 0 {
+		t.Fatalf("mode=%s rejected=%d clamped=%d，期望 reject、2 和 0", stats.Mode, stats.Rejected, stats.Clamped)
+	}
+	want := map[string]int{"palm[0]": 2, "palm[1]": 1}
+	if !maps.Equal(stats.Violations, want) {
+		t.Fatalf("违规计数为 %v，期望 %v", stats.Violations, want)
+	}
+
+	// 返回的是快照
+	stats.Violations["palm[0]"] = 100
+	if envelope.Stats().Violations["palm[0]"] != 2 {
+		t.Fatal("修改快照影响了违规计数")
+	}
+
+	clamp := newTestEnvelope(t, map[string]any{"max_step": 10})
+	clamp.Commit(JointGroupFinger, []byte{100, 100, 100, 100, 100, 100})
+	clamp.Enforce("test", JointGroupFinger, []byte{150, 100, 100, 100, 100, 50})
+	if stats := clamp.Stats(); stats.Clamped != 1 || stats.Violations["finger[0]"] != 1 || stats.Violations["finger[5]"] != 1 {
+		t.Fatalf("clamp 统计为 %+v", stats)
+	}
+}
+
+func TestNewSafetyEnvelopeFromConfigErrors(t *testing.T) {
+	joints := map[JointGroup]int{JointGroupFinger: 6, JointGroupPalm: 4}
+	for name, limits := range map[string]any{
+		"无效模式":     map[string]any{"mode": "ignore"},
+		"负的步长":     map[string]any{"max_step": -1},
+		"关节数不符":    map[string]any{"palm": []map[string]int{{"min": 0, "max": 255}}},
+		"最小值大于最大值": map[string]any{"palm": []map[string]int{{"min": 200, "max": 100}, {}, {}, {}}},
+		"类型错误":     "clamp",
+	} {
+		if _, err := NewSafetyEnvelopeFromConfig(map[string]any{"joint_limits": limits}, joints); err == nil {
+			t.Errorf("%s：应返回错误", name)
+		}
+	}
+
+	envelope, err := NewSafetyEnvelopeFromConfig(map[string]any{}, joints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats := envelope.Stats(); stats.Mode != LimitModeClamp {
+		t.Fatalf("默认模式为 %s，期望 clamp", stats.Mode)
+	}
+}
+
+func TestSafetyEnvelopeClampToRangeIgnoresMaxStep(t *testing.T) {
+	envelope := newTestEnvelope(t, map[string]any{
+		"mode":     "reject",
+		"max_step": 5,
+		"palm":     []map[string]int{{"min": 50, "max": 200}, {"min": 0, "max": 255}, {"min": 0, "max": 255}, {"min": 0, "max": 255}},
+	})
+	envelope.Commit(JointGroupPalm, []byte{100, 100, 100, 100})
+
+	got, violations := envelope.ClampToRange(JointGroupPalm, []byte{0, 200, 0, 255})
+	if want := []byte{50, 200, 0, 255}; !bytes.Equal(got, want) || len(violations) != 1 {
+		t.Fatalf("得到 %v %v，期望 %v 和一处违规", got, violations, want)
+	}
+	if stats := envelope.Stats(); stats.Rejected != 0 || len(stats.Violations) != 0 {
+		t.Fatalf("ClampToRange 不应计入统计，得到 %+v", stats)
+	}
+}
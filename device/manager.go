@@ -2,17 +2,304 @@ package device
 
 import (
 	"fmt"
+	"hands/define"
+	"log"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 )
 
 // DeviceManager 管理设备实例
 type DeviceManager struct {
-	devices map[string]Device
-	mutex   sync.RWMutex
+	devices    map[string]Device
+	records    map[string]DeviceRecord // 通过 CreateDevice 创建、需要持久化的设备
+	unrestored map[string]DeviceRecord // 启动时未能恢复的记录，原样保留在注册表中
+	groups     map[string]DeviceGroup  // 设备组，按组 ID 索引
+	registry   *FileRegistry           // 为空表示不持久化
+	estop      EmergencyStopStatus     // 急停状态，锁定期间加入的设备同样被锁定
+	mutex      sync.RWMutex
 }
 
-func NewDeviceManager() *DeviceManager { return &DeviceManager{devices: make(map[string]Device)} }
+func NewDeviceManager() *DeviceManager {
+	return &DeviceManager{
+		devices:    make(map[string]Device),
+		records:    make(map[string]DeviceRecord),
+		unrestored: make(map[string]DeviceRecord),
+		groups:     make(map[string]DeviceGroup),
+	}
+}
+
+// SetRegistry 设置设备注册表，之后创建、删除设备和设备组以及修改手型都会保存到注册表
+func (m *DeviceManager) SetRegistry(registry *FileRegistry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.registry = registry
+}
+
+// RestoreDevices 从注册表重新创建设备并恢复设备组，应在提供 API 服务之前调用；单个设备恢复失败不影响其它设备
+func (m *DeviceManager) RestoreDevices() (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.registry == nil {
+		return 0, nil
+	}
+	records, groups, err := m.registry.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, record := range records {
+		if _, exists := m.devices[record.ID]; exists {
+			log.Printf("⚠️ 注册表中的设备 %s 重复，已忽略", record.ID)
+			continue
+		}
+
+		config := maps.Clone(record.Config)
+		if config == nil {
+			config = make(map[string]any)
+		}
+		config["id"] = record.ID
+		if record.HandType != "" {
+			config["hand_type"] = record.HandType
+		}
+
+		dev, err := CreateDevice(record.Model, config)
+		if err != nil {
+			log.Printf("⚠️ 恢复设备 %s 失败: %v", record.ID, err)
+			m.unrestored[record.ID] = record
+			continue
+		}
+		m.devices[record.ID] = dev
+		m.records[record.ID] = record
+		m.addedLocked(dev)
+		restored++
+	}
+
+	// 设备组原样恢复，未能恢复的成员在执行组指令时报告为不存在
+	for _, group := range groups {
+		m.groups[group.ID] = group
+	}
+	return restored, nil
+}
+
+// CreateDevice 创建设备、注册到管理器并保存到注册表
+// 设备在锁外构建，避免连接通信端口等耗时操作阻塞急停等其他调用；并发创建同一 ID 时只保留先注册的设备
+func (m *DeviceManager) CreateDevice(model string, config map[string]any) (Device, error) {
+	id, _ := config["id"].(string)
+
+	m.mutex.RLock()
+	_, exists := m.devices[id]
+	m.mutex.RUnlock()
+	if exists {
+		return nil, fmt.Errorf("设备 %s 已存在", id)
+	}
+
+	dev, err := CreateDevice(model, config)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.devices[id]; exists {
+		closeDevice(dev)
+		return nil, fmt.Errorf("设备 %s 已存在", id)
+	}
+
+	// 注册表中的 config 不重复保存 id 和手型
+	recordConfig := maps.Clone(config)
+	delete(recordConfig, "id")
+	delete(recordConfig, "hand_type")
+
+	unrestored, wasUnrestored := m.unrestored[id]
+	m.devices[id] = dev
+	m.records[id] = DeviceRecord{
+		ID:       id,
+		Model:    model,
+		HandType: dev.GetHandType().Key(),
+		Config:   recordConfig,
+	}
+	delete(m.unrestored, id)
+	if err := m.saveLocked(); err != nil {
+		// 没有保存的设备不对外可见：回滚并释放已创建的设备
+		delete(m.devices, id)
+		delete(m.records, id)
+		if wasUnrestored {
+			m.unrestored[id] = unrestored
+		}
+		closeDevice(dev)
+		return nil, err
+	}
+	m.addedLocked(dev)
+	return dev, nil
+}
+
+// SetHandType 设置设备手型，并更新注册表中的记录
+func (m *DeviceManager) SetHandType(id string, handType define.HandType) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	dev, exists := m.devices[id]
+	if !exists {
+		return fmt.Errorf("设备 %s 不存在", id)
+	}
+	previous := dev.GetHandType()
+	if err := dev.SetHandType(handType); err != nil {
+		return err
+	}
+
+	if record, ok := m.records[id]; ok {
+		updated := record
+		updated.HandType = handType.Key()
+		m.records[id] = updated
+		if err := m.saveLocked(); err != nil {
+			m.records[id] = record
+			if rollbackErr := dev.SetHandType(previous); rollbackErr != nil {
+				log.Printf("⚠️ 恢复设备 %s 的手型失败: %v", id, rollbackErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
 
+// SetPoseFilters 替换设备的姿态滤波器，并更新注册表中的记录
+func (m *DeviceManager) SetPoseFilters(id string, configs []FilterConfig) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	dev, exists := m.devices[id]
+	if !exists {
+		return fmt.Errorf("设备 %s 不存在", id)
+	}
+	filterable, ok := dev.(FilterableDevice)
+	if !ok {
+		return fmt.Errorf("设备 %s (%s) 不支持姿态滤波器", id, dev.GetModel())
+	}
+	previous := filterable.GetPoseFilters()
+	if err := filterable.SetPoseFilters(configs); err != nil {
+		return err
+	}
+
+	if record, ok := m.records[id]; ok {
+		recordConfig := maps.Clone(record.Config)
+		if recordConfig == nil {
+			recordConfig = make(map[string]any)
+		}
+		recordConfig["filters"] = configs
+		updated := record
+		updated.Config = recordConfig
+		m.records[id] = updated
+		if err := m.saveLocked(); err != nil {
+			m.records[id] = record
+			if rollbackErr := filterable.SetPoseFilters(previous); rollbackErr != nil {
+				log.Printf("⚠️ 恢复设备 %s 的姿态滤波器失败: %v", id, rollbackErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshDeviceConfig 将 update 中的字段合并到注册表记录的配置中；有变化时按新配置重新创建设备并替换旧设备，
+// 保留手型和设备组成员关系。没有变化或设备不在注册表中时返回原设备
+func (m *DeviceManager) RefreshDeviceConfig(id string, update map[string]any) (Device, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	dev, exists := m.devices[id]
+	if !exists {
+		return nil, fmt.Errorf("设备 %s 不存在", id)
+	}
+	record, persisted := m.records[id]
+	if !persisted {
+		return dev, nil
+	}
+
+	recordConfig := maps.Clone(record.Config)
+	if recordConfig == nil {
+		recordConfig = make(map[string]any)
+	}
+	changed := false
+	for key, value := range update {
+		if !reflect.DeepEqual(recordConfig[key], value) {
+			recordConfig[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return dev, nil
+	}
+
+	// 先创建新设备，失败时保留旧设备
+	config := maps.Clone(recordConfig)
+	config["id"] = id
+	if record.HandType != "" {
+		config["hand_type"] = record.HandType
+	}
+	replacement, err := CreateDevice(record.Model, config)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := record
+	updated.Config = recordConfig
+	m.records[id] = updated
+	if err := m.saveLocked(); err != nil {
+		m.records[id] = record
+		closeDevice(replacement)
+		return nil, err
+	}
+
+	closeDevice(dev)
+	PublishEvent(Event{Type: EventDeviceRemoved, DeviceID: id, Model: dev.GetModel()})
+	m.devices[id] = replacement
+	m.addedLocked(replacement)
+	log.Printf("🔄 设备 %s 已按新的配置重新创建", id)
+	return replacement, nil
+}
+
+// closeDevice 断开设备并释放后台资源；不支持 Close 的设备只断开，停止后台重连
+func closeDevice(dev Device) {
+	if closable, ok := dev.(ClosableDevice); ok {
+		if err := closable.Close(); err != nil {
+			log.Printf("⚠️ 关闭设备 %s 失败: %v", dev.GetID(), err)
+		}
+	} else if err := dev.Disconnect(); err != nil {
+		log.Printf("⚠️ 断开设备 %s 失败: %v", dev.GetID(), err)
+	}
+}
+
+// addedLocked 发布 EventDeviceAdded，急停期间加入的设备同样锁定，调用方需持有 m.mutex
+func (m *DeviceManager) addedLocked(dev Device) {
+	PublishEvent(Event{Type: EventDeviceAdded, DeviceID: dev.GetID(), Model: dev.GetModel()})
+	m.latchNewDeviceLocked(dev)
+}
+
+// saveLocked 将所有记录写入注册表，调用方需持有 m.mutex
+func (m *DeviceManager) saveLocked() error {
+	if m.registry == nil {
+		return nil
+	}
+
+	records := slices.Collect(maps.Values(m.records))
+	records = slices.AppendSeq(records, maps.Values(m.unrestored))
+	slices.SortFunc(records, func(a, b DeviceRecord) int { return strings.Compare(a.ID, b.ID) })
+
+	groups := slices.Collect(maps.Values(m.groups))
+	slices.SortFunc(groups, func(a, b DeviceGroup) int { return strings.Compare(a.ID, b.ID) })
+	if err := m.registry.Save(records, groups); err != nil {
+		return fmt.Errorf("%w：%w", ErrRegistry, err)
+	}
+	return nil
+}
+
+// RegisterDevice 注册一个已创建的设备，不写入注册表
 func (m *DeviceManager) RegisterDevice(dev Device) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -23,6 +310,7 @@ func (m *DeviceManager) RegisterDevice(dev Device) error {
 	}
 
 	m.devices[id] = dev
+	m.addedLocked(dev)
 	return nil
 }
 
@@ -54,10 +342,38 @@ func (m *DeviceManager) RemoveDevice(id string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, exists := m.devices[id]; !exists {
+	// 注册表写入失败时恢复记录和设备组，设备保持原样
+	groups := maps.Clone(m.groups)
+	dev, exists := m.devices[id]
+	if !exists {
+		// 未能恢复的设备也可以从注册表中删除
+		if record, ok := m.unrestored[id]; ok {
+			delete(m.unrestored, id)
+			m.removeFromGroupsLocked(id)
+			if err := m.saveLocked(); err != nil {
+				m.unrestored[id] = record
+				m.groups = groups
+				return err
+			}
+			return nil
+		}
 		return fmt.Errorf("设备 %s 不存在", id)
 	}
 
+	record, persisted := m.records[id]
+	delete(m.records, id)
+	if m.removeFromGroupsLocked(id) || persisted {
+		if err := m.saveLocked(); err != nil {
+			if persisted {
+				m.records[id] = record
+			}
+			m.groups = groups
+			return err
+		}
+	}
+
+	closeDevice(dev)
 	delete(m.devices, id)
+	PublishEvent(Event{Type: EventDeviceRemoved, DeviceID: id, Model: dev.GetModel()})
 	return nil
 }
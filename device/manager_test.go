@@ -0,0 +1,86 @@
+package device
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hands/define"
+)
+
+// stubDevice 只实现管理器用到的方法的设备
+type stubDevice struct {
+	Device
+	id     string
+	closed atomic.Bool
+}
+
+func (d *stubDevice) GetID() string                { return d.id }
+func (d *stubDevice) GetModel() string             { return "stub" }
+func (d *stubDevice) GetHandType() define.HandType { return define.HAND_TYPE_RIGHT }
+func (d *stubDevice) Disconnect() error            { d.closed.Store(true); return nil }
+
+func TestCreateDeviceBuildsOutsideLock(t *testing.T) {
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var built sync.Map
+	RegisterDeviceType("stub-slow", func(config map[string]any) (Device, error) {
+		entered <- struct{}{}
+		<-release
+		dev := &stubDevice{id: config["id"].(string)}
+		built.Store(dev, struct{}{})
+		return dev, nil
+	})
+
+	m := NewDeviceManager()
+	created := make(chan Device, 2)
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			dev, err := m.CreateDevice("stub-slow", map[string]any{"id": "hand"})
+			created <- dev
+			errs <- err
+		}()
+	}
+
+	// 构建设备期间管理器仍然可用
+	<-entered
+	done := make(chan struct{})
+	go func() {
+		m.GetAllDevices()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("构建设备期间管理器被锁住")
+	}
+	close(release)
+
+	var winner Device
+	failed := 0
+	for range 2 {
+		dev, err := <-created, <-errs
+		if err != nil {
+			failed++
+			continue
+		}
+		winner = dev
+	}
+	if winner == nil || failed != 1 {
+		t.Fatalf("同一 ID 并发创建应只有一个成功，失败 %d 个", failed)
+	}
+	if got, err := m.GetDevice("hand"); err != nil || got != winner {
+		t.Fatalf("管理器中的设备为 %v %v，期望先注册的设备", got, err)
+	}
+
+	// 落选的设备被关闭，注册的设备保持打开
+	built.Range(func(key, _ any) bool {
+		dev := key.(*stubDevice)
+		if closed := dev.closed.Load(); closed == (dev == winner) {
+			t.Errorf("设备 %p 关闭状态为 %v", dev, closed)
+		}
+		return true
+	})
+}
@@ -0,0 +1,343 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"hands/define"
+	"hands/device"
+)
+
+// descriptorCANIDMax 描述文件中 CAN ID 的最大值，即扩展帧 (29 位) ID 的范围
+const descriptorCANIDMax = 0x1FFFFFFF
+
+// ModelDescriptor 由描述文件 (JSON) 定义的型号，不需要编写 Go 代码即可添加新的手部型号
+//
+//	{
+//	  "model": "L6",
+//	  "description": "六自由度手",
+//	  "canIds": {"left": 40, "right": 39},
+//	  "joints": {
+//	    "finger": [{"name": "thumb", "openRaw": 255, "closedRaw": 0, "openDegrees": 0, "closedDegrees": 60}, ...],
+//	    "palm": [...]
+//	  },
+//	  "frames": {"finger": [{"prefix": 1, "joints": 6}], "palm": [{"prefix": 4, "joints": 4}]},
+//	  "queryPrefix": 1,
+//	  "reset": {"finger": [64, 64, 64, 64, 64, 64], "palm": [128, 128, 128, 128]},
+//	  "presets": [{"name": "fist", "description": "握拳姿势", "finger": [...], "palm": [...]}],
+//	  "animations": [{"name": "wave", "steps": [{"finger": [...]}, {"palm": [...]}]}]
+//	}
+type ModelDescriptor struct {
+	Model       string                              `json:"model"`
+	Description string                              `json:"description,omitempty"`
+	CANIDs      *DescriptorCANIDs                   `json:"canIds,omitempty"`      // 为空时左手 0x28、右手 0x27
+	Joints      device.JointCalibrationTable        `json:"joints"`                // 关节名称和标定，数量即关节数
+	Frames      map[device.JointGroup][]FrameLayout `json:"frames"`                // 各关节组按顺序拆分成的帧，指令和反馈使用相同的布局
+	QueryPrefix *byte                               `json:"queryPrefix,omitempty"` // 握手查询帧的前缀，默认为第一帧手指的前缀
+	Reset       DescriptorPose                      `json:"reset"`                 // ResetPose 使用的默认姿态
+	Presets     []DescriptorPreset                  `json:"presets,omitempty"`
+	Animations  []DescriptorAnimation               `json:"animations,omitempty"`
+}
+
+// DescriptorCANIDs 左右手使用的 CAN ID
+type DescriptorCANIDs struct {
+	Left  uint32 `json:"left"`
+	Right uint32 `json:"right"`
+}
+
+// DescriptorPose 描述文件中的一个姿态，手掌可以省略
+type DescriptorPose struct {
+	Finger []byte `json:"finger,omitempty"`
+	Palm   []byte `json:"palm,omitempty"`
+}
+
+// DescriptorPreset 描述文件中的预设姿势
+type DescriptorPreset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	DescriptorPose
+}
+
+// DescriptorAnimation 描述文件中的关键帧动画，每个周期依次发送 Steps，步与步之间间隔动画速度
+type DescriptorAnimation struct {
+	Name  string           `json:"name"`
+	Steps []DescriptorPose `json:"steps"`
+}
+
+// LoadModelDescriptors 加载目录中所有 .json 描述文件并注册为设备型号，返回注册的型号
+// 目录不存在时不加载任何型号；任一文件无效或型号已注册时返回错误
+func LoadModelDescriptors(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取型号目录失败：%w", err)
+	}
+
+	var loaded []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		descriptor, err := ReadModelDescriptor(path)
+		if err != nil {
+			return loaded, fmt.Errorf("加载型号描述文件 %s 失败：%w", path, err)
+		}
+		if slices.Contains(device.GetSupportedModels(), descriptor.Model) {
+			return loaded, fmt.Errorf("加载型号描述文件 %s 失败：型号 %s 已注册", path, descriptor.Model)
+		}
+
+		RegisterModelDescriptor(descriptor)
+		loaded = append(loaded, descriptor.Model)
+		log.Printf("🧩 已从 %s 注册型号 %s", path, descriptor.Model)
+	}
+	return loaded, nil
+}
+
+// ReadModelDescriptor 读取并校验一个描述文件，不允许未知字段
+func ReadModelDescriptor(path string) (*ModelDescriptor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var descriptor ModelDescriptor
+	if err := decoder.Decode(&descriptor); err != nil {
+		return nil, fmt.Errorf("解析描述文件失败：%w", err)
+	}
+	if err := descriptor.Validate(); err != nil {
+		return nil, err
+	}
+	return &descriptor, nil
+}
+
+// RegisterModelDescriptor 把描述文件定义的型号注册到设备工厂，并注册其关节标定表和能力描述
+func RegisterModelDescriptor(descriptor *ModelDescriptor) {
+	registerHandModel(descriptor.NewHand, descriptor.Joints, descriptor.spec())
+}
+
+// Validate 检查描述文件的关节、帧布局、姿态长度是否一致
+func (d *ModelDescriptor) Validate() error {
+	if d.Model == "" {
+		return fmt.Errorf("缺少型号名称 model")
+	}
+	if len(d.Joints.Finger) == 0 {
+		return fmt.Errorf("型号 %s 至少需要一个手指关节", d.Model)
+	}
+
+	for group := range d.Frames {
+		if group != device.JointGroupFinger && group != device.JointGroupPalm {
+			return fmt.Errorf("未知的关节组：%s", group)
+		}
+	}
+
+	prefixes := make(map[byte]bool)
+	for _, group := range []device.JointGroup{device.JointGroupFinger, device.JointGroupPalm} {
+		joints := d.Joints.Joints(group)
+		for i, joint := range joints {
+			if joint.Name == "" {
+				return fmt.Errorf("%s[%d] 缺少关节名称", group, i)
+			}
+			if joint.OpenRaw == joint.ClosedRaw || joint.OpenDegrees == joint.ClosedDegrees {
+				return fmt.Errorf("关节 %s 的标定无效：张开和闭合的值不能相同", joint.Name)
+			}
+		}
+
+		total := 0
+		for _, frame := range d.Frames[group] {
+			// 经典 CAN 帧最多 8 字节，其中 1 字节为前缀
+			if frame.Joints < 1 || frame.Joints > 7 {
+				return fmt.Errorf("前缀 0x%02X 的帧关节数 %d 无效，范围为 1-7", frame.Prefix, frame.Joints)
+			}
+			if prefixes[frame.Prefix] {
+				return fmt.Errorf("帧前缀 0x%02X 重复", frame.Prefix)
+			}
+			prefixes[frame.Prefix] = true
+			total += frame.Joints
+		}
+		if total != len(joints) {
+			return fmt.Errorf("%s 的帧共携带 %d 个关节，与关节数 %d 不一致", group, total, len(joints))
+		}
+	}
+
+	if d.QueryPrefix != nil && !prefixes[*d.QueryPrefix] {
+		return fmt.Errorf("握手查询前缀 0x%02X 不是已声明的帧前缀", *d.QueryPrefix)
+	}
+
+	if d.CANIDs != nil {
+		if d.CANIDs.Left == 0 || d.CANIDs.Right == 0 || d.CANIDs.Left == d.CANIDs.Right {
+			return fmt.Errorf("左右手的 CAN ID 必须非零且不同")
+		}
+		if d.CANIDs.Left > descriptorCANIDMax || d.CANIDs.Right > descriptorCANIDMax {
+			return fmt.Errorf("CAN ID 超出 29 位范围 (最大 0x%X)", descriptorCANIDMax)
+		}
+	}
+
+	if err := d.checkPose("reset", d.Reset, true); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, preset := range d.Presets {
+		if preset.Name == "" || names[preset.Name] {
+			return fmt.Errorf("预设姿势名称 %q 为空或重复", preset.Name)
+		}
+		names[preset.Name] = true
+		if err := d.checkPose("预设姿势 "+preset.Name, preset.DescriptorPose, false); err != nil {
+			return err
+		}
+	}
+
+	names = make(map[string]bool)
+	for _, animation := range d.Animations {
+		if animation.Name == "" || names[animation.Name] {
+			return fmt.Errorf("动画名称 %q 为空或重复", animation.Name)
+		}
+		names[animation.Name] = true
+		if len(animation.Steps) == 0 {
+			return fmt.Errorf("动画 %s 没有关键帧", animation.Name)
+		}
+		for i, step := range animation.Steps {
+			if step.Finger == nil && step.Palm == nil {
+				return fmt.Errorf("动画 %s 的第 %d 帧为空", animation.Name, i+1)
+			}
+			if err := d.checkPose(fmt.Sprintf("动画 %s 的第 %d 帧", animation.Name, i+1), step, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkPose 检查姿态长度，required 为 true 时必须包含手指姿态，有手掌关节时还必须包含手掌姿态
+func (d *ModelDescriptor) checkPose(what string, pose DescriptorPose, required bool) error {
+	fingers, palms := len(d.Joints.Finger), len(d.Joints.Palm)
+	if (required || pose.Finger != nil) && len(pose.Finger) != fingers {
+		return fmt.Errorf("%s 的手指姿态需要 %d 个值，实际为 %d 个", what, fingers, len(pose.Finger))
+	}
+	if (required && palms > 0 || pose.Palm != nil) && len(pose.Palm) != palms {
+		return fmt.Errorf("%s 的手掌姿态需要 %d 个值，实际为 %d 个", what, palms, len(pose.Palm))
+	}
+	return nil
+}
+
+// jointCounts 各关节组的关节数
+func (d *ModelDescriptor) jointCounts() map[device.JointGroup]int {
+	return map[device.JointGroup]int{
+		device.JointGroupFinger: len(d.Joints.Finger),
+		device.JointGroupPalm:   len(d.Joints.Palm),
+	}
+}
+
+// canIDs 描述文件中的 CAN ID，未配置时为空，使用手型的默认值
+func (d *ModelDescriptor) canIDs() map[define.HandType]uint32 {
+	if d.CANIDs == nil {
+		return nil
+	}
+	return map[define.HandType]uint32{
+		define.HAND_TYPE_LEFT:  d.CANIDs.Left,
+		define.HAND_TYPE_RIGHT: d.CANIDs.Right,
+	}
+}
+
+// NewHand 按描述文件创建设备实例，作为设备工厂的构造函数
+// 参数 config 的字段与 L20 相同 (见 newHand)，不支持 can_fd 和 finger_speed
+func (d *ModelDescriptor) NewHand(config map[string]any) (device.Device, error) {
+	if canFD, _ := config["can_fd"].(bool); canFD {
+		return nil, fmt.Errorf("%s 不支持 CAN FD", d.Model)
+	}
+
+	base, err := newHand(config, d.spec())
+	if err != nil {
+		return nil, err
+	}
+	base.start()
+
+	log.Printf("✅ 设备 %s (%s, %s) 创建成功", d.Model, base.id, base.handType.String())
+	return &DescriptorHand{hand: base, descriptor: d}, nil
+}
+
+// spec 描述文件对应的型号描述，每次调用创建新的协议实例
+func (d *ModelDescriptor) spec() handSpec {
+	query := d.Frames[device.JointGroupFinger][0].Prefix
+	if d.QueryPrefix != nil {
+		query = *d.QueryPrefix
+	}
+
+	presets := make([]device.PresetPose, 0, len(d.Presets))
+	for _, preset := range d.Presets {
+		presets = append(presets, device.PresetPose{
+			Name:        preset.Name,
+			Description: preset.Description,
+			FingerPose:  preset.Finger,
+			PalmPose:    preset.Palm,
+		})
+	}
+	animations := make([]device.Animation, 0, len(d.Animations))
+	for _, animation := range d.Animations {
+		animations = append(animations, &keyframeAnimation{name: animation.Name, steps: animation.Steps})
+	}
+
+	return handSpec{
+		model:       d.Model,
+		joints:      d.jointCounts(),
+		protocol:    newFramedProtocol(d.Model, d.Frames, query),
+		canIDs:      d.canIDs(),
+		resetFinger: d.Reset.Finger,
+		resetPalm:   d.Reset.Palm,
+		presets:     presets,
+		animations:  animations,
+	}
+}
+
+// DescriptorHand 由描述文件定义的型号的设备实现
+type DescriptorHand struct {
+	*hand
+	descriptor *ModelDescriptor
+}
+
+// keyframeAnimation 描述文件中的关键帧动画
+type keyframeAnimation struct {
+	name  string
+	steps []DescriptorPose
+}
+
+func (a *keyframeAnimation) Name() string { return a.name }
+
+func (a *keyframeAnimation) Run(executor device.PoseExecutor, stop <-chan struct{}, speedMs int) error {
+	delay := time.Duration(speedMs) * time.Millisecond
+
+	for _, step := range a.steps {
+		if step.Finger != nil {
+			if err := executor.SetFingerPose(step.Finger); err != nil {
+				log.Printf("❌ %s 动画 %s 发送失败: %v", animationTarget(executor), a.name, err)
+				return err
+			}
+		}
+		if step.Palm != nil {
+			if err := executor.SetPalmPose(step.Palm); err != nil {
+				log.Printf("❌ %s 动画 %s 发送失败: %v", animationTarget(executor), a.name, err)
+				return err
+			}
+		}
+		if !waitAnimationStep(stop, delay) {
+			return nil // 动画被停止
+		}
+	}
+
+	return nil // 完成一个周期
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModelDescriptorValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(d *ModelDescriptor)
+		wantErr string
+	}{
+		{name: "示例描述文件", modify: func(d *ModelDescriptor) {}},
+		{name: "查询前缀为手掌帧", modify: func(d *ModelDescriptor) { d.QueryPrefix = ptr(byte(4)) }},
+		{name: "查询前缀未声明", modify: func(d *ModelDescriptor) { d.QueryPrefix = ptr(byte(9)) }, wantErr: "握手查询前缀 0x09"},
+		{name: "最大扩展帧 ID", modify: func(d *ModelDescriptor) { d.CANIDs.Left = 0x1FFFFFFF }},
+		{name: "左手 CAN ID 超出 29 位", modify: func(d *ModelDescriptor) { d.CANIDs.Left = 0x20000000 }, wantErr: "超出 29 位"},
+		{name: "右手 CAN ID 超出 29 位", modify: func(d *ModelDescriptor) { d.CANIDs.Right = 0xFFFFFFFF }, wantErr: "超出 29 位"},
+		{name: "左右手 CAN ID 相同", modify: func(d *ModelDescriptor) { d.CANIDs.Right = d.CANIDs.Left }, wantErr: "非零且不同"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			descriptor, err := ReadModelDescriptor("../../docs/model-descriptor.example.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(descriptor)
+
+			err = descriptor.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("期望校验通过，得到 %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("错误为 %v，期望包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
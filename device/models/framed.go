@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+
+	"hands/communication"
+	"hands/device"
+)
+
+// FrameLayout 一帧 CAN 数据的布局：前缀 + Joints 个关节各一个字节
+type FrameLayout struct {
+	Prefix byte `json:"prefix"`
+	Joints int  `json:"joints"`
+}
+
+// framedProtocol 按固定布局把每个关节组拆成若干帧的通用协议，L20 和描述文件定义的型号使用它
+// 反馈帧与指令帧布局相同，凑齐一个关节组的所有帧后才返回整组的实际位置
+type framedProtocol struct {
+	name     string                              // 型号名称，用于错误信息
+	layout   map[device.JointGroup][]FrameLayout // 各关节组按顺序拆分成的帧
+	query    byte                                // 握手查询帧的前缀
+	feedback map[byte][]byte                     // 各前缀最近一次反馈的数据
+}
+
+func newFramedProtocol(name string, layout map[device.JointGroup][]FrameLayout, query byte) *framedProtocol {
+	return &framedProtocol{name: name, layout: layout, query: query, feedback: make(map[byte][]byte)}
+}
+
+// jointCount 返回关节组在布局中的关节总数
+func (p *framedProtocol) jointCount(group device.JointGroup) int {
+	n := 0
+	for _, frame := range p.layout[group] {
+		n += frame.Joints
+	}
+	return n
+}
+
+// commandTypes 布局中有帧的关节组各支持一种姿态指令
+func (p *framedProtocol) commandTypes() []string {
+	commands := []string{"SetFingerPose"}
+	if len(p.layout[device.JointGroupPalm]) > 0 {
+		commands = append(commands, "SetPalmPose")
+	}
+	return commands
+}
+
+// poseCommands 手指和手掌各一条指令，每条指令再拆成多帧
+func (p *framedProtocol) poseCommands(finger *device.FingerPoseCommand, palm *device.PalmPoseCommand) []device.Command {
+	if palm == nil {
+		return []device.Command{finger}
+	}
+	return []device.Command{finger, palm}
+}
+
+// encodeCommand 按布局把一个关节组拆成多帧
+func (p *framedProtocol) encodeCommand(cmd device.Command) ([]communication.RawMessage, error) {
+	var group device.JointGroup
+	switch cmd.Type() {
+	case "SetFingerPose":
+		group = device.JointGroupFinger
+	case "SetPalmPose":
+		group = device.JointGroupPalm
+	default:
+		return nil, fmt.Errorf("%s 不支持的指令类型: %s", p.name, cmd.Type())
+	}
+
+	payload := cmd.Payload()
+	if n := p.jointCount(group); len(payload) != n {
+		return nil, fmt.Errorf("无效的 %s 姿态数据长度 %d，需要 %d 个字节", group, len(payload), n)
+	}
+
+	frames := p.layout[group]
+	msgs := make([]communication.RawMessage, 0, len(frames))
+	offset := 0
+	for _, frame := range frames {
+		chunk := payload[offset : offset+frame.Joints]
+		offset += frame.Joints
+		msgs = append(msgs, communication.RawMessage{Data: append([]byte{frame.Prefix}, chunk...)})
+	}
+	return msgs, nil
+}
+
+// queryMessage 只含查询前缀的帧
+func (p *framedProtocol) queryMessage() communication.RawMessage {
+	return communication.RawMessage{Data: []byte{p.query}}
+}
+
+// decodeFeedback 记录一帧反馈，该帧所属关节组的所有帧都收到过后返回整组的实际位置
+func (p *framedProtocol) decodeFeedback(data []byte) (device.JointGroup, []byte, bool) {
+	prefix, payload := data[0], data[1:]
+	for group, frames := range p.layout {
+		i := slices.IndexFunc(frames, func(frame FrameLayout) bool { return frame.Prefix == prefix })
+		if i < 0 {
+			continue
+		}
+		if len(payload) != frames[i].Joints {
+			return "", nil, false
+		}
+		p.feedback[prefix] = slices.Clone(payload)
+
+		pose := make([]byte, 0, p.jointCount(group))
+		for _, frame := range frames {
+			chunk, ok := p.feedback[frame.Prefix]
+			if !ok {
+				return "", nil, false
+			}
+			pose = append(pose, chunk...)
+		}
+		return group, pose, true
+	}
+	return "", nil, false
+}
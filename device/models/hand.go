@@ -0,0 +1,975 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"hands/communication"
+	"hands/component"
+	"hands/define"
+	"hands/device"
+)
+
+// interFrameDelay 同一动作中连续指令之间的间隔
+const interFrameDelay = 20 * time.Millisecond
+
+// directOrigin 直接调用设备方法（不经过 WithOrigin）时的指令来源
+var directOrigin = device.CommandOrigin{Source: device.SourceDevice, Priority: device.PriorityManual}
+
+// handProtocol 型号相关的 CAN 帧编解码，由各型号实现，hand 负责其余的通用逻辑
+type handProtocol interface {
+	// commandTypes 返回型号支持的指令类型，用于能力描述
+	commandTypes() []string
+	// poseCommands 返回把手指和手掌姿态作为一个动作发送的指令，palm 为空时只发送手指姿态
+	poseCommands(finger *device.FingerPoseCommand, palm *device.PalmPoseCommand) []device.Command
+	// encodeCommand 将一条指令编码为一帧或多帧 CAN 消息，调用方填写接口和 CAN ID
+	encodeCommand(cmd device.Command) ([]communication.RawMessage, error)
+	// queryMessage 握手时发送的查询帧，设备以相同前缀应答，调用方填写接口和 CAN ID
+	queryMessage() communication.RawMessage
+	// decodeFeedback 解码一帧设备反馈，得到一个关节组的完整实际位置时返回 true，调用方持有 h.mutex
+	decodeFeedback(data []byte) (device.JointGroup, []byte, bool)
+}
+
+// handSpec 创建 hand 时型号提供的描述
+type handSpec struct {
+	model       string
+	joints      map[device.JointGroup]int // 各关节组的关节数
+	protocol    handProtocol
+	canIDs      map[define.HandType]uint32 // 各手型使用的 CAN ID，为空时使用手型的值
+	resetFinger []byte                     // ResetPose 使用的默认手指姿态
+	resetPalm   []byte                     // ResetPose 使用的默认手掌姿态
+	presets     []device.PresetPose
+	animations  []device.Animation
+}
+
+// capabilities 由型号描述和关节标定表生成能力描述，关节范围为 0-255
+func (s handSpec) capabilities(calibration device.JointCalibrationTable) device.Capabilities {
+	capabilities := device.NewCapabilities(s.model, s.joints, calibration)
+	capabilities.Commands = s.protocol.commandTypes()
+	capabilities.Presets = make([]string, 0, len(s.presets))
+	for _, preset := range s.presets {
+		capabilities.Presets = append(capabilities.Presets, preset.Name)
+	}
+	capabilities.Animations = make([]string, 0, len(s.animations))
+	for _, animation := range s.animations {
+		capabilities.Animations = append(capabilities.Animations, animation.Name())
+	}
+	return capabilities
+}
+
+// hand 各型号手部设备的通用实现：连接状态机、关节限制、滤波、预设、动画和反馈，
+// 型号只需提供 handProtocol 和关节布局
+type hand struct {
+	id              string
+	model           string
+	handType        define.HandType
+	communicator    communication.Communicator
+	components      map[device.ComponentType][]device.Component
+	status          device.DeviceStatus
+	mutex           sync.RWMutex
+	canInterface    string                            // CAN 接口名称，如 "can0"
+	animationEngine *device.AnimationEngine           // 动画引擎
+	presetManager   *device.PresetManager             // 预设姿势管理器
+	feedbackSub     communication.Subscription        // 设备反馈帧订阅
+	health          *communication.HealthMonitor      // 通信客户端的健康监视器，可能为空
+	healthSub       *communication.HealthSubscription // 健康事件订阅
+	connectMutex    sync.Mutex                        // 保证同一时间只有一次连接尝试
+	reconnectStop   chan struct{}                     // 停止后台自动重连，为空表示没有在重连
+	protocol        handProtocol                      // 型号的 CAN 帧编解码
+	canIDs          map[define.HandType]uint32        // 各手型使用的 CAN ID，为空时使用手型的值
+	resetFinger     []byte                            // 默认手指姿态
+	resetPalm       []byte                            // 默认手掌姿态
+	safePose        device.SafePose                   // 急停和 watchdog 超时时的安全姿态，未配置的关节组使用默认姿态
+	watchdog        *device.Watchdog                  // 控制会话 watchdog，没有配置时为空
+	limits          *device.SafetyEnvelope            // 关节限制，所有姿态指令发送前都要经过检查
+	filters         *device.FilterPipeline            // 姿态滤波管道，在关节限制之前应用
+	capabilities    device.Capabilities               // 能力描述，关节范围反映 joint_limits，所有姿态按它校验
+	queue           *device.CommandQueue              // 异步指令队列，所有指令都由它的发送 goroutine 经 sendCommands 发送
+	history         *device.CommandHistory            // 指令历史，记录每个动作的来源、内容和结果
+}
+
+// newHand 解析各型号共用的配置并创建 hand，返回后需调用 start 开始监听反馈并连接
+// 共用的配置字段：
+//   - id: 设备 ID
+//   - transport、can_service_url、can_service_urls、serial_port 等：见 communication.NewCommunicatorFromConfig
+//   - can_interface: CAN 接口名称，如 "can0"
+//   - hand_type: 手型，可选值为 "left" 或 "right"，默认值为 "right"
+//   - joint_limits: 关节范围、单步最大变化量和 clamp/reject 模式，见 device.LimitConfig
+//   - filters: 姿态滤波器列表 (jitter、clamp、calibration、smoothing)，按顺序应用，默认不做任何变换，见 device.FilterConfig
+//   - safe_pose: 急停时驱动到的安全姿态，默认为型号的默认姿态，见 device.SafePose
+//   - watchdog: 控制会话的心跳超时，超时后停止动画并驱动到安全姿态，默认不启用，见 device.WatchdogConfig
+func newHand(config map[string]any, spec handSpec) (*hand, error) {
+	id, ok := config["id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("缺少设备 ID 配置")
+	}
+
+	canInterface, ok := config["can_interface"].(string)
+	if !ok {
+		canInterface = "can0" // 默认接口
+	}
+
+	handTypeStr, ok := config["hand_type"].(string)
+	handType := define.HAND_TYPE_RIGHT // 默认右手
+	if ok && handTypeStr == "left" {
+		handType = define.HAND_TYPE_LEFT
+	}
+
+	limits, err := device.NewSafetyEnvelopeFromConfig(config, spec.joints)
+	if err != nil {
+		return nil, err
+	}
+
+	filterConfigs, err := device.FilterConfigsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := device.NewFilterPipeline(filterConfigs, spec.joints)
+	if err != nil {
+		return nil, err
+	}
+
+	safePose, err := device.SafePoseFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	watchdogConfig, err := device.WatchdogConfigFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建通信客户端
+	comm, err := communication.NewCommunicatorFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("创建通信客户端失败：%w", err)
+	}
+	if fd, ok := spec.protocol.(device.CANFDDevice); ok && fd.RequiresCANFD() && !communication.SupportsFD(comm) {
+		return nil, fmt.Errorf("设备 %s 需要 CAN FD，但当前传输方式不支持", id)
+	}
+
+	h := &hand{
+		id:           id,
+		model:        spec.model,
+		handType:     handType,
+		communicator: comm,
+		components:   make(map[device.ComponentType][]device.Component),
+		canInterface: canInterface,
+		protocol:     spec.protocol,
+		canIDs:       spec.canIDs,
+		resetFinger:  spec.resetFinger,
+		resetPalm:    spec.resetPalm,
+		limits:       limits,
+		filters:      filters,
+		status: device.DeviceStatus{
+			// 创建后立即在后台连接，握手完成前不接受指令
+			State:      device.StateConnecting,
+			IsActive:   true,
+			LastUpdate: time.Now(),
+			Pose:       device.PoseValues{Unit: device.UnitRaw},
+		},
+	}
+
+	calibration, _ := device.GetJointCalibration(spec.model)
+	h.capabilities = spec.capabilities(calibration)
+	for _, group := range []device.JointGroup{device.JointGroupFinger, device.JointGroupPalm} {
+		h.capabilities = h.capabilities.WithLimits(group, limits.Limits(group))
+	}
+
+	// 安全姿态发送时绕过滤波和单步变化量限制，因此在这里按能力描述和关节范围校验
+	if safePose.Finger, err = h.resolveSafePose(device.JointGroupFinger, safePose.Finger, spec.resetFinger); err != nil {
+		return nil, err
+	}
+	if safePose.Palm, err = h.resolveSafePose(device.JointGroupPalm, safePose.Palm, spec.resetPalm); err != nil {
+		return nil, err
+	}
+	h.safePose = safePose
+	h.watchdog = device.NewWatchdog(id, watchdogConfig, h.watchdogTrip)
+
+	if monitor, ok := communication.As[*communication.HealthMonitor](comm); ok {
+		h.health = monitor
+	}
+
+	// 指令队列的发送 goroutine 发送时不持有 h.mutex，通信阻塞不影响读取状态
+	h.history = device.NewCommandHistory(device.DefaultHistorySize)
+	h.queue = device.NewCommandQueue(id, h.history, h.sendCommands)
+
+	// 初始化动画引擎，动画帧通过 WithOrigin 以动画名称为来源提交到 animation 通道
+	h.animationEngine = device.NewAnimationEngine(h)
+	for _, animation := range spec.animations {
+		h.animationEngine.Register(animation)
+	}
+
+	// 初始化预设姿势管理器
+	h.presetManager = device.NewPresetManager()
+	for _, preset := range spec.presets {
+		h.presetManager.RegisterPreset(preset)
+	}
+
+	// 初始化组件
+	if err := h.initializeComponents(config); err != nil {
+		return nil, fmt.Errorf("初始化组件失败：%w", err)
+	}
+
+	return h, nil
+}
+
+// resolveSafePose 校验关节组配置的安全姿态，超出关节范围时报错；未配置时使用默认姿态并限制到关节范围内
+func (h *hand) resolveSafePose(group device.JointGroup, configured, fallback []byte) ([]byte, error) {
+	if configured == nil {
+		pose, violations := h.limits.ClampToRange(group, fallback)
+		if len(violations) > 0 {
+			log.Printf("ℹ️ 设备 %s 的默认 %s 姿态超出关节限制，安全姿态已限制: %v", h.id, group, violations)
+		}
+		return pose, nil
+	}
+
+	if err := h.capabilities.ValidatePose(group, configured); err != nil {
+		return nil, fmt.Errorf("无效的 safe_pose 配置：%w", err)
+	}
+	if _, violations := h.limits.ClampToRange(group, configured); len(violations) > 0 {
+		return nil, fmt.Errorf("无效的 safe_pose 配置：%w：%v", device.ErrJointLimit, violations)
+	}
+	return configured, nil
+}
+
+// start 订阅反馈帧和健康事件，并在后台与设备握手
+func (h *hand) start() {
+	// 订阅设备反馈帧
+	h.startFeedbackListener()
+
+	// 跟踪 CAN 接口的可用性
+	h.startHealthListener()
+
+	// 检查接口并与设备握手，失败时自动重连
+	go h.tryConnect()
+}
+
+// canIDFor 返回手型对应的 CAN ID
+func (h *hand) canIDFor(handType define.HandType) uint32 {
+	if id, ok := h.canIDs[handType]; ok {
+		return id
+	}
+	return uint32(handType)
+}
+
+// GetHandType 获取设备手型
+func (h *hand) GetHandType() define.HandType {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.handType
+}
+
+// SetHandType 设置设备手型
+func (h *hand) SetHandType(handType define.HandType) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if handType != define.HAND_TYPE_LEFT && handType != define.HAND_TYPE_RIGHT {
+		return fmt.Errorf("无效的手型：%d", handType)
+	}
+	h.handType = handType
+	log.Printf("🔧 设备 %s 手型已更新: %s", h.id, handType.String())
+	device.PublishEvent(device.Event{Type: device.EventHandTypeChanged, DeviceID: h.id, HandType: handType.Key()})
+	return nil
+}
+
+// GetAnimationEngine 获取动画引擎
+func (h *hand) GetAnimationEngine() *device.AnimationEngine {
+	return h.animationEngine
+}
+
+// SetFingerPose 设置手指姿态 (实现 PoseExecutor)，通过 manual 通道发送
+func (h *hand) SetFingerPose(pose []byte) error {
+	return h.setFingerPose(directOrigin, pose)
+}
+
+// SetPalmPose 设置手掌姿态 (实现 PoseExecutor)，通过 manual 通道发送
+func (h *hand) SetPalmPose(pose []byte) error {
+	return h.setPalmPose(directOrigin, pose)
+}
+
+func (h *hand) setFingerPose(origin device.CommandOrigin, pose []byte) error {
+	cmd, err := h.newFingerPoseCommand(pose)
+	if err != nil {
+		return err
+	}
+
+	// 执行指令
+	err = h.executeCommands(device.CommandRequest{
+		Origin:   origin,
+		Commands: []device.Command{cmd},
+		Action:   "finger",
+		Input:    device.HistoryPose{Finger: slices.Clone(pose)},
+	})
+	if err == nil {
+		h.logFingerPose(cmd.Payload())
+	}
+	return err
+}
+
+func (h *hand) setPalmPose(origin device.CommandOrigin, pose []byte) error {
+	cmd, err := h.newPalmPoseCommand(pose)
+	if err != nil {
+		return err
+	}
+
+	// 执行指令
+	err = h.executeCommands(device.CommandRequest{
+		Origin:   origin,
+		Commands: []device.Command{cmd},
+		Action:   "palm",
+		Input:    device.HistoryPose{Palm: slices.Clone(pose)},
+	})
+	if err == nil {
+		h.logPalmPose(cmd.Payload())
+	}
+	return err
+}
+
+// newFingerPoseCommand 校验手指姿态并经过滤波管道，生成手指姿态指令
+func (h *hand) newFingerPoseCommand(pose []byte) (*device.FingerPoseCommand, error) {
+	if err := h.capabilities.ValidatePose(device.JointGroupFinger, pose); err != nil {
+		return nil, err
+	}
+	return device.NewFingerPoseCommand(h.filters.Apply(device.JointGroupFinger, pose)), nil
+}
+
+// newPalmPoseCommand 校验手掌姿态并经过滤波管道，生成手掌姿态指令
+func (h *hand) newPalmPoseCommand(pose []byte) (*device.PalmPoseCommand, error) {
+	if err := h.capabilities.ValidatePose(device.JointGroupPalm, pose); err != nil {
+		return nil, err
+	}
+	return device.NewPalmPoseCommand(h.filters.Apply(device.JointGroupPalm, pose)), nil
+}
+
+// GetCapabilities 获取设备的能力描述，关节范围反映设备配置的 joint_limits
+func (h *hand) GetCapabilities() device.Capabilities { return h.capabilities }
+
+// CheckJointLimits 按关节限制预检姿态，不发送也不计入违规统计
+func (h *hand) CheckJointLimits(group device.JointGroup, pose []byte) error {
+	return h.limits.Check(group, pose)
+}
+
+// GetPoseFilters 获取当前的姿态滤波器配置
+func (h *hand) GetPoseFilters() []device.FilterConfig { return h.filters.Configs() }
+
+// SetPoseFilters 在运行时替换姿态滤波器
+func (h *hand) SetPoseFilters(configs []device.FilterConfig) error {
+	if err := h.filters.SetConfigs(configs); err != nil {
+		return err
+	}
+	log.Printf("🔧 设备 %s 姿态滤波器已更新: %d 个", h.id, len(configs))
+	return nil
+}
+
+func (h *hand) logFingerPose(pose []byte) {
+	log.Printf("✅ %s (%s) 手指动作已发送: [% X]", h.id, h.GetHandType().String(), pose)
+}
+
+func (h *hand) logPalmPose(pose []byte) {
+	log.Printf("✅ %s (%s) 掌部姿态已发送: [% X]", h.id, h.GetHandType().String(), pose)
+}
+
+// setFullPose 将手指和手掌姿态作为一个动作发送；palmPose 为空时只发送手指姿态
+// 具体合并为几条指令由型号决定，action 记录在指令历史中
+func (h *hand) setFullPose(origin device.CommandOrigin, action string, fingerPose, palmPose []byte) error {
+	fingerCmd, err := h.newFingerPoseCommand(fingerPose)
+	if err != nil {
+		return err
+	}
+
+	var palmCmd *device.PalmPoseCommand
+	if len(palmPose) > 0 {
+		if palmCmd, err = h.newPalmPoseCommand(palmPose); err != nil {
+			return err
+		}
+	}
+
+	err = h.executeCommands(device.CommandRequest{
+		Origin:   origin,
+		Commands: h.protocol.poseCommands(fingerCmd, palmCmd),
+		Action:   action,
+		Input:    device.HistoryPose{Finger: slices.Clone(fingerPose), Palm: slices.Clone(palmPose)},
+	})
+	if err != nil {
+		return err
+	}
+
+	h.logFingerPose(fingerCmd.Payload())
+	if palmCmd != nil {
+		h.logPalmPose(palmCmd.Payload())
+	}
+	return nil
+}
+
+// ResetPose 重置到默认姿态 (实现 PoseExecutor)，通过 manual 通道发送
+func (h *hand) ResetPose() error {
+	return h.resetPose(directOrigin)
+}
+
+func (h *hand) resetPose(origin device.CommandOrigin) error {
+	log.Printf("🔄 正在重置设备 %s (%s) 到默认姿态...", h.id, h.GetHandType().String())
+
+	if err := h.setFullPose(origin, "reset", h.resetFinger, h.resetPalm); err != nil {
+		log.Printf("❌ %s 重置姿势失败: %v", h.id, err)
+		return err
+	}
+	log.Printf("✅ 设备 %s 已重置到默认姿态", h.id)
+	return nil
+}
+
+// commandToRawMessagesUnsafe 将通用指令转换为型号特定的 CAN 消息（不加锁版本）
+// 注意：此方法不是线程安全的，只应在已获取适当锁的情况下调用
+func (h *hand) commandToRawMessagesUnsafe(cmd device.Command) ([]communication.RawMessage, error) {
+	msgs, err := h.protocol.encodeCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range msgs {
+		msgs[i].Interface = h.canInterface
+		msgs[i].ID = h.canIDFor(h.handType)
+		msgs[i].Idempotent = true // 姿态帧携带的是绝对位置，重复发送没有副作用
+		msgs[i].Coalesce = true   // 限速等待期间只需发送最新的姿态
+		if err := msgs[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s 指令无效：%w", cmd.Type(), err)
+		}
+	}
+	return msgs, nil
+}
+
+// ExecuteCommand 执行一个通用指令
+func (h *hand) ExecuteCommand(cmd device.Command) error {
+	return h.executeCommand(directOrigin, cmd)
+}
+
+func (h *hand) executeCommand(origin device.CommandOrigin, cmd device.Command) error {
+	fingerPose, palmPose := posePayloads(cmd)
+	return h.executeCommands(device.CommandRequest{
+		Origin:   origin,
+		Commands: []device.Command{cmd},
+		Action:   "command",
+		Input:    device.HistoryPose{Finger: slices.Clone(fingerPose), Palm: slices.Clone(palmPose)},
+	})
+}
+
+// executeCommands 将一组指令作为一个逻辑动作提交到指令队列的 req.Origin.Priority 通道，并等待发送结果
+// 设备当前不接受指令时直接返回错误，不进入队列，只记录到指令历史
+func (h *hand) executeCommands(req device.CommandRequest) error {
+	h.mutex.RLock()
+	state := h.status.State
+	h.mutex.RUnlock()
+
+	if !state.AcceptsCommands() {
+		err := fmt.Errorf("设备 %s 当前状态为 %s，无法发送指令", h.id, state)
+		entry := device.NewHistoryEntry(req)
+		entry.Result = device.ResultRejected
+		entry.Error = err.Error()
+		entry.Seq = h.history.Add(entry)
+		device.PublishEvent(device.Event{Type: device.EventCommand, DeviceID: h.id, Command: &entry})
+		return err
+	}
+	return h.queue.Execute(req)
+}
+
+// sendCommands 发送一个动作，只由指令队列的发送 goroutine 调用。多帧通过 SendBatch 一次交给传输层，
+// 指令之间保持 interFrameDelay 间隔，同一条指令的多帧连续发送；等待传输层时不持有 h.mutex
+// 经过关节限制的姿态和编码后的 CAN 帧填写到 entry
+func (h *hand) sendCommands(req device.CommandRequest, entry *device.HistoryEntry) error {
+	cmds := req.Commands
+	h.mutex.Lock()
+	if !h.status.State.AcceptsCommands() {
+		state := h.status.State
+		h.mutex.Unlock()
+		return fmt.Errorf("设备 %s 当前状态为 %s，无法发送指令", h.id, state)
+	}
+
+	// 所有姿态指令（API、预设、动画）都在这里经过关节限制；安全姿态已在创建设备时校验，必须一次到位
+	var err error
+	if !req.SafePose {
+		if cmds, err = h.enforceLimits(cmds); err != nil {
+			h.status.LastError = err.Error()
+			h.mutex.Unlock()
+			return err
+		}
+	}
+
+	for _, cmd := range cmds {
+		fingerPose, palmPose := posePayloads(cmd)
+		if fingerPose != nil {
+			entry.Output.Finger = fingerPose
+		}
+		if palmPose != nil {
+			entry.Output.Palm = palmPose
+		}
+	}
+
+	// 转换指令为 CAN 消息（使用不加锁版本，因为已经在写锁保护下）
+	var frames []communication.BatchFrame
+	for i, cmd := range cmds {
+		msgs, err := h.commandToRawMessagesUnsafe(cmd)
+		if err != nil {
+			h.recordErrorLocked(err)
+			h.mutex.Unlock()
+			return fmt.Errorf("转换指令失败：%w", err)
+		}
+
+		for j, msg := range msgs {
+			entry.Frames = append(entry.Frames, communication.FormatCandumpFrame(msg))
+			frame := communication.BatchFrame{Message: msg}
+			if i > 0 && j == 0 {
+				frame.Delay = interFrameDelay
+			}
+			frames = append(frames, frame)
+		}
+	}
+
+	h.mutex.Unlock()
+
+	// 创建带有超时的 context，设置 3 秒超时
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// 发送到 can-bridge 服务
+	if len(frames) == 1 {
+		err = h.communicator.SendMessage(ctx, frames[0].Message)
+	} else {
+		err = h.communicator.SendBatch(ctx, frames)
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err != nil {
+		h.recordErrorLocked(err)
+		if len(frames) == 1 {
+			log.Printf("❌ %s (%s) 发送指令失败: %v (ID: 0x%X, Data: %X)", h.id, h.handType.String(), err, frames[0].Message.ID, frames[0].Message.Data)
+		} else {
+			log.Printf("❌ %s (%s) 批量发送 %d 帧失败: %v", h.id, h.handType.String(), len(frames), err)
+		}
+		// 发送失败说明链路可能有问题，降级并在后台重新握手
+		if h.status.State == device.StateConnected {
+			h.setStateLocked(device.StateDegraded, err.Error())
+			h.startReconnectLocked()
+		}
+		return fmt.Errorf("发送指令失败：%w", err)
+	}
+
+	h.status.LastUpdate = time.Now()
+	h.commitPose(cmds)
+
+	return nil
+}
+
+// recordErrorLocked 增加错误计数并发布 EventError，调用方需持有 h.mutex
+func (h *hand) recordErrorLocked(err error) {
+	h.status.ErrorCount++
+	h.status.LastError = err.Error()
+	device.PublishEvent(device.Event{Type: device.EventError, DeviceID: h.id, Error: err.Error()})
+}
+
+// enforceLimits 对姿态指令应用关节限制，返回限制后的指令；reject 模式下有违规时整组指令都不发送
+func (h *hand) enforceLimits(cmds []device.Command) ([]device.Command, error) {
+	enforced := make([]device.Command, len(cmds))
+	for i, cmd := range cmds {
+		switch c := cmd.(type) {
+		case *device.FingerPoseCommand:
+			pose, err := h.limits.Enforce(h.id, device.JointGroupFinger, c.Payload())
+			if err != nil {
+				return nil, err
+			}
+			enforced[i] = device.NewFingerPoseCommand(pose)
+		case *device.PalmPoseCommand:
+			pose, err := h.limits.Enforce(h.id, device.JointGroupPalm, c.Payload())
+			if err != nil {
+				return nil, err
+			}
+			enforced[i] = device.NewPalmPoseCommand(pose)
+		case *device.FullPoseCommand:
+			fingerPose, err := h.limits.Enforce(h.id, device.JointGroupFinger, c.FingerPose())
+			if err != nil {
+				return nil, err
+			}
+			palmPose, err := h.limits.Enforce(h.id, device.JointGroupPalm, c.PalmPose())
+			if err != nil {
+				return nil, err
+			}
+			enforced[i] = device.NewFullPoseCommand(fingerPose, palmPose, c.Speed())
+		default:
+			enforced[i] = cmd
+		}
+	}
+	return enforced, nil
+}
+
+// commitPose 记录已发送的姿态：作为下一条指令单步变化量的基准，并更新状态中的当前姿态
+func (h *hand) commitPose(cmds []device.Command) {
+	for _, cmd := range cmds {
+		fingerPose, palmPose := posePayloads(cmd)
+		sent := device.RawPose(fingerPose, palmPose)
+		if fingerPose != nil {
+			h.limits.Commit(device.JointGroupFinger, fingerPose)
+			h.status.Pose.Finger = sent.Finger
+		}
+		if palmPose != nil {
+			h.limits.Commit(device.JointGroupPalm, palmPose)
+			h.status.Pose.Palm = sent.Palm
+		}
+	}
+}
+
+// posePayloads 返回姿态指令中的手指和手掌姿态，不涉及的关节组为空
+func posePayloads(cmd device.Command) (fingerPose, palmPose []byte) {
+	switch c := cmd.(type) {
+	case *device.FingerPoseCommand:
+		fingerPose = c.Payload()
+	case *device.PalmPoseCommand:
+		palmPose = c.Payload()
+	case *device.FullPoseCommand:
+		fingerPose, palmPose = c.FingerPose(), c.PalmPose()
+	}
+	return fingerPose, palmPose
+}
+
+// startFeedbackListener 订阅本设备接口上发往左右手 CAN ID 的帧，解码设备反馈
+func (h *hand) startFeedbackListener() {
+	sub, err := h.communicator.Subscribe(communication.FrameFilter{
+		Interface: h.canInterface,
+		IDs:       []uint32{h.canIDFor(define.HAND_TYPE_LEFT), h.canIDFor(define.HAND_TYPE_RIGHT)},
+	})
+	if err != nil {
+		log.Printf("⚠️ 设备 %s 订阅反馈帧失败: %v", h.id, err)
+		return
+	}
+
+	h.feedbackSub = sub
+	go func() {
+		for msg := range sub.Frames() {
+			h.handleFeedback(msg)
+		}
+	}()
+}
+
+// handleFeedback 由型号解码一帧设备反馈，并更新传感器和连接状态
+func (h *hand) handleFeedback(msg communication.RawMessage) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// 手型可能在运行时切换，只处理当前手型对应的 CAN ID
+	if msg.ID != h.canIDFor(h.handType) || len(msg.Data) == 0 {
+		return
+	}
+
+	group, pose, ok := h.protocol.decodeFeedback(msg.Data)
+	if !ok {
+		return
+	}
+
+	var sensor component.FeedbackSensor
+	for _, comp := range h.components[device.SensorComponent] {
+		if s, ok := comp.(component.FeedbackSensor); ok {
+			sensor = s
+			break
+		}
+	}
+	if sensor != nil {
+		switch group {
+		case device.JointGroupFinger:
+			sensor.ApplyFingerFeedback(pose)
+		case device.JointGroupPalm:
+			sensor.ApplyPalmFeedback(pose)
+		}
+	}
+
+	h.status.LastFeedback = time.Now()
+
+	// 降级状态下收到反馈说明设备已恢复应答
+	if h.status.State == device.StateDegraded {
+		h.setStateLocked(device.StateConnected, "收到设备反馈")
+		h.stopReconnectLocked()
+		h.status.ReconnectAttempts = 0
+	}
+}
+
+// startHealthListener 订阅健康事件，本设备接口启用或停用时更新设备状态
+func (h *hand) startHealthListener() {
+	if h.health == nil {
+		return
+	}
+
+	h.healthSub = communication.SubscribeHealthEvents()
+	go func() {
+		for event := range h.healthSub.Events() {
+			if event.Type != communication.HealthEventInterface ||
+				event.Communicator != h.health.Name() || event.Interface != h.canInterface {
+				continue
+			}
+			h.applyInterfaceHealth(event.Up)
+		}
+	}()
+}
+
+func (h *hand) initializeComponents(_ map[string]any) error {
+	// 初始化传感器组件
+	defaultSensor := component.NewSensorData(h.canInterface)
+	defaultSensor.MockData()
+	sensors := []device.Component{defaultSensor}
+	h.components[device.SensorComponent] = sensors
+	return nil
+}
+
+func (h *hand) GetID() string {
+	return h.id
+}
+
+func (h *hand) GetModel() string {
+	return h.model
+}
+
+func (h *hand) ReadSensorData() (device.SensorData, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	sensors := h.components[device.SensorComponent]
+	for _, comp := range sensors {
+		if sensor, ok := comp.(component.Sensor); ok {
+			return sensor.ReadData()
+		}
+	}
+	return nil, fmt.Errorf("传感器不存在")
+}
+
+func (h *hand) GetComponents(componentType device.ComponentType) []device.Component {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if components, exists := h.components[componentType]; exists {
+		result := make([]device.Component, len(components))
+		copy(result, components)
+		return result
+	}
+	return []device.Component{}
+}
+
+func (h *hand) GetStatus() (device.DeviceStatus, error) {
+	h.mutex.RLock()
+	status := h.status
+	h.mutex.RUnlock()
+
+	status.Limits = h.limits.Stats()
+	status.Queue = h.queue.Stats()
+	status.Watchdog = h.watchdog.Status()
+
+	// 配置了多个 can-bridge 时附带故障转移状态
+	if reporter, ok := communication.As[communication.FailoverReporter](h.communicator); ok {
+		failover := reporter.FailoverStatus()
+		status.ActiveBridge = failover.ActiveURL
+		status.BridgeSwitchovers = failover.Switchovers
+		if n := len(failover.Recent); n > 0 {
+			last := failover.Recent[n-1]
+			status.LastSwitchover = fmt.Sprintf("%s -> %s: %s", last.From, last.To, last.Reason)
+			status.LastSwitchoverAt = last.Timestamp
+		}
+	}
+	return status, nil
+}
+
+// --- 预设姿势相关方法 ---
+
+// GetSupportedPresets 获取支持的预设姿势列表
+func (h *hand) GetSupportedPresets() []string { return h.presetManager.GetSupportedPresets() }
+
+// ExecutePreset 执行预设姿势
+func (h *hand) ExecutePreset(presetName string) error {
+	return h.executePreset(directOrigin, presetName)
+}
+
+func (h *hand) executePreset(origin device.CommandOrigin, presetName string) error {
+	preset, exists := h.presetManager.GetPreset(presetName)
+	if !exists {
+		return fmt.Errorf("预设姿势 '%s' 不存在", presetName)
+	}
+
+	log.Printf("🎯 设备 %s (%s) 执行预设姿势: %s", h.id, h.GetHandType().String(), presetName)
+
+	// 手指姿态和手掌姿态（如果有）作为一个动作批量发送
+	if err := h.setFullPose(origin, "preset:"+presetName, preset.FingerPose, preset.PalmPose); err != nil {
+		return fmt.Errorf("执行预设姿势 '%s' 失败: %w", presetName, err)
+	}
+
+	log.Printf("✅ 设备 %s 预设姿势 '%s' 执行完成", h.id, presetName)
+	return nil
+}
+
+// GetPresetDescription 获取预设姿势描述
+func (h *hand) GetPresetDescription(presetName string) string {
+	return h.presetManager.GetPresetDescription(presetName)
+}
+
+// GetPresetDetails 获取预设姿势详细信息
+func (h *hand) GetPresetDetails(presetName string) (device.PresetPose, bool) {
+	return h.presetManager.GetPreset(presetName)
+}
+
+func (h *hand) GetCanStatus() (map[string]bool, error) {
+	return h.communicator.GetAllInterfaceStatuses()
+}
+
+// --- 急停 ---
+
+// EmergencyStop 停止动画并锁定指令队列，再通过 emergency 通道驱动到安全姿态 (实现 device.EmergencyStopper)
+// 锁定期间其它通道的指令都被拒绝，因此安全姿态不经过滤波和单步变化量限制，保证一次到位
+func (h *hand) EmergencyStop(origin device.CommandOrigin) error {
+	if err := h.animationEngine.Stop(); err != nil {
+		log.Printf("⚠️ 设备 %s 停止动画失败: %v", h.id, err)
+	}
+
+	h.queue.Latch()
+	h.mutex.Lock()
+	if !h.status.EmergencyStop {
+		h.status.EmergencyStop = true
+		h.status.EmergencyStopAt = time.Now()
+	}
+	h.mutex.Unlock()
+	log.Printf("🛑 设备 %s 已急停锁定", h.id)
+
+	event := device.Event{Type: device.EventEmergencyStop, DeviceID: h.id, Client: origin.Client}
+	err := h.sendSafePose(origin, "estop")
+	if err != nil {
+		err = fmt.Errorf("驱动到安全姿态失败：%w", err)
+		event.Error = err.Error()
+	} else {
+		log.Printf("✅ 设备 %s 已到达安全姿态", h.id)
+	}
+	device.PublishEvent(event)
+	return err
+}
+
+// ReleaseEmergencyStop 解除急停锁定，设备停留在当前姿态 (实现 device.EmergencyStopper)
+func (h *hand) ReleaseEmergencyStop() {
+	h.queue.Unlatch()
+	h.mutex.Lock()
+	h.status.EmergencyStop = false
+	h.status.EmergencyStopAt = time.Time{}
+	h.mutex.Unlock()
+	log.Printf("✅ 设备 %s 急停已解除", h.id)
+	device.PublishEvent(device.Event{Type: device.EventEmergencyStopReleased, DeviceID: h.id})
+}
+
+// sendSafePose 通过 emergency 通道驱动到安全姿态。安全姿态在创建设备时已按关节范围校验，
+// 不经过滤波管道和单步变化量限制：否则 smoothing 或 max_step 会让手停在半路，而锁定期间没有其它指令能完成这个动作
+func (h *hand) sendSafePose(origin device.CommandOrigin, action string) error {
+	origin.Priority = device.PriorityEmergency
+	fingerCmd := device.NewFingerPoseCommand(h.safePose.Finger)
+	palmCmd := device.NewPalmPoseCommand(h.safePose.Palm)
+
+	err := h.executeCommands(device.CommandRequest{
+		Origin:   origin,
+		Commands: h.protocol.poseCommands(fingerCmd, palmCmd),
+		Action:   action,
+		Input:    device.HistoryPose{Finger: slices.Clone(h.safePose.Finger), Palm: slices.Clone(h.safePose.Palm)},
+		SafePose: true,
+	})
+	if err != nil {
+		return err
+	}
+	h.logFingerPose(fingerCmd.Payload())
+	h.logPalmPose(palmCmd.Payload())
+	return nil
+}
+
+// --- 控制会话 watchdog ---
+
+// OpenSession 开始控制会话，之后需要在超时时间内持续发送心跳 (实现 device.WatchdogDevice)
+func (h *hand) OpenSession(client string) (device.WatchdogSession, error) {
+	return h.watchdog.Open(client)
+}
+
+// Heartbeat 刷新控制会话 (实现 device.WatchdogDevice)
+func (h *hand) Heartbeat(sessionID string) (device.WatchdogSession, error) {
+	return h.watchdog.Heartbeat(sessionID)
+}
+
+// CloseSession 正常结束控制会话 (实现 device.WatchdogDevice)
+func (h *hand) CloseSession(sessionID string) error {
+	return h.watchdog.Close(sessionID)
+}
+
+// watchdogTrip 心跳超时：停止动画，通过 emergency 通道驱动到安全姿态，取消等待中的指令
+func (h *hand) watchdogTrip() error {
+	if err := h.animationEngine.Stop(); err != nil {
+		log.Printf("⚠️ 设备 %s 停止动画失败: %v", h.id, err)
+	}
+
+	origin := device.CommandOrigin{Source: device.SourceWatchdog, Detail: "heartbeat timeout"}
+	err := h.sendSafePose(origin, "watchdog")
+
+	event := device.Event{Type: device.EventWatchdogTrip, DeviceID: h.id}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	device.PublishEvent(event)
+	return err
+}
+
+// --- 指令来源和历史 ---
+
+// originExecutor 以固定来源和优先级提交指令的 Commander
+type originExecutor struct {
+	*hand
+	origin device.CommandOrigin
+}
+
+func (e originExecutor) SetFingerPose(pose []byte) error {
+	return e.setFingerPose(e.origin, pose)
+}
+
+func (e originExecutor) SetPalmPose(pose []byte) error {
+	return e.setPalmPose(e.origin, pose)
+}
+
+func (e originExecutor) ResetPose() error {
+	return e.resetPose(e.origin)
+}
+
+func (e originExecutor) ExecuteCommand(cmd device.Command) error {
+	return e.executeCommand(e.origin, cmd)
+}
+
+func (e originExecutor) ExecutePreset(presetName string) error {
+	return e.executePreset(e.origin, presetName)
+}
+
+// WithOrigin 返回以指定来源和优先级提交指令的 Commander (实现 device.OriginDevice)
+func (h *hand) WithOrigin(origin device.CommandOrigin) device.Commander {
+	return originExecutor{hand: h, origin: origin}
+}
+
+// GetHistory 按从新到旧的顺序返回满足条件的指令历史 (实现 device.HistoryDevice)
+func (h *hand) GetHistory(filter device.HistoryFilter) []device.HistoryEntry {
+	return h.history.Query(filter)
+}
+
+// --- 动画辅助方法 ---
+
+// waitAnimationStep 等待一个动画步长，动画被停止时返回 false
+func waitAnimationStep(stop <-chan struct{}, delay time.Duration) bool {
+	select {
+	case <-stop:
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
+
+// animationTarget 动画日志中使用的设备名称
+func animationTarget(executor device.PoseExecutor) string {
+	if idProvider, ok := executor.(interface{ GetID() string }); ok {
+		return idProvider.GetID()
+	}
+	return "unknown"
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"hands/communication"
+	"hands/device"
+)
+
+// handHandshakeTimeout 握手时等待设备应答的时间
+const handHandshakeTimeout = 1 * time.Second
+
+// handReconnectPolicy 自动重连的退避间隔
+var handReconnectPolicy = communication.RetryPolicy{
+	BaseDelay: 1 * time.Second,
+	MaxDelay:  30 * time.Second,
+}
+
+// setStateLocked 转换连接状态并同步 IsConnected/IsActive，不允许的转换返回 false，调用方需持有 h.mutex
+func (h *hand) setStateLocked(next device.ConnectionState, reason string) bool {
+	current := h.status.State
+	if current == next {
+		return true
+	}
+	if !current.CanTransitionTo(next) {
+		log.Printf("⚠️ 设备 %s 不能从 %s 转换到 %s", h.id, current, next)
+		return false
+	}
+
+	h.status.State = next
+	h.status.IsConnected = next.AcceptsCommands()
+	h.status.IsActive = next != device.StateDisconnected
+	h.status.LastUpdate = time.Now()
+	if reason != "" && (next == device.StateDegraded || next == device.StateFaulted) {
+		h.status.LastError = reason
+	}
+
+	if reason != "" {
+		log.Printf("🔄 设备 %s 状态 %s -> %s (%s)", h.id, current, next, reason)
+	} else {
+		log.Printf("🔄 设备 %s 状态 %s -> %s", h.id, current, next)
+	}
+	device.PublishEvent(device.Event{
+		Type:      device.EventStateChanged,
+		DeviceID:  h.id,
+		Timestamp: h.status.LastUpdate,
+		State:     &device.StateChange{From: current, To: next, Reason: reason},
+	})
+	return true
+}
+
+// tryConnect 检查接口并与设备握手：接口或服务不可用进入 faulted，设备未应答进入 degraded，二者都会启动自动重连
+// 降级状态下仍允许发送指令，因此只重试握手，不进入 connecting
+func (h *hand) tryConnect() error {
+	h.connectMutex.Lock()
+	defer h.connectMutex.Unlock()
+
+	h.mutex.Lock()
+	if h.status.State != device.StateDegraded && !h.setStateLocked(device.StateConnecting, "") {
+		state := h.status.State
+		h.mutex.Unlock()
+		return fmt.Errorf("设备 %s 当前状态为 %s，无法连接", h.id, state)
+	}
+	canInterface := h.canInterface
+	canID := h.canIDFor(h.handType)
+	h.mutex.Unlock()
+
+	if err := h.checkInterface(canInterface); err != nil {
+		h.finishConnect(device.StateFaulted, err)
+		return err
+	}
+	if err := h.handshake(canInterface, canID); err != nil {
+		h.finishConnect(device.StateDegraded, err)
+		return err
+	}
+	h.finishConnect(device.StateConnected, nil)
+	return nil
+}
+
+// finishConnect 记录一次连接尝试的结果；期间设备被主动断开时保持断开
+func (h *hand) finishConnect(next device.ConnectionState, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.status.State == device.StateDisconnected {
+		return
+	}
+
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	h.setStateLocked(next, reason)
+
+	if next == device.StateConnected {
+		h.stopReconnectLocked()
+		h.status.ReconnectAttempts = 0
+		log.Printf("🔗 设备 %s 已连接", h.id)
+	} else {
+		h.startReconnectLocked()
+	}
+}
+
+// checkInterface 确认通信服务可达且设备接口处于启用状态，服务没有上报该接口时交给握手判断
+func (h *hand) checkInterface(canInterface string) error {
+	statuses, err := h.communicator.GetAllInterfaceStatuses()
+	if err != nil {
+		return fmt.Errorf("通信服务不可用：%w", err)
+	}
+	if up, ok := statuses[canInterface]; ok && !up {
+		return fmt.Errorf("接口 %s 不可用", canInterface)
+	}
+	return nil
+}
+
+// handshake 发送型号的查询帧，等待设备以相同前缀应答
+// 通信客户端无法接收帧时（例如没有接收流的旧版 can-bridge）跳过应答检查，只按接口状态判断连接
+func (h *hand) handshake(canInterface string, canID uint32) error {
+	if !communication.CanReceive(h.communicator) {
+		return nil
+	}
+
+	sub, err := h.communicator.Subscribe(communication.FrameFilter{Interface: canInterface, IDs: []uint32{canID}})
+	if err != nil {
+		return fmt.Errorf("订阅设备应答失败：%w", err)
+	}
+	defer sub.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), handHandshakeTimeout)
+	defer cancel()
+
+	query := h.protocol.queryMessage()
+	query.Interface = canInterface
+	query.ID = canID
+	if err := h.communicator.SendMessage(ctx, query); err != nil {
+		return fmt.Errorf("发送握手帧失败：%w", err)
+	}
+
+	for {
+		select {
+		case msg, ok := <-sub.Frames():
+			if !ok {
+				return fmt.Errorf("握手时订阅被关闭")
+			}
+			if len(msg.Data) > 0 && msg.Data[0] == query.Data[0] {
+				return nil
+			}
+		case <-ctx.Done():
+			// 订阅后才发现 bridge 没有接收流
+			if !communication.CanReceive(h.communicator) {
+				log.Printf("ℹ️ 设备 %s 的通信客户端无法接收 CAN 帧，跳过握手应答检查", h.id)
+				return nil
+			}
+			return fmt.Errorf("设备在 %v 内没有应答", handHandshakeTimeout)
+		}
+	}
+}
+
+// startReconnectLocked 启动后台自动重连，已在运行时不重复启动，调用方需持有 h.mutex
+func (h *hand) startReconnectLocked() {
+	if h.reconnectStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	h.reconnectStop = stop
+	go h.reconnectLoop(stop)
+}
+
+// stopReconnectLocked 停止后台自动重连，调用方需持有 h.mutex
+func (h *hand) stopReconnectLocked() {
+	if h.reconnectStop != nil {
+		close(h.reconnectStop)
+		h.reconnectStop = nil
+	}
+}
+
+// reconnectLoop 按退避间隔重试连接，直到连接成功、设备被断开或重连被停止
+func (h *hand) reconnectLoop(stop chan struct{}) {
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(handReconnectPolicy.Backoff(attempt))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		h.mutex.Lock()
+		if h.status.State == device.StateDisconnected {
+			h.mutex.Unlock()
+			return
+		}
+		h.status.ReconnectAttempts = attempt
+		h.mutex.Unlock()
+
+		if err := h.tryConnect(); err == nil {
+			return
+		}
+	}
+}
+
+func (h *hand) Connect() error {
+	// 手动连接重新开始退避计时
+	h.mutex.Lock()
+	h.stopReconnectLocked()
+	h.status.ReconnectAttempts = 0
+	h.mutex.Unlock()
+
+	if err := h.tryConnect(); err != nil {
+		return fmt.Errorf("设备 %s 连接失败：%w", h.id, err)
+	}
+	return nil
+}
+
+func (h *hand) Disconnect() error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.stopReconnectLocked()
+	h.status.ReconnectAttempts = 0
+	h.setStateLocked(device.StateDisconnected, "")
+	log.Printf("🔌 设备 %s 已断开", h.id)
+	return nil
+}
+
+// Close 断开设备并释放后台资源 (实现 device.ClosableDevice)：停止动画、watchdog 和自动重连，
+// 取消反馈帧和健康事件订阅，关闭指令队列。通信客户端由多个设备共享，不在这里关闭
+func (h *hand) Close() error {
+	if err := h.animationEngine.Stop(); err != nil {
+		log.Printf("⚠️ 设备 %s 停止动画失败: %v", h.id, err)
+	}
+	h.watchdog.Stop()
+	if err := h.Disconnect(); err != nil {
+		return err
+	}
+
+	// 最后一个订阅关闭时 can-bridge 客户端会关闭接收流
+	if h.feedbackSub != nil {
+		h.feedbackSub.Close()
+	}
+	if h.healthSub != nil {
+		h.healthSub.Close()
+	}
+	h.queue.Close()
+	log.Printf("🗑️ 设备 %s 已关闭", h.id)
+	return nil
+}
+
+// applyInterfaceHealth 根据接口可用性更新连接状态：接口停用进入 faulted，恢复后立即重连；主动断开的设备保持断开
+func (h *hand) applyInterfaceHealth(up bool) {
+	h.mutex.Lock()
+	state := h.status.State
+	switch {
+	case state == device.StateDisconnected:
+	case !up && state != device.StateFaulted:
+		h.setStateLocked(device.StateFaulted, fmt.Sprintf("接口 %s 不可用", h.canInterface))
+		h.startReconnectLocked()
+	case up && state == device.StateFaulted:
+		log.Printf("🔗 设备 %s 的接口 %s 已恢复，立即重连", h.id, h.canInterface)
+		h.stopReconnectLocked()
+		go h.tryConnect()
+	}
+	h.mutex.Unlock()
+}
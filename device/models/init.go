@@ -3,6 +3,16 @@ package models
 import "hands/device"
 
 func RegisterDeviceTypes() {
-	// 注册 L10 设备类型
-	device.RegisterDeviceType("L10", NewL10Hand)
+	// 注册 L10 设备类型，型号的能力描述按经典 CAN 列出指令，开启 can_fd 的设备在自己的能力描述中增加组合帧指令
+	registerHandModel(NewL10Hand, L10JointCalibration(), l10Spec(&L10Hand{}))
+
+	// 注册 L20 设备类型
+	registerHandModel(NewL20Hand, L20JointCalibration(), l20Spec())
+}
+
+// registerHandModel 注册型号的构造函数、关节标定表和能力描述
+func registerHandModel(constructor func(config map[string]any) (device.Device, error), calibration device.JointCalibrationTable, spec handSpec) {
+	device.RegisterDeviceType(spec.model, constructor)
+	device.RegisterJointCalibration(spec.model, calibration)
+	device.RegisterModelCapabilities(spec.model, spec.capabilities(calibration))
 }
@@ -1,378 +1,159 @@
 package models
 
 import (
-	"context"
 	"fmt"
 	"log"
-	"math/rand/v2"
-	"sync"
-	"time"
 
 	"hands/communication"
-	"hands/component"
-	"hands/define"
 	"hands/device"
 )
 
+// L10 CAN 帧的指令前缀，发送指令和设备反馈使用相同的前缀
+const (
+	l10FingerPrefix   byte = 0x01 // 手指姿态
+	l10PalmPrefix     byte = 0x04 // 手掌姿态
+	l10FullPosePrefix byte = 0x10 // 手指姿态 + 手掌姿态 + 手指速度的组合帧，仅 CAN FD
+)
+
+// l10DefaultFingerSpeed 未配置手指速度时使用的速度（最快）
+const l10DefaultFingerSpeed byte = 255
+
 // L10Hand L10 型号手部设备实现
 type L10Hand struct {
-	id              string
-	model           string
-	handType        define.HandType
-	communicator    communication.Communicator
-	components      map[device.ComponentType][]device.Component
-	status          device.DeviceStatus
-	mutex           sync.RWMutex
-	canInterface    string                  // CAN 接口名称，如 "can0"
-	animationEngine *device.AnimationEngine // 动画引擎
-	presetManager   *device.PresetManager   // 预设姿势管理器
+	*hand
+	canFD       bool   // 是否使用 CAN FD 帧，新固件通过 FD 组合帧一次下发完整姿态
+	canFDBRS    bool   // FD 帧是否开启位速率切换
+	fingerSpeed []byte // 组合帧中的手指速度
 }
 
-// 在 base 基础上进行 ±delta 的扰动，范围限制在 [0, 255]
-func perturb(base byte, delta int) byte {
-	offset := rand.IntN(2*delta+1) - delta
-	v := min(max(int(base)+offset, 0), 255)
-	return byte(v)
+// l10Joints L10 各关节组的关节数
+var l10Joints = map[device.JointGroup]int{
+	device.JointGroupFinger: 6,
+	device.JointGroupPalm:   4,
 }
 
 // NewL10Hand 创建 L10 手部设备实例
-// 参数 config 是设备配置，包含以下字段：
-//   - id: 设备 ID
-//   - can_service_url: CAN 服务 URL
-//   - can_interface: CAN 接口名称，如 "can0"
-//   - hand_type: 手型，可选值为 "left" 或 "right"，默认值为 "right"
+// 参数 config 是设备配置，除 newHand 中的共用字段外还包含以下字段：
+//   - transport: 传输方式，可选值为 "can-bridge"、"socketcan"、"sim" 或 "slcan"，默认值为 "can-bridge"
+//   - can_service_url: CAN 服务 URL，transport 为 "can-bridge" 时必填；多个 URL 用逗号分隔时按顺序故障转移
+//   - can_service_urls: 按优先级排列的 can-bridge URL 列表，优先于 can_service_url
+//   - serial_port: slcan 适配器的串口设备，transport 为 "slcan" 时必填；serial_baud、can_bitrate 见 communication.NewCommunicatorFromConfig
+//   - can_fd: 是否使用 CAN FD，默认值为 false；开启后完整姿态通过一帧组合帧发送，通信客户端必须支持 FD
+//   - can_fd_brs: FD 帧是否开启位速率切换，默认值为 true
+//   - finger_speed: 组合帧中的手指速度 (0-255)，默认值为 255
+//   - joint_limits: 6 个手指、4 个手掌关节的范围，见 device.LimitConfig
 func NewL10Hand(config map[string]any) (device.Device, error) {
-	id, ok := config["id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("缺少设备 ID 配置")
-	}
-
-	serviceURL, ok := config["can_service_url"].(string)
+	canFD, _ := config["can_fd"].(bool)
+	canFDBRS, ok := config["can_fd_brs"].(bool)
 	if !ok {
-		return nil, fmt.Errorf("缺少 can 服务 URL 配置")
-	}
-
-	canInterface, ok := config["can_interface"].(string)
-	if !ok {
-		canInterface = "can0" // 默认接口
-	}
-
-	handTypeStr, ok := config["hand_type"].(string)
-	handType := define.HAND_TYPE_RIGHT // 默认右手
-	if ok && handTypeStr == "left" {
-		handType = define.HAND_TYPE_LEFT
+		canFDBRS = true
 	}
 
-	// 创建通信客户端
-	comm := communication.NewCanBridgeClient(serviceURL)
-
-	hand := &L10Hand{
-		id:           id,
-		model:        "L10",
-		handType:     handType,
-		communicator: comm,
-		components:   make(map[device.ComponentType][]device.Component),
-		canInterface: canInterface,
-		status: device.DeviceStatus{
-			// TODO: 这里需要修改，根据实际连接情况设置，因为当前还没有实现连接和断开路由，先设置为 true
-			IsConnected: true,
-			IsActive:    true,
-			LastUpdate:  time.Now(),
-		},
-	}
-
-	// 初始化动画引擎，将 hand 自身作为 PoseExecutor
-	hand.animationEngine = device.NewAnimationEngine(hand)
-
-	// 注册默认动画
-	hand.animationEngine.Register(NewL10WaveAnimation())
-	hand.animationEngine.Register(NewL10SwayAnimation())
-
-	// 初始化预设姿势管理器
-	hand.presetManager = device.NewPresetManager()
-
-	// 注册 L10 的预设姿势
-	for _, preset := range GetL10Presets() {
-		hand.presetManager.RegisterPreset(preset)
+	fingerSpeed := l10DefaultFingerSpeed
+	switch v := config["finger_speed"].(type) {
+	case float64: // JSON 数字
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("无效的手指速度：%v，范围为 0-255", v)
+		}
+		fingerSpeed = byte(v)
+	case int:
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("无效的手指速度：%d，范围为 0-255", v)
+		}
+		fingerSpeed = byte(v)
 	}
 
-	// 初始化组件
-	if err := hand.initializeComponents(config); err != nil {
-		return nil, fmt.Errorf("初始化组件失败：%w", err)
+	l10 := &L10Hand{
+		canFD:       canFD,
+		canFDBRS:    canFDBRS,
+		fingerSpeed: []byte{fingerSpeed, fingerSpeed, fingerSpeed, fingerSpeed, fingerSpeed, fingerSpeed},
 	}
 
-	log.Printf("✅ 设备 L10 (%s, %s) 创建成功", id, handType.String())
-	return hand, nil
-}
-
-// GetHandType 获取设备手型
-func (h *L10Hand) GetHandType() define.HandType {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-	return h.handType
-}
-
-// SetHandType 设置设备手型
-func (h *L10Hand) SetHandType(handType define.HandType) error {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-	if handType != define.HAND_TYPE_LEFT && handType != define.HAND_TYPE_RIGHT {
-		return fmt.Errorf("无效的手型：%d", handType)
+	base, err := newHand(config, l10Spec(l10))
+	if err != nil {
+		return nil, err
 	}
-	h.handType = handType
-	log.Printf("🔧 设备 %s 手型已更新: %s", h.id, handType.String())
-	return nil
-}
+	l10.hand = base
+	base.start()
 
-// GetAnimationEngine 获取动画引擎
-func (h *L10Hand) GetAnimationEngine() *device.AnimationEngine {
-	return h.animationEngine
+	log.Printf("✅ 设备 L10 (%s, %s) 创建成功", base.id, base.handType.String())
+	return l10, nil
 }
 
-// SetFingerPose 设置手指姿态 (实现 PoseExecutor)
-func (h *L10Hand) SetFingerPose(pose []byte) error {
-	if len(pose) != 6 {
-		return fmt.Errorf("无效的手指姿态数据长度，需要 6 个字节")
+// l10Spec L10 的型号描述，protocol 为设备实例自身
+func l10Spec(protocol handProtocol) handSpec {
+	return handSpec{
+		model:       "L10",
+		joints:      l10Joints,
+		protocol:    protocol,
+		resetFinger: []byte{64, 64, 64, 64, 64, 64}, // 0x40 - 半开
+		resetPalm:   []byte{128, 128, 128, 128},     // 0x80 - 居中
+		presets:     GetL10Presets(),
+		animations:  []device.Animation{NewL10WaveAnimation(), NewL10SwayAnimation()},
 	}
-
-	// 添加随机扰动
-	perturbedPose := make([]byte, len(pose))
-	for i, v := range pose {
-		perturbedPose[i] = perturb(v, 5)
-	}
-
-	// 创建指令
-	cmd := device.NewFingerPoseCommand(perturbedPose)
-
-	// 执行指令
-	err := h.ExecuteCommand(cmd)
-	if err == nil {
-		log.Printf("✅ %s (%s) 手指动作已发送: [%X %X %X %X %X %X]",
-			h.id, h.GetHandType().String(), perturbedPose[0], perturbedPose[1], perturbedPose[2],
-			perturbedPose[3], perturbedPose[4], perturbedPose[5])
-	}
-	return err
 }
 
-// SetPalmPose 设置手掌姿态 (实现 PoseExecutor)
-func (h *L10Hand) SetPalmPose(pose []byte) error {
-	if len(pose) != 4 {
-		return fmt.Errorf("无效的手掌姿态数据长度，需要 4 个字节")
-	}
+// RequiresCANFD 开启 can_fd 的 L10 需要 CAN FD 传输
+func (h *L10Hand) RequiresCANFD() bool { return h.canFD }
 
-	// 添加随机扰动
-	perturbedPose := make([]byte, len(pose))
-	for i, v := range pose {
-		perturbedPose[i] = perturb(v, 8)
+// commandTypes 开启 CAN FD 时额外支持组合帧指令
+func (h *L10Hand) commandTypes() []string {
+	if h.canFD {
+		return []string{"SetFingerPose", "SetPalmPose", "SetFullPose"}
 	}
-
-	// 创建指令
-	cmd := device.NewPalmPoseCommand(perturbedPose)
-
-	// 执行指令
-	err := h.ExecuteCommand(cmd)
-	if err == nil {
-		log.Printf("✅ %s (%s) 掌部姿态已发送: [%X %X %X %X]",
-			h.id, h.GetHandType().String(), perturbedPose[0], perturbedPose[1], perturbedPose[2], perturbedPose[3])
-	}
-	return err
+	return []string{"SetFingerPose", "SetPalmPose"}
 }
 
-// ResetPose 重置到默认姿态 (实现 PoseExecutor)
-func (h *L10Hand) ResetPose() error {
-	log.Printf("🔄 正在重置设备 %s (%s) 到默认姿态...", h.id, h.GetHandType().String())
-	defaultFingerPose := []byte{64, 64, 64, 64, 64, 64} // 0x40 - 半开
-	defaultPalmPose := []byte{128, 128, 128, 128}       // 0x80 - 居中
-
-	if err := h.SetFingerPose(defaultFingerPose); err != nil {
-		log.Printf("❌ %s 重置手指姿势失败: %v", h.id, err)
-		return err
+// poseCommands 使用 CAN FD 时合并为一帧组合帧，否则手指和手掌各一帧
+func (h *L10Hand) poseCommands(finger *device.FingerPoseCommand, palm *device.PalmPoseCommand) []device.Command {
+	if palm == nil {
+		return []device.Command{finger}
 	}
-	time.Sleep(20 * time.Millisecond) // 短暂延时
-	if err := h.SetPalmPose(defaultPalmPose); err != nil {
-		log.Printf("❌ %s 重置掌部姿势失败: %v", h.id, err)
-		return err
+	if h.canFD {
+		return []device.Command{device.NewFullPoseCommand(finger.Payload(), palm.Payload(), h.fingerSpeed)}
 	}
-	log.Printf("✅ 设备 %s 已重置到默认姿态", h.id)
-	return nil
+	return []device.Command{finger, palm}
 }
 
-// commandToRawMessageUnsafe 将通用指令转换为 L10 特定的 CAN 消息（不加锁版本）
-// 注意：此方法不是线程安全的，只应在已获取适当锁的情况下调用
-func (h *L10Hand) commandToRawMessageUnsafe(cmd device.Command) (communication.RawMessage, error) {
+// encodeCommand 每条指令编码为一帧：指令前缀 + 姿态数据
+func (h *L10Hand) encodeCommand(cmd device.Command) ([]communication.RawMessage, error) {
 	var data []byte
-	canID := uint32(h.handType)
-
 	switch cmd.Type() {
 	case "SetFingerPose":
 		// 添加 0x01 前缀
-		data = append([]byte{0x01}, cmd.Payload()...)
-		if len(data) > 8 { // CAN 消息数据长度限制
-			return communication.RawMessage{}, fmt.Errorf("手指姿态数据过长")
-		}
+		data = append([]byte{l10FingerPrefix}, cmd.Payload()...)
 	case "SetPalmPose":
 		// 添加 0x04 前缀
-		data = append([]byte{0x04}, cmd.Payload()...)
-		if len(data) > 8 { // CAN 消息数据长度限制
-			return communication.RawMessage{}, fmt.Errorf("手掌姿态数据过长")
+		data = append([]byte{l10PalmPrefix}, cmd.Payload()...)
+	case "SetFullPose":
+		if !h.canFD {
+			return nil, fmt.Errorf("组合姿态指令需要 CAN FD")
 		}
-	default:
-		return communication.RawMessage{}, fmt.Errorf("L10 不支持的指令类型: %s", cmd.Type())
-	}
-
-	return communication.RawMessage{
-		Interface: h.canInterface,
-		ID:        canID,
-		Data:      data,
-	}, nil
-}
-
-// ExecuteCommand 执行一个通用指令
-func (h *L10Hand) ExecuteCommand(cmd device.Command) error {
-	h.mutex.Lock() // 使用写锁，因为会更新状态
-	defer h.mutex.Unlock()
-
-	if !h.status.IsConnected || !h.status.IsActive {
-		return fmt.Errorf("设备 %s 未连接或未激活", h.id)
-	}
-
-	// 转换指令为 CAN 消息（使用不加锁版本，因为已经在写锁保护下）
-	rawMsg, err := h.commandToRawMessageUnsafe(cmd)
-	if err != nil {
-		h.status.ErrorCount++
-		h.status.LastError = err.Error()
-		return fmt.Errorf("转换指令失败：%w", err)
-	}
-
-	// 创建带有超时的 context，设置 3 秒超时
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	// 发送到 can-bridge 服务
-	if err := h.communicator.SendMessage(ctx, rawMsg); err != nil {
-		h.status.ErrorCount++
-		h.status.LastError = err.Error()
-		log.Printf("❌ %s (%s) 发送指令失败: %v (ID: 0x%X, Data: %X)", h.id, h.handType.String(), err, rawMsg.ID, rawMsg.Data)
-		return fmt.Errorf("发送指令失败：%w", err)
-	}
-
-	h.status.LastUpdate = time.Now()
-
-	return nil
-}
-
-func (h *L10Hand) initializeComponents(_ map[string]any) error {
-	// 初始化传感器组件
-	defaultSensor := component.NewSensorData(h.canInterface)
-	defaultSensor.MockData()
-	sensors := []device.Component{defaultSensor}
-	h.components[device.SensorComponent] = sensors
-	return nil
-}
-
-func (h *L10Hand) GetID() string {
-	return h.id
-}
-
-func (h *L10Hand) GetModel() string {
-	return h.model
-}
-
-func (h *L10Hand) ReadSensorData() (device.SensorData, error) {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-
-	sensors := h.components[device.SensorComponent]
-	for _, comp := range sensors {
-		if sensor, ok := comp.(component.Sensor); ok {
-			return sensor.ReadData()
+		// 添加 0x10 前缀，并用 0 填充到合法的 FD 数据长度
+		data = append([]byte{l10FullPosePrefix}, cmd.Payload()...)
+		if padded := communication.FDPaddedLength(len(data)); padded > len(data) {
+			data = append(data, make([]byte, padded-len(data))...)
 		}
+	default:
+		return nil, fmt.Errorf("L10 不支持的指令类型: %s", cmd.Type())
 	}
-	return nil, fmt.Errorf("传感器不存在")
-}
-
-func (h *L10Hand) GetComponents(componentType device.ComponentType) []device.Component {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-
-	if components, exists := h.components[componentType]; exists {
-		result := make([]device.Component, len(components))
-		copy(result, components)
-		return result
-	}
-	return []device.Component{}
-}
-
-func (h *L10Hand) GetStatus() (device.DeviceStatus, error) {
-	h.mutex.RLock()
-	defer h.mutex.RUnlock()
-	return h.status, nil
-}
-
-func (h *L10Hand) Connect() error {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
 
-	// TODO: 假设连接总是成功，除非有显式错误
-	h.status.IsConnected = true
-	h.status.IsActive = true
-	h.status.LastUpdate = time.Now()
-	log.Printf("🔗 设备 %s 已连接", h.id)
-	return nil
+	return []communication.RawMessage{{Data: data, FD: h.canFD, BRS: h.canFD && h.canFDBRS}}, nil
 }
 
-func (h *L10Hand) Disconnect() error {
-	h.mutex.Lock()
-	defer h.mutex.Unlock()
-
-	h.status.IsConnected = false
-	h.status.IsActive = false
-	h.status.LastUpdate = time.Now()
-	log.Printf("🔌 设备 %s 已断开", h.id)
-	return nil
+// queryMessage 只含手指前缀的查询帧，设备回送手指位置
+func (h *L10Hand) queryMessage() communication.RawMessage {
+	return communication.RawMessage{Data: []byte{l10FingerPrefix}, FD: h.canFD, BRS: h.canFD && h.canFDBRS}
 }
 
-// --- 预设姿势相关方法 ---
-
-// GetSupportedPresets 获取支持的预设姿势列表
-func (h *L10Hand) GetSupportedPresets() []string { return h.presetManager.GetSupportedPresets() }
-
-// ExecutePreset 执行预设姿势
-func (h *L10Hand) ExecutePreset(presetName string) error {
-	preset, exists := h.presetManager.GetPreset(presetName)
-	if !exists {
-		return fmt.Errorf("预设姿势 '%s' 不存在", presetName)
-	}
-
-	log.Printf("🎯 设备 %s (%s) 执行预设姿势: %s", h.id, h.GetHandType().String(), presetName)
-
-	// 执行手指姿态
-	if err := h.SetFingerPose(preset.FingerPose); err != nil {
-		return fmt.Errorf("执行预设姿势 '%s' 的手指姿态失败: %w", presetName, err)
+// decodeFeedback 反馈帧与指令帧使用相同的前缀和数据布局
+func (h *L10Hand) decodeFeedback(data []byte) (device.JointGroup, []byte, bool) {
+	payload := data[1:]
+	switch data[0] {
+	case l10FingerPrefix:
+		return device.JointGroupFinger, payload, len(payload) == l10Joints[device.JointGroupFinger]
+	case l10PalmPrefix:
+		return device.JointGroupPalm, payload, len(payload) == l10Joints[device.JointGroupPalm]
 	}
-
-	// 如果有手掌姿态数据，也执行
-	if len(preset.PalmPose) > 0 {
-		time.Sleep(20 * time.Millisecond) // 短暂延时
-		if err := h.SetPalmPose(preset.PalmPose); err != nil {
-			return fmt.Errorf("执行预设姿势 '%s' 的手掌姿态失败: %w", presetName, err)
-		}
-	}
-
-	log.Printf("✅ 设备 %s 预设姿势 '%s' 执行完成", h.id, presetName)
-	return nil
-}
-
-// GetPresetDescription 获取预设姿势描述
-func (h *L10Hand) GetPresetDescription(presetName string) string {
-	return h.presetManager.GetPresetDescription(presetName)
-}
-
-// GetPresetDetails 获取预设姿势详细信息
-func (h *L10Hand) GetPresetDetails(presetName string) (device.PresetPose, bool) {
-	return h.presetManager.GetPreset(presetName)
-}
-
-func (h *L10Hand) GetCanStatus() (map[string]bool, error) {
-	return h.communicator.GetAllInterfaceStatuses()
+	return "", nil, false
 }
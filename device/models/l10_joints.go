@@ -0,0 +1,33 @@
+package models
+
+import "hands/device"
+
+// L10JointCalibration 获取 L10 的关节标定表
+// 手指原始值越小越弯曲：255 为完全伸直 (0%)，0 为完全弯曲 (100%)，与 fist (64) 和 open (192) 预设一致
+// 角度为标称值，用于把原始值换算成便于理解的物理量
+func L10JointCalibration() device.JointCalibrationTable {
+	finger := func(name string, closedDegrees float64) device.JointCalibration {
+		return device.JointCalibration{Name: name, OpenRaw: 255, ClosedRaw: 0, OpenDegrees: 0, ClosedDegrees: closedDegrees}
+	}
+	palm := func(name string) device.JointCalibration {
+		// 掌部关节以 128 为中位，0 和 255 分别为两侧极限
+		return device.JointCalibration{Name: name, OpenRaw: 255, ClosedRaw: 0, OpenDegrees: -20, ClosedDegrees: 20}
+	}
+
+	return device.JointCalibrationTable{
+		Finger: []device.JointCalibration{
+			finger("thumb", 60),
+			finger("thumb_rotate", 90),
+			finger("index", 90),
+			finger("middle", 90),
+			finger("ring", 90),
+			finger("pinky", 90),
+		},
+		Palm: []device.JointCalibration{
+			palm("joint7"),
+			palm("joint8"),
+			palm("joint9"),
+			palm("joint10"),
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"log"
+
+	"hands/device"
+)
+
+// L20 CAN 帧的指令前缀，每帧为前缀 + 5 个手指各一个字节 (拇指、食指、中指、无名指、小指)，
+// 发送指令和设备反馈使用相同的前缀
+const (
+	l20BasePrefix   byte = 0x01 // 手指根部弯曲
+	l20TipPrefix    byte = 0x03 // 手指末端弯曲
+	l20SpreadPrefix byte = 0x04 // 手指侧摆
+	l20RollPrefix   byte = 0x06 // 拇指横滚，只有 1 个字节
+)
+
+// l20FrameJoints 每帧最多携带的关节数
+const l20FrameJoints = 5
+
+// l20Joints L20 各关节组的关节数
+// 手指：5 个根部弯曲 + 5 个末端弯曲；手掌：5 个侧摆 + 拇指横滚
+var l20Joints = map[device.JointGroup]int{
+	device.JointGroupFinger: 10,
+	device.JointGroupPalm:   6,
+}
+
+// l20Layout 每个关节组按顺序拆分成的帧
+var l20Layout = map[device.JointGroup][]FrameLayout{
+	device.JointGroupFinger: {{Prefix: l20BasePrefix, Joints: 5}, {Prefix: l20TipPrefix, Joints: 5}},
+	device.JointGroupPalm:   {{Prefix: l20SpreadPrefix, Joints: 5}, {Prefix: l20RollPrefix, Joints: 1}},
+}
+
+// L20Hand L20 型号手部设备实现
+// L20 自由度更多，一个关节组的姿态要拆成多帧经典 CAN 帧发送，不支持 CAN FD
+type L20Hand struct {
+	*hand
+}
+
+// NewL20Hand 创建 L20 手部设备实例
+// 参数 config 是设备配置，字段与 L10 相同 (见 newHand 和 NewL10Hand)，但不支持 can_fd 和 finger_speed；
+// joint_limits 中为 10 个手指、6 个手掌关节的范围
+func NewL20Hand(config map[string]any) (device.Device, error) {
+	if canFD, _ := config["can_fd"].(bool); canFD {
+		return nil, fmt.Errorf("L20 不支持 CAN FD")
+	}
+
+	base, err := newHand(config, l20Spec())
+	if err != nil {
+		return nil, err
+	}
+	base.start()
+
+	log.Printf("✅ 设备 L20 (%s, %s) 创建成功", base.id, base.handType.String())
+	return &L20Hand{hand: base}, nil
+}
+
+// l20Spec L20 的型号描述，每次调用创建新的协议实例 (反馈拼帧的状态属于单个设备)
+func l20Spec() handSpec {
+	return handSpec{
+		model:       "L20",
+		joints:      l20Joints,
+		protocol:    newFramedProtocol("L20", l20Layout, l20BasePrefix),
+		resetFinger: []byte{128, 128, 128, 128, 128, 128, 128, 128, 128, 128}, // 0x80 - 半开
+		resetPalm:   []byte{128, 128, 128, 128, 128, 128},                     // 0x80 - 居中
+		presets:     GetL20Presets(),
+		animations:  []device.Animation{NewL20WaveAnimation(), NewL20SwayAnimation(), NewL20SpreadAnimation()},
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"hands/device"
+	"log"
+	"time"
+)
+
+// --- L20WaveAnimation ---
+
+// L20WaveAnimation 实现 L20 的波浪动画：手指依次弯曲再依次伸直，根部和末端一起动
+type L20WaveAnimation struct{}
+
+// NewL20WaveAnimation 创建 L20 波浪动画实例
+func NewL20WaveAnimation() *L20WaveAnimation { return &L20WaveAnimation{} }
+
+func (w *L20WaveAnimation) Name() string { return "wave" }
+
+func (w *L20WaveAnimation) Run(executor device.PoseExecutor, stop <-chan struct{}, speedMs int) error {
+	openPos, closedPos := byte(192), byte(64)
+	delay := time.Duration(speedMs) * time.Millisecond
+
+	pose := l20Fingers(openPos, openPos, openPos, openPos, openPos)
+	for _, target := range []byte{closedPos, openPos} {
+		for finger := range l20FrameJoints {
+			pose[finger] = target                // 根部
+			pose[l20FrameJoints+finger] = target // 末端
+
+			if err := executor.SetFingerPose(pose); err != nil {
+				log.Printf("❌ %s 动画 %s 发送失败: %v", animationTarget(executor), w.Name(), err)
+				return err
+			}
+			if !waitAnimationStep(stop, delay) {
+				return nil // 动画被停止
+			}
+		}
+	}
+
+	return nil // 完成一个周期
+}
+
+// --- L20SwayAnimation ---
+
+// L20SwayAnimation 实现 L20 的横向摆动动画：四指侧摆一起向左再向右
+type L20SwayAnimation struct{}
+
+// NewL20SwayAnimation 创建 L20 摆动动画实例
+func NewL20SwayAnimation() *L20SwayAnimation { return &L20SwayAnimation{} }
+
+func (s *L20SwayAnimation) Name() string { return "sway" }
+
+func (s *L20SwayAnimation) Run(executor device.PoseExecutor, stop <-chan struct{}, speedMs int) error {
+	leftPose := []byte{128, 80, 80, 80, 80, 128}      // 拇指不动，四指向左
+	rightPose := []byte{128, 176, 176, 176, 176, 128} // 四指向右
+	delay := time.Duration(speedMs) * time.Millisecond
+
+	for _, pose := range [][]byte{leftPose, rightPose} {
+		if err := executor.SetPalmPose(pose); err != nil {
+			log.Printf("❌ %s 动画 %s 发送失败: %v", animationTarget(executor), s.Name(), err)
+			return err
+		}
+		if !waitAnimationStep(stop, delay) {
+			return nil // 动画被停止
+		}
+	}
+
+	return nil // 完成一个周期
+}
+
+// --- L20SpreadAnimation ---
+
+// L20SpreadAnimation 实现 L20 的开合动画：五指张开并展开侧摆，再并拢
+type L20SpreadAnimation struct{}
+
+// NewL20SpreadAnimation 创建 L20 开合动画实例
+func NewL20SpreadAnimation() *L20SpreadAnimation { return &L20SpreadAnimation{} }
+
+func (s *L20SpreadAnimation) Name() string { return "spread" }
+
+func (s *L20SpreadAnimation) Run(executor device.PoseExecutor, stop <-chan struct{}, speedMs int) error {
+	spreadPose := []byte{224, 192, 128, 64, 32, 192}
+	delay := time.Duration(speedMs) * time.Millisecond
+
+	for _, pose := range [][]byte{spreadPose, l20NeutralPalm} {
+		if err := executor.SetPalmPose(pose); err != nil {
+			log.Printf("❌ %s 动画 %s 发送失败: %v", animationTarget(executor), s.Name(), err)
+			return err
+		}
+		if !waitAnimationStep(stop, delay) {
+			return nil // 动画被停止
+		}
+	}
+
+	return nil // 完成一个周期
+}
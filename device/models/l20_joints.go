@@ -0,0 +1,38 @@
+package models
+
+import "hands/device"
+
+// L20JointCalibration 获取 L20 的关节标定表
+// 与 L10 一致，原始值越小越弯曲：255 为完全伸直 (0%)，0 为完全弯曲 (100%)
+// 侧摆以 128 为中位，角度为标称值
+func L20JointCalibration() device.JointCalibrationTable {
+	bend := func(name string, closedDegrees float64) device.JointCalibration {
+		return device.JointCalibration{Name: name, OpenRaw: 255, ClosedRaw: 0, OpenDegrees: 0, ClosedDegrees: closedDegrees}
+	}
+	spread := func(name string, degrees float64) device.JointCalibration {
+		return device.JointCalibration{Name: name, OpenRaw: 255, ClosedRaw: 0, OpenDegrees: -degrees, ClosedDegrees: degrees}
+	}
+
+	return device.JointCalibrationTable{
+		Finger: []device.JointCalibration{
+			bend("thumb_base", 60),
+			bend("index_base", 90),
+			bend("middle_base", 90),
+			bend("ring_base", 90),
+			bend("pinky_base", 90),
+			bend("thumb_tip", 80),
+			bend("index_tip", 110),
+			bend("middle_tip", 110),
+			bend("ring_tip", 110),
+			bend("pinky_tip", 110),
+		},
+		Palm: []device.JointCalibration{
+			spread("thumb_spread", 30),
+			spread("index_spread", 15),
+			spread("middle_spread", 15),
+			spread("ring_spread", 15),
+			spread("pinky_spread", 15),
+			bend("thumb_roll", 90),
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package models
+
+import "hands/device"
+
+// l20Fingers 由 5 个手指的弯曲值生成 L20 手指姿态，根部和末端使用相同的值
+// 与 L10 一致，64 (0x40) 为弯曲、192 (0xC0) 为伸直
+func l20Fingers(thumb, index, middle, ring, pinky byte) []byte {
+	return []byte{thumb, index, middle, ring, pinky, thumb, index, middle, ring, pinky}
+}
+
+// l20NeutralPalm L20 手掌居中：5 个侧摆和拇指横滚都为 128
+var l20NeutralPalm = []byte{128, 128, 128, 128, 128, 128}
+
+// GetL20Presets 获取 L20 设备的所有预设姿势
+func GetL20Presets() []device.PresetPose {
+	return []device.PresetPose{
+		// 基础姿势
+		{
+			Name:        "fist",
+			Description: "握拳姿势",
+			FingerPose:  l20Fingers(64, 64, 64, 64, 64),
+			PalmPose:    []byte{128, 128, 128, 128, 128, 64}, // 拇指横滚压在食指上
+		},
+		{
+			Name:        "open",
+			Description: "完全张开姿势",
+			FingerPose:  l20Fingers(192, 192, 192, 192, 192),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "pinch",
+			Description: "捏取姿势",
+			FingerPose:  []byte{120, 120, 64, 64, 64, 100, 100, 64, 64, 64},
+			PalmPose:    []byte{128, 128, 128, 128, 128, 96},
+		},
+		{
+			Name:        "thumbsup",
+			Description: "竖起大拇指姿势",
+			FingerPose:  l20Fingers(192, 64, 64, 64, 64),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "point",
+			Description: "食指指点姿势",
+			FingerPose:  l20Fingers(64, 192, 64, 64, 64),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "ok",
+			Description: "OK 手势，拇指与食指成环",
+			FingerPose:  []byte{120, 120, 192, 192, 192, 100, 100, 192, 192, 192},
+			PalmPose:    []byte{128, 128, 128, 128, 128, 96},
+		},
+		{
+			Name:        "spread",
+			Description: "五指张开并最大侧摆",
+			FingerPose:  l20Fingers(192, 192, 192, 192, 192),
+			PalmPose:    []byte{224, 192, 128, 64, 32, 192},
+		},
+
+		// 数字手势
+		{
+			Name:        "1",
+			Description: "数字 1 手势",
+			FingerPose:  l20Fingers(64, 192, 64, 64, 64),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "2",
+			Description: "数字 2 手势",
+			FingerPose:  l20Fingers(64, 192, 192, 64, 64),
+			PalmPose:    []byte{128, 160, 96, 128, 128, 128}, // 食指和中指分开
+		},
+		{
+			Name:        "3",
+			Description: "数字 3 手势",
+			FingerPose:  l20Fingers(64, 192, 192, 192, 64),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "4",
+			Description: "数字 4 手势",
+			FingerPose:  l20Fingers(64, 192, 192, 192, 192),
+			PalmPose:    l20NeutralPalm,
+		},
+		{
+			Name:        "5",
+			Description: "数字 5 手势",
+			FingerPose:  l20Fingers(192, 192, 192, 192, 192),
+			PalmPose:    l20NeutralPalm,
+		},
+	}
+}
@@ -1,15 +1,21 @@
 package device
 
-import "hands/define"
+import (
+	"errors"
+	"hands/define"
+)
+
+// ErrInvalidPose 姿态数据的长度与设备型号的关节数不一致
+var ErrInvalidPose = errors.New("无效的姿态数据")
 
 // PoseExecutor 定义了执行基本姿态指令的能力
 type PoseExecutor interface {
 	// SetFingerPose 设置手指姿态
-	// pose: 6 字节数据，代表 6 个手指的位置
+	// pose: 每个手指关节一个字节，长度与能力描述中的手指关节数一致 (见 Device.GetCapabilities)
 	SetFingerPose(pose []byte) error
 
 	// SetPalmPose 设置手掌姿态
-	// pose: 4 字节数据，代表手掌的 4 个自由度
+	// pose: 每个手掌自由度一个字节，长度与能力描述中的手掌关节数一致
 	SetPalmPose(pose []byte) error
 
 	// ResetPose 重置到默认姿态
@@ -0,0 +1,364 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull 设备的指令队列已满，指令没有入队
+var ErrQueueFull = errors.New("指令队列已满")
+
+// ErrCommandPreempted 等待发送的指令被紧急指令取消
+var ErrCommandPreempted = errors.New("指令被紧急指令取消")
+
+// ErrQueueClosed 设备已删除，指令队列不再发送指令
+var ErrQueueClosed = errors.New("指令队列已关闭")
+
+// maxQueueDepth 每个设备最多等待发送的动作数
+const maxQueueDepth = 64
+
+// latencyAlpha 平均延迟的指数滑动平均系数
+const latencyAlpha = 0.2
+
+// CommandPriority 指令队列的优先级通道，数值越大越优先
+type CommandPriority int
+
+const (
+	PriorityAnimation CommandPriority = iota // 动画帧
+	PriorityManual                           // API、预设姿势和组指令
+	PriorityEmergency                        // 紧急指令，入队时取消所有低优先级的等待指令
+
+	priorityCount = iota
+)
+
+// String 返回通道名称
+func (p CommandPriority) String() string {
+	switch p {
+	case PriorityAnimation:
+		return "animation"
+	case PriorityManual:
+		return "manual"
+	case PriorityEmergency:
+		return "emergency"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// CommandOrigin 指令的来源和优先级，记录在指令历史中
+type CommandOrigin struct {
+	Source   string          // 来源，见 SourceAPI 等常量
+	Detail   string          // 来源细节，如 "POST /api/v1/devices/:deviceId/poses/fingers" 或动画名称
+	Client   string          // 发起请求的客户端地址，可以为空
+	Priority CommandPriority // 指令队列通道
+}
+
+// Commander 以固定来源执行指令
+type Commander interface {
+	PoseExecutor
+	ExecuteCommand(cmd Command) error
+	ExecutePreset(presetName string) error
+}
+
+// OriginDevice 由使用指令队列的设备实现，返回以指定来源和优先级提交指令的 Commander
+type OriginDevice interface {
+	WithOrigin(origin CommandOrigin) Commander
+}
+
+// CommanderFor 返回以 origin 执行指令的 Commander，设备不记录来源时返回设备本身
+func CommanderFor(dev Device, origin CommandOrigin) Commander {
+	if d, ok := dev.(OriginDevice); ok {
+		return d.WithOrigin(origin)
+	}
+	return dev
+}
+
+// CommandRequest 提交到指令队列的一个动作：一组作为整体发送的指令及其来源
+type CommandRequest struct {
+	Origin   CommandOrigin
+	Commands []Command
+	Action   string      // 动作，见 HistoryEntry.Action
+	Input    HistoryPose // 滤波前请求的姿态，用于指令历史
+	SafePose bool        // 急停或 watchdog 的安全姿态：创建设备时已按关节范围校验，发送时不再应用单步变化量限制
+}
+
+// QueueStats 指令队列的深度、计数和延迟
+type QueueStats struct {
+	Depth         int            `json:"depth"`         // 当前等待发送的动作数
+	Lanes         map[string]int `json:"lanes"`         // 各优先级通道中等待的动作数
+	Sent          int            `json:"sent"`          // 已发送的动作数，包括发送失败的
+	Failed        int            `json:"failed"`        // 发送失败的动作数
+	Coalesced     int            `json:"coalesced"`     // 被同一通道中更新的姿态取代的动作数
+	Preempted     int            `json:"preempted"`     // 被紧急指令取消的动作数
+	Rejected      int            `json:"rejected"`      // 队列已满或急停锁定时被拒绝的动作数
+	LastLatencyMs float64        `json:"lastLatencyMs"` // 最近一个动作从入队到发送完成的耗时
+	AvgLatencyMs  float64        `json:"avgLatencyMs"`  // 耗时的指数滑动平均
+	MaxLatencyMs  float64        `json:"maxLatencyMs"`  // 最大耗时
+}
+
+// queuedCommand 队列中的一个动作：一组作为整体发送的指令
+type queuedCommand struct {
+	req      CommandRequest
+	key      string // 合并键，为空表示不合并
+	enqueued time.Time
+	done     chan error
+	entry    HistoryEntry // 完成时写入指令历史
+}
+
+// CommandQueue 设备的异步指令队列，按优先级分为多个通道，由一个发送 goroutine 依次取出动作执行。
+// 同一通道中等待的姿态动作按指令类型合并，只发送最新的一个 (latest-wins)，被取代的动作视为成功。
+// 每个动作的结果都记录到指令历史中并发布为 EventCommand。发送 goroutine 在 Close 之后退出
+type CommandQueue struct {
+	name    string                                    // 设备 ID，用于错误信息
+	send    func(CommandRequest, *HistoryEntry) error // 实际发送一个动作并填写发送的姿态和 CAN 帧，只由发送 goroutine 调用
+	history *CommandHistory                           // 指令历史，为空时不记录
+	lanes   [priorityCount][]*queuedCommand
+	latched bool // 急停锁定，只接受 emergency 通道的动作
+	closed  bool // 已关闭，拒绝所有动作
+	stats   QueueStats
+	mutex   sync.Mutex
+	wake    chan struct{}
+}
+
+// NewCommandQueue 创建指令队列并启动发送 goroutine，history 为空时不记录指令历史
+func NewCommandQueue(name string, history *CommandHistory, send func(req CommandRequest, entry *HistoryEntry) error) *CommandQueue {
+	q := &CommandQueue{
+		name:    name,
+		send:    send,
+		history: history,
+		wake:    make(chan struct{}, 1),
+	}
+	go q.run()
+	return q
+}
+
+// coalesceKey 只含姿态指令的动作可以合并，合并键为指令类型序列；含其他指令时返回空
+func coalesceKey(cmds []Command) string {
+	types := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		switch cmd.(type) {
+		case *FingerPoseCommand, *PalmPoseCommand, *FullPoseCommand:
+			types[i] = cmd.Type()
+		default:
+			return ""
+		}
+	}
+	return strings.Join(types, "+")
+}
+
+// NewHistoryEntry 由动作生成指令历史记录，结果和发送的内容在完成时填写
+func NewHistoryEntry(req CommandRequest) HistoryEntry {
+	types := make([]string, len(req.Commands))
+	for i, cmd := range req.Commands {
+		types[i] = cmd.Type()
+	}
+	return HistoryEntry{
+		Time:     time.Now(),
+		Source:   req.Origin.Source,
+		Detail:   req.Origin.Detail,
+		Client:   req.Origin.Client,
+		Action:   req.Action,
+		Priority: req.Origin.Priority.String(),
+		Commands: types,
+		Input:    req.Input,
+	}
+}
+
+// Submit 将动作提交到 req.Origin.Priority 通道，返回的通道在动作发送完成、被取代或被取消时收到结果
+func (q *CommandQueue) Submit(req CommandRequest) <-chan error {
+	priority := req.Origin.Priority
+	job := &queuedCommand{
+		req:      req,
+		key:      coalesceKey(req.Commands),
+		enqueued: time.Now(),
+		done:     make(chan error, 1),
+		entry:    NewHistoryEntry(req),
+	}
+	if priority < 0 || priority >= priorityCount {
+		q.finish(job, ResultRejected, fmt.Errorf("无效的指令优先级：%d", priority))
+		return job.done
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		q.finish(job, ResultClosed, fmt.Errorf("%w：设备 %s 已删除", ErrQueueClosed, q.name))
+		return job.done
+	}
+	if q.latched && priority < PriorityEmergency {
+		q.stats.Rejected++
+		q.finish(job, ResultRejected, fmt.Errorf("%w：设备 %s 需要解除急停后才能发送指令", ErrEmergencyStop, q.name))
+		return job.done
+	}
+
+	// 紧急指令取消所有低优先级的等待指令
+	if priority == PriorityEmergency {
+		q.preemptLocked()
+	}
+
+	// 最新的姿态取代同一通道中等待的同类姿态，沿用原来的排队位置
+	lane := q.lanes[priority]
+	if job.key != "" {
+		for i, pending := range lane {
+			if pending.key == job.key {
+				q.finish(pending, ResultCoalesced, nil)
+				lane[i] = job
+				q.stats.Coalesced++
+				return job.done
+			}
+		}
+	}
+
+	if depth := q.depthLocked(); depth >= maxQueueDepth {
+		q.stats.Rejected++
+		q.finish(job, ResultRejected, fmt.Errorf("%w：设备 %s 有 %d 个动作等待发送", ErrQueueFull, q.name, depth))
+		return job.done
+	}
+	q.lanes[priority] = append(lane, job)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job.done
+}
+
+// preemptLocked 取消所有低于 emergency 的等待动作，调用方持有 q.mutex
+func (q *CommandQueue) preemptLocked() {
+	for p := range PriorityEmergency {
+		for _, pending := range q.lanes[p] {
+			q.finish(pending, ResultPreempted, ErrCommandPreempted)
+			q.stats.Preempted++
+		}
+		q.lanes[p] = nil
+	}
+}
+
+// Latch 急停锁定：取消所有等待的非紧急动作，之后只接受 emergency 通道的动作，直到 Unlatch
+func (q *CommandQueue) Latch() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.latched = true
+	q.preemptLocked()
+}
+
+// Unlatch 解除急停锁定
+func (q *CommandQueue) Unlatch() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.latched = false
+}
+
+// Close 关闭队列：取消所有等待的动作，拒绝之后提交的动作，发送 goroutine 在当前动作完成后退出
+func (q *CommandQueue) Close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	for p, lane := range q.lanes {
+		for _, job := range lane {
+			q.finish(job, ResultClosed, fmt.Errorf("%w：设备 %s 已删除", ErrQueueClosed, q.name))
+		}
+		q.lanes[p] = nil
+	}
+	close(q.wake)
+}
+
+// Execute 提交动作并等待结果
+func (q *CommandQueue) Execute(req CommandRequest) error {
+	return <-q.Submit(req)
+}
+
+// finish 将结果交给提交方，记录指令历史并发布 EventCommand；被取代的动作对提交方视为成功
+func (q *CommandQueue) finish(job *queuedCommand, result string, err error) {
+	entry := job.entry
+	entry.Result = result
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.LatencyMs = float64(time.Since(job.enqueued).Microseconds()) / 1000
+	if q.history != nil {
+		entry.Seq = q.history.Add(entry)
+	}
+	PublishEvent(Event{Type: EventCommand, DeviceID: q.name, Command: &entry})
+	job.done <- err
+}
+
+// depthLocked 返回所有通道中等待的动作数，调用方持有 q.mutex
+func (q *CommandQueue) depthLocked() int {
+	depth := 0
+	for _, lane := range q.lanes {
+		depth += len(lane)
+	}
+	return depth
+}
+
+// next 从优先级最高的非空通道取出一个动作，队列为空时返回 nil
+func (q *CommandQueue) next() *queuedCommand {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for p := priorityCount - 1; p >= 0; p-- {
+		if lane := q.lanes[p]; len(lane) > 0 {
+			q.lanes[p] = lane[1:]
+			return lane[0]
+		}
+	}
+	return nil
+}
+
+// run 发送 goroutine：被唤醒后依次发送直到队列为空
+func (q *CommandQueue) run() {
+	for range q.wake {
+		for job := q.next(); job != nil; job = q.next() {
+			err := q.send(job.req, &job.entry)
+			q.record(time.Since(job.enqueued), err)
+			result := ResultOK
+			if err != nil {
+				result = ResultFailed
+			}
+			q.finish(job, result, err)
+		}
+	}
+}
+
+// record 更新发送计数和延迟
+func (q *CommandQueue) record(latency time.Duration, err error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	ms := float64(latency.Microseconds()) / 1000
+	if q.stats.Sent == 0 {
+		q.stats.AvgLatencyMs = ms
+	} else {
+		q.stats.AvgLatencyMs += latencyAlpha * (ms - q.stats.AvgLatencyMs)
+	}
+	q.stats.Sent++
+	if err != nil {
+		q.stats.Failed++
+	}
+	q.stats.LastLatencyMs = ms
+	q.stats.MaxLatencyMs = max(q.stats.MaxLatencyMs, ms)
+}
+
+// Stats 返回队列状态的快照
+func (q *CommandQueue) Stats() QueueStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	stats := q.stats
+	stats.AvgLatencyMs = math.Round(stats.AvgLatencyMs*1000) / 1000
+	stats.Depth = q.depthLocked()
+	stats.Lanes = make(map[string]int, priorityCount)
+	for p, lane := range q.lanes {
+		stats.Lanes[CommandPriority(p).String()] = len(lane)
+	}
+	return stats
+}
@@ -0,0 +1,252 @@
+package device
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingSender 按顺序记录发送的动作；gate 未关闭前第一个动作阻塞在发送中，后续动作留在队列里
+type blockingSender struct {
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+	sent    []string
+	mutex   sync.Mutex
+}
+
+func newBlockingSender() *blockingSender {
+	return &blockingSender{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (s *blockingSender) send(req CommandRequest, _ *HistoryEntry) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.gate
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sent = append(s.sent, req.Action)
+	return nil
+}
+
+func (s *blockingSender) sentActions() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.sent)
+}
+
+// newBlockedQueue 创建队列并提交一个阻塞在发送中的动作 "first"
+func newBlockedQueue(t *testing.T) (*CommandQueue, *blockingSender, <-chan error) {
+	t.Helper()
+	sender := newBlockingSender()
+	q := NewCommandQueue("test", NewCommandHistory(0), sender.send)
+	t.Cleanup(q.Close)
+
+	first := q.Submit(queueRequest("first", PriorityManual, NewGenericCommand("Query", nil, "")))
+	select {
+	case <-sender.started:
+	case <-time.After(time.Second):
+		t.Fatal("第一个动作没有开始发送")
+	}
+	return q, sender, first
+}
+
+// queueRequest 以 device 来源创建动作
+func queueRequest(action string, priority CommandPriority, cmds ...Command) CommandRequest {
+	return CommandRequest{Origin: CommandOrigin{Source: SourceDevice, Priority: priority}, Action: action, Commands: cmds}
+}
+
+// wait 等待动作的结果
+func wait(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("动作没有结果")
+		return nil
+	}
+}
+
+// historyResults 返回各动作在指令历史中的结果，key 为动作名称
+func historyResults(q *CommandQueue) map[string]string {
+	results := make(map[string]string)
+	for _, entry := range q.history.Query(HistoryFilter{}) {
+		results[entry.Action] = entry.Result
+	}
+	return results
+}
+
+func TestCommandQueueCoalescesPendingPoses(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	older := q.Submit(queueRequest("finger-1", PriorityManual, NewFingerPoseCommand([]byte{1})))
+	palm := q.Submit(queueRequest("palm", PriorityManual, NewPalmPoseCommand([]byte{2})))
+	newer := q.Submit(queueRequest("finger-2", PriorityManual, NewFingerPoseCommand([]byte{3})))
+	// 其它通道中的同类姿态不合并
+	animation := q.Submit(queueRequest("finger-animation", PriorityAnimation, NewFingerPoseCommand([]byte{4})))
+
+	// 被取代的动作对提交方视为成功
+	if err := wait(t, older); err != nil {
+		t.Fatalf("被取代的动作应返回 nil，得到 %v", err)
+	}
+
+	close(sender.gate)
+	for _, done := range []<-chan error{first, palm, newer, animation} {
+		if err := wait(t, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 最新的手指姿态沿用被取代动作的位置，排在手掌姿态之前
+	want := []string{"first", "finger-2", "palm", "finger-animation"}
+	if got := sender.sentActions(); !slices.Equal(got, want) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+	if stats := q.Stats(); stats.Coalesced != 1 || stats.Sent != 4 {
+		t.Fatalf("coalesced=%d sent=%d，期望 1 和 4", stats.Coalesced, stats.Sent)
+	}
+	if result := historyResults(q)["finger-1"]; result != ResultCoalesced {
+		t.Fatalf("被取代动作的历史结果为 %q", result)
+	}
+}
+
+func TestCommandQueueEmergencyPreemptsLowerLanes(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	animation := q.Submit(queueRequest("animation", PriorityAnimation, NewFingerPoseCommand([]byte{1})))
+	manual := q.Submit(queueRequest("manual", PriorityManual, NewPalmPoseCommand([]byte{2})))
+	emergency := q.Submit(queueRequest("estop", PriorityEmergency, NewFingerPoseCommand([]byte{3})))
+
+	for _, done := range []<-chan error{animation, manual} {
+		if err := wait(t, done); !errors.Is(err, ErrCommandPreempted) {
+			t.Fatalf("等待中的低优先级动作应被取消，得到 %v", err)
+		}
+	}
+
+	// 紧急指令之后提交的动作照常排队
+	later := q.Submit(queueRequest("later", PriorityManual, NewPalmPoseCommand([]byte{4})))
+
+	close(sender.gate)
+	for _, done := range []<-chan error{first, emergency, later} {
+		if err := wait(t, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"first", "estop", "later"}
+	if got := sender.sentActions(); !slices.Equal(got, want) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+	if stats := q.Stats(); stats.Preempted != 2 {
+		t.Fatalf("preempted=%d，期望 2", stats.Preempted)
+	}
+	results := historyResults(q)
+	if results["animation"] != ResultPreempted || results["manual"] != ResultPreempted {
+		t.Fatalf("被取消动作的历史结果为 %v", results)
+	}
+}
+
+func TestCommandQueueSendsHigherLaneFirst(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	animation := q.Submit(queueRequest("animation", PriorityAnimation, NewFingerPoseCommand([]byte{1})))
+	manual := q.Submit(queueRequest("manual", PriorityManual, NewFingerPoseCommand([]byte{2})))
+
+	close(sender.gate)
+	for _, done := range []<-chan error{first, animation, manual} {
+		if err := wait(t, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"first", "manual", "animation"}
+	if got := sender.sentActions(); !slices.Equal(got, want) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+}
+
+func TestCommandQueueLatch(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	pending := q.Submit(queueRequest("pending", PriorityManual, NewFingerPoseCommand([]byte{1})))
+	q.Latch()
+	if err := wait(t, pending); !errors.Is(err, ErrCommandPreempted) {
+		t.Fatalf("锁定时等待中的动作应被取消，得到 %v", err)
+	}
+
+	for _, priority := range []CommandPriority{PriorityAnimation, PriorityManual} {
+		if err := wait(t, q.Submit(queueRequest("rejected", priority, NewFingerPoseCommand([]byte{2})))); !errors.Is(err, ErrEmergencyStop) {
+			t.Fatalf("锁定期间 %s 通道的动作应被拒绝，得到 %v", priority, err)
+		}
+	}
+	emergency := q.Submit(queueRequest("estop", PriorityEmergency, NewFingerPoseCommand([]byte{3})))
+
+	close(sender.gate)
+	for _, done := range []<-chan error{first, emergency} {
+		if err := wait(t, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q.Unlatch()
+	if err := wait(t, q.Submit(queueRequest("released", PriorityManual, NewFingerPoseCommand([]byte{4})))); err != nil {
+		t.Fatalf("解除锁定后应接受动作，得到 %v", err)
+	}
+
+	want := []string{"first", "estop", "released"}
+	if got := sender.sentActions(); !slices.Equal(got, want) {
+		t.Fatalf("发送顺序为 %v，期望 %v", got, want)
+	}
+	if stats := q.Stats(); stats.Rejected != 2 || stats.Preempted != 1 {
+		t.Fatalf("rejected=%d preempted=%d，期望 2 和 1", stats.Rejected, stats.Preempted)
+	}
+}
+
+func TestCommandQueueClose(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	pending := q.Submit(queueRequest("pending", PriorityManual, NewFingerPoseCommand([]byte{1})))
+	q.Close()
+	if err := wait(t, pending); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("关闭时等待中的动作应返回 ErrQueueClosed，得到 %v", err)
+	}
+	if err := wait(t, q.Submit(queueRequest("after", PriorityEmergency, NewFingerPoseCommand([]byte{2})))); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("关闭后提交的动作应返回 ErrQueueClosed，得到 %v", err)
+	}
+
+	// 正在发送的动作完成后发送 goroutine 退出
+	close(sender.gate)
+	if err := wait(t, first); err != nil {
+		t.Fatal(err)
+	}
+	if got := sender.sentActions(); !slices.Equal(got, []string{"first"}) {
+		t.Fatalf("关闭后仍发送了 %v", got)
+	}
+	results := historyResults(q)
+	if results["pending"] != ResultClosed || results["after"] != ResultClosed {
+		t.Fatalf("历史结果为 %v", results)
+	}
+}
+
+func TestCommandQueueFull(t *testing.T) {
+	q, sender, first := newBlockedQueue(t)
+
+	// 不合并的动作占满队列
+	var pending []<-chan error
+	for range maxQueueDepth {
+		pending = append(pending, q.Submit(queueRequest("query", PriorityManual, NewGenericCommand("Query", nil, ""))))
+	}
+	if err := wait(t, q.Submit(queueRequest("overflow", PriorityManual, NewGenericCommand("Query", nil, "")))); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("队列已满时应返回 ErrQueueFull，得到 %v", err)
+	}
+
+	close(sender.gate)
+	for _, done := range append(pending, first) {
+		if err := wait(t, done); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrRegistry 设备注册表写入失败，对应的修改已经回滚
+var ErrRegistry = errors.New("保存设备注册表失败")
+
+// DeviceRecord 设备注册表中的一条记录，足以在重启后重新创建设备
+type DeviceRecord struct {
+	ID       string         `json:"id"`
+	Model    string         `json:"model"`
+	HandType string         `json:"handType"` // "left" 或 "right"
+	Config   map[string]any `json:"config"`
+}
+
+// registryFile 注册表文件的内容
+type registryFile struct {
+	Devices []DeviceRecord `json:"devices"`
+	Groups  []DeviceGroup  `json:"groups,omitempty"`
+}
+
+// FileRegistry 以 JSON 文件保存设备注册表，每次保存先写临时文件再重命名，保证文件始终完整
+type FileRegistry struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileRegistry 创建文件注册表，文件在第一次保存时创建
+func NewFileRegistry(path string) *FileRegistry {
+	return &FileRegistry{path: path}
+}
+
+// Path 返回注册表文件路径
+func (r *FileRegistry) Path() string { return r.path }
+
+// Load 读取所有设备记录和设备组，文件不存在时返回空列表
+func (r *FileRegistry) Load() ([]DeviceRecord, []DeviceGroup, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取设备注册表失败：%w", err)
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("解析设备注册表 %s 失败：%w", r.path, err)
+	}
+	return file.Devices, file.Groups, nil
+}
+
+// Save 原子地写入所有设备记录和设备组
+func (r *FileRegistry) Save(records []DeviceRecord, groups []DeviceGroup) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data, err := json.MarshalIndent(registryFile{Devices: records, Groups: groups}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化设备注册表失败：%w", err)
+	}
+
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建设备注册表目录失败：%w", err)
+	}
+
+	// 临时文件与目标文件在同一目录，重命名才是原子的
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败：%w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入设备注册表失败：%w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入设备注册表失败：%w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入设备注册表失败：%w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("替换设备注册表失败：%w", err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir 将目录项落盘，保证断电后重命名不会丢失
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("打开设备注册表目录失败：%w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("同步设备注册表目录失败：%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package device
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// PoseUnit 姿态值的单位
+type PoseUnit string
+
+const (
+	UnitRaw     PoseUnit = "raw"     // 协议原始值 0-255
+	UnitPercent PoseUnit = "percent" // 闭合百分比，0 为完全张开，100 为完全闭合
+	UnitDegrees PoseUnit = "degrees" // 关节角度
+)
+
+// ParsePoseUnit 解析单位，空字符串为 raw
+func ParsePoseUnit(s string) (PoseUnit, error) {
+	switch unit := PoseUnit(s); unit {
+	case "":
+		return UnitRaw, nil
+	case UnitRaw, UnitPercent, UnitDegrees:
+		return unit, nil
+	}
+	return "", fmt.Errorf("无效的单位：%s，可选值为 raw、percent 或 degrees", s)
+}
+
+// JointCalibration 单个关节原始值与物理量的线性对应关系，由张开和闭合两个端点确定
+type JointCalibration struct {
+	Name          string  `json:"name"`
+	OpenRaw       byte    `json:"openRaw"`       // 完全张开 (0%) 时的原始值
+	ClosedRaw     byte    `json:"closedRaw"`     // 完全闭合 (100%) 时的原始值
+	OpenDegrees   float64 `json:"openDegrees"`   // 完全张开时的角度
+	ClosedDegrees float64 `json:"closedDegrees"` // 完全闭合时的角度
+}
+
+// JointCalibrationTable 一个型号所有关节的标定表
+type JointCalibrationTable struct {
+	Finger []JointCalibration `json:"finger"`
+	Palm   []JointCalibration `json:"palm"`
+}
+
+// PoseValues 以指定单位表示的姿态
+type PoseValues struct {
+	Unit   PoseUnit  `json:"unit"`
+	Finger []float64 `json:"finger,omitempty"`
+	Palm   []float64 `json:"palm,omitempty"`
+}
+
+var (
+	calibrations      = make(map[string]JointCalibrationTable)
+	calibrationsMutex sync.RWMutex
+)
+
+// RegisterJointCalibration 注册型号的关节标定表
+func RegisterJointCalibration(model string, table JointCalibrationTable) {
+	calibrationsMutex.Lock()
+	defer calibrationsMutex.Unlock()
+	calibrations[model] = table
+}
+
+// GetJointCalibration 获取型号的关节标定表
+func GetJointCalibration(model string) (JointCalibrationTable, bool) {
+	calibrationsMutex.RLock()
+	defer calibrationsMutex.RUnlock()
+	table, ok := calibrations[model]
+	return table, ok
+}
+
+// Joints 返回关节组的标定
+func (t JointCalibrationTable) Joints(group JointGroup) []JointCalibration {
+	switch group {
+	case JointGroupFinger:
+		return t.Finger
+	case JointGroupPalm:
+		return t.Palm
+	}
+	return nil
+}
+
+// toUnit 将原始值换算为指定单位，保留一位小数
+func (j JointCalibration) toUnit(unit PoseUnit, raw byte) float64 {
+	fraction := (float64(raw) - float64(j.OpenRaw)) / (float64(j.ClosedRaw) - float64(j.OpenRaw))
+	var v float64
+	switch unit {
+	case UnitPercent:
+		v = fraction * 100
+	case UnitDegrees:
+		v = j.OpenDegrees + fraction*(j.ClosedDegrees-j.OpenDegrees)
+	default:
+		return float64(raw)
+	}
+	return math.Round(v*10)/10 + 0 // + 0 避免输出 -0
+}
+
+// fromUnit 将指定单位的值换算为原始值，超出 0-255 时返回错误
+func (j JointCalibration) fromUnit(unit PoseUnit, v float64) (byte, error) {
+	raw := v
+	switch unit {
+	case UnitPercent:
+		raw = float64(j.OpenRaw) + v/100*(float64(j.ClosedRaw)-float64(j.OpenRaw))
+	case UnitDegrees:
+		fraction := (v - j.OpenDegrees) / (j.ClosedDegrees - j.OpenDegrees)
+		raw = float64(j.OpenRaw) + fraction*(float64(j.ClosedRaw)-float64(j.OpenRaw))
+	}
+
+	raw = math.Round(raw)
+	if math.IsNaN(raw) || raw < 0 || raw > 255 {
+		return 0, fmt.Errorf("关节 %s 的值 %v %s 超出范围", j.Name, v, unit)
+	}
+	return byte(raw), nil
+}
+
+// ToRaw 将一个关节组的值从指定单位换算为原始值
+// 标定表为空时只能使用 raw 单位，此时不检查关节数，由设备校验
+func (t JointCalibrationTable) ToRaw(group JointGroup, unit PoseUnit, values []float64) ([]byte, error) {
+	joints := t.Joints(group)
+	if len(joints) == 0 && unit != UnitRaw {
+		return nil, fmt.Errorf("没有 %s 关节标定，只支持 raw 单位", group)
+	}
+	if len(joints) > 0 && len(values) != len(joints) {
+		return nil, fmt.Errorf("无效的 %s 姿态数据长度 %d，需要 %d 个值", group, len(values), len(joints))
+	}
+
+	raw := make([]byte, len(values))
+	for i, v := range values {
+		joint := JointCalibration{Name: fmt.Sprintf("%s[%d]", group, i)}
+		if i < len(joints) {
+			joint = joints[i]
+		}
+		b, err := joint.fromUnit(unit, v)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = b
+	}
+	return raw, nil
+}
+
+// FromRaw 将一个关节组的原始值换算为指定单位，长度与标定表不一致时按原始值返回
+func (t JointCalibrationTable) FromRaw(group JointGroup, unit PoseUnit, raw []byte) []float64 {
+	if raw == nil {
+		return nil
+	}
+	joints := t.Joints(group)
+	values := make([]float64, len(raw))
+	for i, b := range raw {
+		if unit == UnitRaw || i >= len(joints) {
+			values[i] = float64(b)
+			continue
+		}
+		values[i] = joints[i].toUnit(unit, b)
+	}
+	return values
+}
+
+// Pose 将原始的手指和手掌姿态换算为指定单位
+func (t JointCalibrationTable) Pose(unit PoseUnit, finger, palm []byte) PoseValues {
+	return PoseValues{
+		Unit:   unit,
+		Finger: t.FromRaw(JointGroupFinger, unit, finger),
+		Palm:   t.FromRaw(JointGroupPalm, unit, palm),
+	}
+}
+
+// RawPose 将原始姿态表示为 raw 单位的 PoseValues
+func RawPose(finger, palm []byte) PoseValues {
+	return JointCalibrationTable{}.Pose(UnitRaw, finger, palm)
+}
+
+// Convert 将 raw 单位的姿态换算为指定单位，pose 不是 raw 单位时原样返回
+func (t JointCalibrationTable) Convert(pose PoseValues, unit PoseUnit) PoseValues {
+	if pose.Unit != UnitRaw || unit == UnitRaw {
+		return pose
+	}
+	toBytes := func(values []float64) []byte {
+		if values == nil {
+			return nil
+		}
+		raw := make([]byte, len(values))
+		for i, v := range values {
+			raw[i] = byte(v)
+		}
+		return raw
+	}
+	return t.Pose(unit, toBytes(pose.Finger), toBytes(pose.Palm))
+}
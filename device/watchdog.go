@@ -0,0 +1,250 @@
+package device
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// minWatchdogTimeoutMs 心跳超时的最小值，避免网络抖动误触发
+const minWatchdogTimeoutMs = 100
+
+var (
+	// ErrWatchdogDisabled 设备没有配置 watchdog
+	ErrWatchdogDisabled = errors.New("设备没有配置 watchdog")
+	// ErrSessionActive 设备已有其它客户端的控制会话
+	ErrSessionActive = errors.New("设备已有控制会话")
+	// ErrSessionNotFound 控制会话不存在、已关闭或已超时
+	ErrSessionNotFound = errors.New("控制会话不存在或已超时")
+)
+
+// WatchdogConfig 设备配置中的 watchdog，timeout_ms 为 0 时不启用：
+//
+//	"watchdog": {"timeout_ms": 1500}
+type WatchdogConfig struct {
+	TimeoutMs int `json:"timeout_ms"` // 控制会话两次心跳之间允许的最长间隔
+}
+
+// WatchdogConfigFromConfig 解析设备配置中的 watchdog，没有配置时返回零值
+func WatchdogConfigFromConfig(config map[string]any) (WatchdogConfig, error) {
+	var watchdogConfig WatchdogConfig
+	raw, ok := config["watchdog"]
+	if !ok || raw == nil {
+		return watchdogConfig, nil
+	}
+
+	// 配置可能来自 JSON 请求或注册表，统一经过一次 JSON 编解码
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return watchdogConfig, fmt.Errorf("无效的 watchdog 配置：%w", err)
+	}
+	if err := json.Unmarshal(data, &watchdogConfig); err != nil {
+		return watchdogConfig, fmt.Errorf("无效的 watchdog 配置：%w", err)
+	}
+	if watchdogConfig.TimeoutMs != 0 && watchdogConfig.TimeoutMs < minWatchdogTimeoutMs {
+		return watchdogConfig, fmt.Errorf("无效的 watchdog 配置：timeout_ms 为 %d，至少为 %d", watchdogConfig.TimeoutMs, minWatchdogTimeoutMs)
+	}
+	return watchdogConfig, nil
+}
+
+// WatchdogSession 控制会话，客户端需要在 ExpiresAt 之前发送心跳
+type WatchdogSession struct {
+	SessionID string    `json:"sessionId"`
+	TimeoutMs int       `json:"timeoutMs"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// WatchdogStatus watchdog 的配置、当前会话和触发记录
+type WatchdogStatus struct {
+	Enabled       bool      `json:"enabled"`
+	TimeoutMs     int       `json:"timeoutMs"`
+	SessionActive bool      `json:"sessionActive"`           // 是否有控制会话
+	Client        string    `json:"client,omitempty"`        // 当前会话的客户端地址
+	OpenedAt      time.Time `json:"openedAt,omitzero"`       // 当前会话的开始时间
+	LastHeartbeat time.Time `json:"lastHeartbeat,omitzero"`  // 当前会话最近一次心跳
+	Trips         int       `json:"trips"`                   // 心跳超时的累计次数
+	LastTripAt    time.Time `json:"lastTripAt,omitzero"`     // 最近一次心跳超时的时间
+	LastTripError string    `json:"lastTripError,omitempty"` // 最近一次超时后驱动到安全姿态失败的原因
+}
+
+// WatchdogDevice 由支持控制会话 watchdog 的设备实现
+type WatchdogDevice interface {
+	OpenSession(client string) (WatchdogSession, error)
+	Heartbeat(sessionID string) (WatchdogSession, error)
+	CloseSession(sessionID string) error
+}
+
+// Watchdog 设备的 deadman watchdog：同一时间只有一个控制会话，心跳中断超过超时时间时
+// 结束会话并调用 trip（通常停止动画并驱动到安全姿态）。没有会话时不监视
+type Watchdog struct {
+	name     string // 设备 ID，用于日志
+	timeout  time.Duration
+	trip     func() error
+	status   WatchdogStatus
+	session  string      // 当前会话 ID，为空表示没有会话
+	deadline time.Time   // 当前会话的超时时间
+	timer    *time.Timer // 当前会话的超时定时器
+	mutex    sync.Mutex
+}
+
+// NewWatchdog 创建 watchdog，config 没有启用时返回 nil
+func NewWatchdog(name string, config WatchdogConfig, trip func() error) *Watchdog {
+	if config.TimeoutMs == 0 {
+		return nil
+	}
+	return &Watchdog{
+		name:    name,
+		timeout: time.Duration(config.TimeoutMs) * time.Millisecond,
+		trip:    trip,
+		status:  WatchdogStatus{Enabled: true, TimeoutMs: config.TimeoutMs},
+	}
+}
+
+// Open 开始一个控制会话，已有会话时返回 ErrSessionActive
+func (w *Watchdog) Open(client string) (WatchdogSession, error) {
+	if w == nil {
+		return WatchdogSession{}, ErrWatchdogDisabled
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.session != "" {
+		return WatchdogSession{}, fmt.Errorf("%w：客户端 %s 的会话开始于 %s", ErrSessionActive, w.status.Client, w.status.OpenedAt.Format(time.RFC3339))
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return WatchdogSession{}, fmt.Errorf("生成会话 ID 失败：%w", err)
+	}
+	session := hex.EncodeToString(id)
+
+	now := time.Now()
+	w.session = session
+	w.deadline = now.Add(w.timeout)
+	w.status.SessionActive = true
+	w.status.Client = client
+	w.status.OpenedAt = now
+	w.status.LastHeartbeat = now
+	w.timer = time.AfterFunc(w.timeout, func() { w.expire(session) })
+
+	log.Printf("🎮 设备 %s 的控制会话已开始 (客户端: %s, 超时: %s)", w.name, client, w.timeout)
+	return w.sessionLocked(), nil
+}
+
+// Heartbeat 刷新控制会话的超时时间，会话不存在或已超时时返回 ErrSessionNotFound
+func (w *Watchdog) Heartbeat(sessionID string) (WatchdogSession, error) {
+	if w == nil {
+		return WatchdogSession{}, ErrWatchdogDisabled
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if sessionID == "" || sessionID != w.session {
+		return WatchdogSession{}, ErrSessionNotFound
+	}
+	now := time.Now()
+	w.deadline = now.Add(w.timeout)
+	w.status.LastHeartbeat = now
+	w.timer.Reset(w.timeout)
+	return w.sessionLocked(), nil
+}
+
+// Close 正常结束控制会话，不驱动到安全姿态
+func (w *Watchdog) Close(sessionID string) error {
+	if w == nil {
+		return ErrWatchdogDisabled
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if sessionID == "" || sessionID != w.session {
+		return ErrSessionNotFound
+	}
+	w.timer.Stop()
+	w.endSessionLocked()
+	log.Printf("🎮 设备 %s 的控制会话已结束", w.name)
+	return nil
+}
+
+// Stop 结束当前会话并停止定时器，不驱动到安全姿态，设备删除时调用
+func (w *Watchdog) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.session == "" {
+		return
+	}
+	w.timer.Stop()
+	w.endSessionLocked()
+}
+
+// Status 返回 watchdog 状态的快照，未启用时返回零值
+func (w *Watchdog) Status() WatchdogStatus {
+	if w == nil {
+		return WatchdogStatus{}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.status
+}
+
+// expire 由会话的超时定时器调用；定时器与心跳竞争时以 deadline 为准
+func (w *Watchdog) expire(sessionID string) {
+	w.mutex.Lock()
+	if sessionID != w.session {
+		w.mutex.Unlock()
+		return
+	}
+	if remaining := time.Until(w.deadline); remaining > 0 {
+		w.timer.Reset(remaining)
+		w.mutex.Unlock()
+		return
+	}
+
+	client := w.status.Client
+	w.endSessionLocked()
+	w.status.Trips++
+	w.status.LastTripAt = time.Now()
+	w.status.LastTripError = ""
+	w.mutex.Unlock()
+
+	log.Printf("⏰ 设备 %s 的控制会话 (客户端: %s) 超过 %s 没有心跳，正在驱动到安全姿态", w.name, client, w.timeout)
+	if err := w.trip(); err != nil {
+		log.Printf("❌ 设备 %s 心跳超时后驱动到安全姿态失败: %v", w.name, err)
+		w.mutex.Lock()
+		w.status.LastTripError = err.Error()
+		w.mutex.Unlock()
+	}
+}
+
+// endSessionLocked 清除当前会话，调用方持有 w.mutex
+func (w *Watchdog) endSessionLocked() {
+	w.session = ""
+	w.timer = nil
+	w.status.SessionActive = false
+	w.status.Client = ""
+	w.status.OpenedAt = time.Time{}
+	w.status.LastHeartbeat = time.Time{}
+}
+
+// sessionLocked 返回当前会话，调用方持有 w.mutex
+func (w *Watchdog) sessionLocked() WatchdogSession {
+	return WatchdogSession{
+		SessionID: w.session,
+		TimeoutMs: w.status.TimeoutMs,
+		ExpiresAt: w.deadline,
+	}
+}
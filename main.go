@@ -19,10 +19,21 @@ import (
 // 初始化服务
 func initService() {
 	log.Printf("🔧 服务配置：")
+	log.Printf("   - 传输方式: %s", config.Config.Transport)
 	log.Printf("   - CAN 服务 URL: %s", config.Config.CanServiceURL)
 	log.Printf("   - Web 端口: %s", config.Config.WebPort)
 	log.Printf("   - 可用接口: %v", config.Config.AvailableInterfaces)
 	log.Printf("   - 默认接口: %s", config.Config.DefaultInterface)
+	log.Printf("   - 发送重试: %d 次 (基础间隔 %v)", config.Config.SendRetries, config.Config.RetryBaseDelay)
+	log.Printf("   - 熔断阈值: %d 次 (恢复间隔 %v)", config.Config.BreakerThreshold, config.Config.BreakerOpenTimeout)
+	log.Printf("   - 健康检查间隔: %v", config.Config.HealthInterval)
+	log.Printf("   - 发送限速: %v 帧/秒 (突发 %d 帧，波特率 %d/%d)", config.Config.RateLimit, config.Config.RateBurst, config.Config.Bitrate, config.Config.DataBitrate)
+	if config.Config.DeviceStore != "" {
+		log.Printf("   - 设备注册表: %s", config.Config.DeviceStore)
+	} else {
+		log.Printf("   - 设备注册表: 不持久化")
+	}
+	log.Printf("   - 型号描述目录: %s", config.Config.ModelDir)
 
 	log.Println("✅ 控制服务初始化完成")
 }
@@ -30,16 +41,42 @@ func initService() {
 func printUsage() {
 	fmt.Println("CAN Control Service with Hand Type Support")
 	fmt.Println("Usage:")
-	fmt.Println("  -can-url string         CAN 服务的 URL (default: http://127.0.0.1:5260)")
+	fmt.Println("  -can-url string         CAN 服务的 URL，sim:// 表示使用进程内模拟总线，多个 URL 用逗号分隔时按顺序故障转移 (default: http://127.0.0.1:5260)")
+	fmt.Println("  -transport string       默认设备的传输方式: can-bridge、socketcan 或 sim (default: can-bridge)")
 	fmt.Println("  -port string            Web 服务的端口 (default: 9099)")
 	fmt.Println("  -interface string       默认 CAN 接口")
 	fmt.Println("  -can-interfaces string  支持的 CAN 接口列表，用逗号分隔")
+	fmt.Println("  -can-retries int        姿态帧发送失败后的最大重试次数 (default: 3)")
+	fmt.Println("  -can-retry-delay dur    重试退避的基础间隔 (default: 50ms)")
+	fmt.Println("  -can-breaker-threshold  连续失败多少次后熔断，0 表示不熔断 (default: 5)")
+	fmt.Println("  -can-breaker-timeout    熔断后多久尝试恢复 (default: 5s)")
+	fmt.Println("  -record-dir string      CAN 流量录制文件的存放目录 (default: recordings)")
+	fmt.Println("  -device-store string    设备注册表文件路径，为空时不持久化设备 (default: devices.json)")
+	fmt.Println("  -model-dir string       型号描述文件 (*.json) 所在目录 (default: models)")
+	fmt.Println("  -health-interval dur    通信服务和接口健康检查的轮询间隔 (default: 2s)")
+	fmt.Println("  -can-rate-limit float   每个 CAN 接口每秒最多发送的帧数，0 表示不限速 (default: 1000)")
+	fmt.Println("  -can-rate-burst int     发送限速允许的突发帧数 (default: 100)")
+	fmt.Println("  -can-bitrate int        CAN 总线波特率，用于估算总线负载 (default: 1000000)")
+	fmt.Println("  -can-data-bitrate int   CAN FD 数据段波特率 (default: 5000000)")
 	fmt.Println("")
 	fmt.Println("Environment Variables:")
 	fmt.Println("  CAN_SERVICE_URL        CAN 服务的 URL")
+	fmt.Println("  CAN_TRANSPORT         默认设备的传输方式")
 	fmt.Println("  WEB_PORT              Web 服务的端口")
 	fmt.Println("  DEFAULT_INTERFACE     默认 CAN 接口")
 	fmt.Println("  CAN_INTERFACES        支持的 CAN 接口列表，用逗号分隔")
+	fmt.Println("  CAN_RETRIES           姿态帧发送失败后的最大重试次数")
+	fmt.Println("  CAN_RETRY_DELAY       重试退避的基础间隔")
+	fmt.Println("  CAN_BREAKER_THRESHOLD 连续失败多少次后熔断")
+	fmt.Println("  CAN_BREAKER_TIMEOUT   熔断后多久尝试恢复")
+	fmt.Println("  CAN_RECORD_DIR        CAN 流量录制文件的存放目录")
+	fmt.Println("  DEVICE_STORE          设备注册表文件路径，设为空字符串时不持久化设备")
+	fmt.Println("  MODEL_DIR             型号描述文件 (*.json) 所在目录")
+	fmt.Println("  CAN_HEALTH_INTERVAL   通信服务和接口健康检查的轮询间隔")
+	fmt.Println("  CAN_RATE_LIMIT        每个 CAN 接口每秒最多发送的帧数")
+	fmt.Println("  CAN_RATE_BURST        发送限速允许的突发帧数")
+	fmt.Println("  CAN_BITRATE           CAN 总线波特率")
+	fmt.Println("  CAN_DATA_BITRATE      CAN FD 数据段波特率")
 	fmt.Println("")
 	fmt.Println("New Features:")
 	fmt.Println("  - Support for left/right hand configuration")
@@ -52,6 +89,9 @@ func printUsage() {
 	fmt.Println("  ./control-service -interface can1 -can-interfaces can0,can1")
 	fmt.Println("  CAN_INTERFACES=can0,can1,vcan0 ./control-service")
 	fmt.Println("  CAN_SERVICE_URL=http://localhost:5260 ./control-service")
+	fmt.Println("  ./control-service -transport socketcan -can-interfaces vcan0")
+	fmt.Println("  ./control-service -can-url sim://")
+	fmt.Println("  ./control-service -can-url http://10.0.0.1:5260,http://10.0.0.2:5260")
 }
 
 func main() {
@@ -95,8 +135,27 @@ func main() {
 
 	models.RegisterDeviceTypes()
 
+	// 描述文件定义的型号需要在恢复设备之前注册
+	loadedModels, err := models.LoadModelDescriptors(config.Config.ModelDir)
+	if err != nil {
+		log.Fatalf("❌ 加载型号描述文件失败: %v", err)
+	}
+	if len(loadedModels) > 0 {
+		log.Printf("🧩 从 %s 加载了 %d 个型号: %v", config.Config.ModelDir, len(loadedModels), loadedModels)
+	}
+
 	deviceManager := device.NewDeviceManager()
 
+	// 在提供 API 服务之前从注册表恢复设备
+	if config.Config.DeviceStore != "" {
+		deviceManager.SetRegistry(device.NewFileRegistry(config.Config.DeviceStore))
+		restored, err := deviceManager.RestoreDevices()
+		if err != nil {
+			log.Fatalf("❌ 加载设备注册表失败: %v", err)
+		}
+		log.Printf("📂 从 %s 恢复了 %d 个设备", config.Config.DeviceStore, restored)
+	}
+
 	// 设置 API 路由
 	api.NewServer(deviceManager).SetupRoutes(r)
 	legacyServer, err := legacy.NewLegacyServer(deviceManager)